@@ -0,0 +1,42 @@
+package age
+
+import (
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// BatchResult is the outcome of one entry of a batch verification call.
+type BatchResult struct {
+	Valid bool
+	Code  string // error code (e.g. E1003) when Err is set
+	Err   error
+}
+
+// VerifyAgeBatch verifies many age proofs against the configured policy and
+// returns one result per proof, in order; a bad proof only fails its own result.
+// Groth16 proofs share a single randomized pairing check; PLONK proofs are
+// verified in parallel.
+func (v *Verifier) VerifyAgeBatch(proofs [][]byte) []BatchResult {
+	results := make([]BatchResult, len(proofs))
+	assignment, err := v.ageAssignment()
+	if err != nil {
+		for i := range results {
+			results[i] = BatchResult{Code: sdkerrors.ErrInvalidConfig.Code, Err: err}
+		}
+		return results
+	}
+
+	items := make([]backend.BatchItem, len(proofs))
+	for i, proof := range proofs {
+		items[i] = backend.BatchItem{Proof: proof, Public: assignment}
+	}
+	for i, err := range backend.VerifyBatch(v.verifyingKey, items) {
+		if err != nil {
+			err = backend.VerifyError(err, "age")
+			results[i] = BatchResult{Code: sdkerrors.CodeOf(err), Err: err}
+			continue
+		}
+		results[i] = BatchResult{Valid: true}
+	}
+	return results
+}
@@ -17,7 +17,7 @@ func BenchmarkVerifyAge(b *testing.B) {
 		b.Fatalf("verifier init failed: %v", err)
 	}
 
-	proof, err := prover.GenerateAgeProof(2000, cfg.TargetYear, cfg.LimitAge)
+	proof, err := prover.GenerateAgeProof(20000101, cfg.CurrentDate(), cfg.LimitAge)
 	if err != nil {
 		b.Fatalf("proof generation failed: %v", err)
 	}
@@ -2,17 +2,19 @@ package age
 
 import "github.com/consensys/gnark/frontend"
 
-// AgeCircuit enforces CurrentYear - BirthYear >= LimitAge without revealing BirthYear.
+// AgeCircuit enforces age(BirthDate, CurrentDate) >= LimitAge without revealing BirthDate.
+// Dates are YYYYMMDD; Mode selects international full age (0) or Korean year-age (1).
 type AgeCircuit struct {
-	CurrentYear frontend.Variable `gnark:",public"`
+	CurrentDate frontend.Variable `gnark:",public"`
 	LimitAge    frontend.Variable `gnark:",public"`
+	Mode        frontend.Variable `gnark:",public"`
 
-	BirthYear frontend.Variable
+	BirthDate frontend.Variable
 }
 
 // Define implements the gnark circuit definition.
 func (c *AgeCircuit) Define(api frontend.API) error {
-	age := api.Sub(c.CurrentYear, c.BirthYear)
-	api.AssertIsLessOrEqual(c.LimitAge, age)
+	age := AgeAt(api, c.BirthDate, c.CurrentDate, c.Mode)
+	AssertAgeAtLeast(api, age, c.LimitAge)
 	return nil
 }
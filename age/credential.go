@@ -0,0 +1,137 @@
+package age
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// Credential is an issuer-signed birth date held by the user. The signature
+// covers the holder commitment too, so proving with the credential needs the
+// holder secret behind it.
+type Credential struct {
+	BirthDate int    `json:"birth_date"` // YYYYMMDD
+	Holder    string `json:"holder"`     // decimal holder commitment (commitment.HolderCommitment)
+	IssuerKey string `json:"issuer_key"` // hex, compressed BabyJubJub public key
+	Signature string `json:"signature"`  // hex, EdDSA (MiMC) signature over MiMC(BirthDate, Holder)
+}
+
+// Issuer signs birth-date credentials with an EdDSA key over BabyJubJub.
+type Issuer struct {
+	key *eddsa.PrivateKey
+}
+
+// GenerateIssuer creates an issuer with a fresh random key.
+func GenerateIssuer() (*Issuer, error) {
+	key, err := eddsa.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSetupFailed.Code, "issuer key generation failed", err)
+	}
+	return &Issuer{key: key}, nil
+}
+
+// NewIssuerFromBytes restores an issuer from a serialized private key (see Issuer.Bytes).
+func NewIssuerFromBytes(keyBytes []byte) (*Issuer, error) {
+	var key eddsa.PrivateKey
+	if _, err := key.SetBytes(keyBytes); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "issuer key parse failed", err)
+	}
+	return &Issuer{key: &key}, nil
+}
+
+// Bytes returns the serialized private key. Store it in a KMS; it is not a public value.
+func (i *Issuer) Bytes() []byte {
+	return i.key.Bytes()
+}
+
+// PublicKey returns the issuer public key as hex, as listed in VerifierConfig.TrustedIssuers.
+func (i *Issuer) PublicKey() string {
+	return hex.EncodeToString(i.key.PublicKey.Bytes())
+}
+
+// Issue signs birthDate (YYYYMMDD) for the holder whose holder commitment
+// (see commitment.HolderCommitment) is holder, and returns the credential.
+func (i *Issuer) Issue(birthDate int, holder string) (Credential, error) {
+	if err := common.ValidateDate(birthDate); err != nil {
+		return Credential{}, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "birth date invalid", err)
+	}
+	msg, err := credentialMessage(birthDate, holder)
+	if err != nil {
+		return Credential{}, err
+	}
+	sig, err := i.key.Sign(msg, mimc.NewMiMC())
+	if err != nil {
+		return Credential{}, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "credential signing failed", err)
+	}
+	return Credential{
+		BirthDate: birthDate,
+		Holder:    holder,
+		IssuerKey: i.PublicKey(),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// VerifyCredential checks the credential signature natively, outside the circuit.
+func VerifyCredential(cred Credential) error {
+	pub, sig, err := decodeCredential(cred)
+	if err != nil {
+		return err
+	}
+	msg, err := credentialMessage(cred.BirthDate, cred.Holder)
+	if err != nil {
+		return err
+	}
+	ok, err := pub.Verify(sig, msg, mimc.NewMiMC())
+	if err != nil || !ok {
+		return sdkerrors.ErrCredentialInvalid
+	}
+	return nil
+}
+
+func decodeCredential(cred Credential) (*eddsa.PublicKey, []byte, error) {
+	pub, err := parseIssuerKey(cred.IssuerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := hex.DecodeString(cred.Signature)
+	if err != nil {
+		return nil, nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "signature decode failed", err)
+	}
+	return pub, sig, nil
+}
+
+func parseIssuerKey(keyHex string) (*eddsa.PublicKey, error) {
+	raw, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "issuer key decode failed", err)
+	}
+	var pub eddsa.PublicKey
+	if _, err := pub.SetBytes(raw); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, fmt.Sprintf("issuer key parse failed: %s", keyHex), err)
+	}
+	return &pub, nil
+}
+
+// credentialMessage is the signed message MiMC(birthDate, holder), matching the circuit.
+func credentialMessage(birthDate int, holder string) ([]byte, error) {
+	holderElem, err := commitment.ParseHolderCommitment(holder)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "holder commitment invalid", err)
+	}
+	var birth fr.Element
+	birth.SetUint64(uint64(birthDate))
+	msg, err := hasher.Sum(hasher.MiMC, birth, holderElem)
+	if err != nil {
+		return nil, err
+	}
+	b := msg.Bytes()
+	return b[:], nil
+}
@@ -0,0 +1,60 @@
+package age
+
+import (
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// CredentialAgeCircuit enforces the AgeCircuit predicate over a birth date that
+// carries a valid issuer EdDSA (BabyJubJub) signature. The issuer public key is
+// public so the verifier can check it against its trusted issuers.
+//
+// The issuer signs MiMC(BirthDate, MiMC(HolderSecret)), so only the holder can
+// prove with a credential, and the public Challenge issued by the verifier
+// ties each proof to one presentation.
+type CredentialAgeCircuit struct {
+	CurrentDate frontend.Variable `gnark:",public"`
+	LimitAge    frontend.Variable `gnark:",public"`
+	Mode        frontend.Variable `gnark:",public"`
+	IssuerKey   eddsa.PublicKey   `gnark:",public"`
+	Challenge   frontend.Variable `gnark:",public"`
+
+	BirthDate    frontend.Variable
+	HolderSecret frontend.Variable
+	Signature    eddsa.Signature
+}
+
+// Define implements the gnark circuit definition.
+func (c *CredentialAgeCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	holderHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	holderHash.Write(c.HolderSecret)
+	msgHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	msgHash.Write(c.BirthDate, holderHash.Sum())
+	sigHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	if err := eddsa.Verify(curve, c.Signature, msgHash.Sum(), c.IssuerKey, &sigHash); err != nil {
+		return err
+	}
+	// The challenge must be non-zero; the check also constrains it, so a
+	// proof does not verify for any other challenge.
+	api.AssertIsDifferent(c.Challenge, 0)
+
+	age := AgeAt(api, c.BirthDate, c.CurrentDate, c.Mode)
+	AssertAgeAtLeast(api, age, c.LimitAge)
+	return nil
+}
@@ -0,0 +1,112 @@
+package age
+
+import (
+	"context"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+//go:embed age_credential.pk
+var credentialProvingKeyData []byte
+
+//go:embed age_credential.ccs
+var credentialCCSData []byte
+
+// EmbeddedCredentialProvingKeyID is the blake2b-256 fingerprint of the embedded credential age proving key.
+var EmbeddedCredentialProvingKeyID = backend.KeyID(credentialProvingKeyData)
+
+// GenerateCredentialAgeProof proves the age predicate over an issuer-signed birth date.
+// holderSecret must be the secret behind cred.Holder, and challenge is the
+// verifier-issued value the proof is bound to. The credential circuit is
+// compiled on first use.
+func (p *Prover) GenerateCredentialAgeProof(cred Credential, holderSecret string, challenge string, currentDate int, limitAge int) ([]byte, error) {
+	return p.GenerateCredentialAgeProofContext(context.Background(), cred, holderSecret, challenge, currentDate, limitAge)
+}
+
+// GenerateCredentialAgeProofContext is GenerateCredentialAgeProof with a
+// context (see GenerateAgeProofContext).
+func (p *Prover) GenerateCredentialAgeProofContext(ctx context.Context, cred Credential, holderSecret string, challenge string, currentDate int, limitAge int) ([]byte, error) {
+	if currentDate == 0 {
+		currentDate = p.config.CurrentDate()
+	}
+	if limitAge == 0 {
+		limitAge = p.config.LimitAge
+	}
+	if err := VerifyCredential(cred); err != nil {
+		return nil, err
+	}
+	secretInt, err := commitment.ParseChallenge(holderSecret)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "holder secret invalid", err)
+	}
+	if holder, err := commitment.HolderCommitment(secretInt.String()); err != nil || holder != cred.Holder {
+		return nil, sdkerrors.New(sdkerrors.ErrCredentialInvalid.Code, "holder secret does not match credential")
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	mode, err := common.AgeModeCode(p.config.AgeMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.loadCredentialCircuit(); err != nil {
+		return nil, err
+	}
+
+	pubBytes, _ := hex.DecodeString(cred.IssuerKey)
+	sigBytes, _ := hex.DecodeString(cred.Signature)
+
+	var publicCurr big.Int
+	publicCurr.SetInt64(int64(currentDate))
+	var publicLimit big.Int
+	publicLimit.SetInt64(int64(limitAge))
+
+	assignment := CredentialAgeCircuit{
+		CurrentDate:  publicCurr,
+		LimitAge:     publicLimit,
+		Mode:         mode,
+		Challenge:    challengeInt,
+		BirthDate:    cred.BirthDate,
+		HolderSecret: secretInt,
+	}
+	assignment.IssuerKey.Assign(tedwards.BN254, pubBytes)
+	assignment.Signature.Assign(tedwards.BN254, sigBytes)
+
+	proof, err := backend.ProveContext(ctx, p.credentialCCS, p.credentialProvingKey, &assignment)
+	if err != nil {
+		return nil, fmt.Errorf("credential age %w", err)
+	}
+	return proof, nil
+}
+
+func (p *Prover) loadCredentialCircuit() error {
+	p.credentialOnce.Do(func() {
+		ccs, err := backend.LoadConstraintSystem(backend.Groth16, credentialCCSData, &CredentialAgeCircuit{})
+		if err != nil {
+			p.credentialErr = fmt.Errorf("credential age circuit load failed: %w", err)
+			return
+		}
+		pk, err := backend.LoadProvingKey(backend.Groth16, p.credentialPKData)
+		if err != nil {
+			p.credentialErr = fmt.Errorf("credential age proving key parse failed: %w", err)
+			return
+		}
+		p.credentialCCS = ccs
+		p.credentialProvingKey = pk
+	})
+	return p.credentialErr
+}
+
+// CredentialProvingKeyID returns the fingerprint of the embedded credential age proving key.
+func CredentialProvingKeyID() string {
+	return EmbeddedCredentialProvingKeyID
+}
@@ -0,0 +1,81 @@
+package age
+
+import (
+	"context"
+	_ "embed"
+	"math/big"
+	"sort"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+//go:embed age_credential.vk
+var credentialVerifyingKeyData []byte
+
+// EmbeddedCredentialVerifyingKeyID is the blake2b-256 fingerprint of the embedded credential age verifying key.
+var EmbeddedCredentialVerifyingKeyID = backend.KeyID(credentialVerifyingKeyData)
+
+// VerifyCredentialAge validates a credential age proof made with the issuer key issuerKey (hex)
+// for challenge, which the verifier issued for this presentation and must not accept twice.
+// The issuer must be listed in VerifierConfig.TrustedIssuers.
+func (v *Verifier) VerifyCredentialAge(proofBytes []byte, issuerKey string, challenge string) (bool, error) {
+	return v.VerifyCredentialAgeContext(context.Background(), proofBytes, issuerKey, challenge)
+}
+
+// VerifyCredentialAgeContext is VerifyCredentialAge with a context (see
+// VerifyAgeContext).
+func (v *Verifier) VerifyCredentialAgeContext(ctx context.Context, proofBytes []byte, issuerKey string, challenge string) (bool, error) {
+	if !v.trustedIssuers[issuerKey] {
+		return false, sdkerrors.ErrIssuerUntrusted
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return false, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	pub, err := parseIssuerKey(issuerKey)
+	if err != nil {
+		return false, err
+	}
+
+	mode, err := common.AgeModeCode(v.config.AgeMode)
+	if err != nil {
+		return false, err
+	}
+
+	var publicCurr big.Int
+	publicCurr.SetInt64(int64(v.config.CurrentDate()))
+	var publicLimit big.Int
+	publicLimit.SetInt64(int64(v.config.LimitAge))
+
+	assignment := CredentialAgeCircuit{
+		CurrentDate: publicCurr,
+		LimitAge:    publicLimit,
+		Mode:        mode,
+		Challenge:   challengeInt,
+	}
+	assignment.IssuerKey.Assign(tedwards.BN254, pub.Bytes())
+
+	if err := backend.VerifyContext(ctx, v.credentialVerifyingKey, proofBytes, &assignment); err != nil {
+		return false, backend.VerifyError(err, "age")
+	}
+	return true, nil
+}
+
+// TrustedIssuers returns the issuer public keys accepted by VerifyCredentialAge.
+func (v *Verifier) TrustedIssuers() []string {
+	out := make([]string, 0, len(v.trustedIssuers))
+	for k := range v.trustedIssuers {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CredentialVerifyingKeyID returns the fingerprint of the embedded credential age verifying key.
+func CredentialVerifyingKeyID() string {
+	return EmbeddedCredentialVerifyingKeyID
+}
@@ -0,0 +1,146 @@
+package age
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// GenerateAgeEnvelope creates an age proof and wraps it in a proof envelope
+// with the prover's params version. Age proofs carry no public inputs; the
+// verifier supplies the date and limit from its policy.
+func (p *Prover) GenerateAgeEnvelope(birthDate int, currentDate int, limitAge int) (envelope.Envelope, error) {
+	return p.GenerateAgeEnvelopeContext(context.Background(), birthDate, currentDate, limitAge)
+}
+
+// GenerateAgeEnvelopeContext is GenerateAgeEnvelope with a context (see
+// GenerateAgeProofContext).
+func (p *Prover) GenerateAgeEnvelopeContext(ctx context.Context, birthDate int, currentDate int, limitAge int) (envelope.Envelope, error) {
+	proof, err := p.GenerateAgeProofContext(ctx, birthDate, currentDate, limitAge)
+	if err != nil {
+		return envelope.Envelope{}, err
+	}
+	return envelope.New(proof, backend.ProofVersion(ProofVersion, p.Backend()), p.VerifyingKeyID(), common.ParamsVersion(p.config), nil), nil
+}
+
+// GenerateCredentialAgeEnvelope creates a credential age proof for challenge
+// and wraps it in a proof envelope with the issuer key as public input. The
+// challenge is not included; the verifier supplies the one it issued.
+func (p *Prover) GenerateCredentialAgeEnvelope(cred Credential, holderSecret string, challenge string, currentDate int, limitAge int) (envelope.Envelope, error) {
+	return p.GenerateCredentialAgeEnvelopeContext(context.Background(), cred, holderSecret, challenge, currentDate, limitAge)
+}
+
+// GenerateCredentialAgeEnvelopeContext is GenerateCredentialAgeEnvelope with a context.
+func (p *Prover) GenerateCredentialAgeEnvelopeContext(ctx context.Context, cred Credential, holderSecret string, challenge string, currentDate int, limitAge int) (envelope.Envelope, error) {
+	proof, err := p.GenerateCredentialAgeProofContext(ctx, cred, holderSecret, challenge, currentDate, limitAge)
+	if err != nil {
+		return envelope.Envelope{}, err
+	}
+	return envelope.New(proof, CredentialProofVersion, p.credentialVKID, common.ParamsVersion(p.config), map[string]string{
+		envelope.InputIssuerKey: cred.IssuerKey,
+	}), nil
+}
+
+// GenerateAgeRangeEnvelope creates an age range proof and wraps it in a proof
+// envelope. The bounds are not included; the verifier enforces its own.
+func (p *Prover) GenerateAgeRangeEnvelope(birthDate int, currentDate int, minAge int, maxAge int) (envelope.Envelope, error) {
+	return p.GenerateAgeRangeEnvelopeContext(context.Background(), birthDate, currentDate, minAge, maxAge)
+}
+
+// GenerateAgeRangeEnvelopeContext is GenerateAgeRangeEnvelope with a context.
+func (p *Prover) GenerateAgeRangeEnvelopeContext(ctx context.Context, birthDate int, currentDate int, minAge int, maxAge int) (envelope.Envelope, error) {
+	proof, err := p.GenerateAgeRangeProofContext(ctx, birthDate, currentDate, minAge, maxAge)
+	if err != nil {
+		return envelope.Envelope{}, err
+	}
+	return envelope.New(proof, RangeProofVersion, p.rangeVKID, common.ParamsVersion(p.config), nil), nil
+}
+
+// VerifyingKeyID returns the fingerprint of the age verifying key that checks
+// this prover's proofs: ProverConfig.VKID, the embedded verifying key for an
+// embedded proving key, or the verifying key found next to the proving key by
+// NewProverFromDir. It is empty when none of these is known.
+func (p *Prover) VerifyingKeyID() string {
+	return p.vkID
+}
+
+// ProofResultFromEnvelope validates an age proof envelope and converts it into
+// a ProofResult whose VKID is the envelope's verifying key ID. Credential age
+// envelopes must carry exactly the issuer key public input, others none.
+func ProofResultFromEnvelope(env envelope.Envelope) (ProofResult, error) {
+	if err := env.Validate(); err != nil {
+		return ProofResult{}, err
+	}
+	var inputs []string
+	if env.ProofVersion == CredentialProofVersion {
+		inputs = append(inputs, envelope.InputIssuerKey)
+	}
+	if err := env.ExpectInputs(inputs...); err != nil {
+		return ProofResult{}, err
+	}
+	return ProofResult{
+		Proof:         env.Proof,
+		ProofVersion:  env.ProofVersion,
+		VKID:          env.VKID,
+		ParamsVersion: env.ParamsVersion,
+		IssuerKey:     env.PublicInputs[envelope.InputIssuerKey],
+	}, nil
+}
+
+// VerifyEnvelope verifies an age, credential age or age range proof envelope,
+// picking the check by proof_version. The envelope's vk_id (E2004) and
+// params_version (E4002) must match this verifier; unknown proof versions are
+// rejected with E4002. challenge is the value issued for a credential age
+// proof (see VerifyCredentialAge); other proof versions ignore it.
+func (v *Verifier) VerifyEnvelope(env envelope.Envelope, challenge string) (bool, error) {
+	return v.VerifyEnvelopeContext(context.Background(), env, challenge)
+}
+
+// VerifyEnvelopeContext is VerifyEnvelope with a context (see VerifyAgeContext).
+func (v *Verifier) VerifyEnvelopeContext(ctx context.Context, env envelope.Envelope, challenge string) (bool, error) {
+	if _, err := v.verifyEnvelope(ctx, env, challenge); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyEnvelope verifies env and returns its decoded proof result.
+func (v *Verifier) verifyEnvelope(ctx context.Context, env envelope.Envelope, challenge string) (ProofResult, error) {
+	res, err := ProofResultFromEnvelope(env)
+	if err != nil {
+		return ProofResult{}, err
+	}
+	var vkID string
+	switch res.ProofVersion {
+	case backend.ProofVersion(ProofVersion, v.Backend()):
+		vkID = v.VerifyingKeyID()
+	case CredentialProofVersion:
+		vkID = v.credentialVKID
+	case RangeProofVersion:
+		vkID = v.rangeVKID
+	default:
+		return ProofResult{}, sdkerrors.Wrap(sdkerrors.ErrPolicyMismatch.Code, "proof version not accepted", fmt.Errorf("%q", res.ProofVersion))
+	}
+	if res.VKID != vkID {
+		return ProofResult{}, sdkerrors.ErrKeyMismatch
+	}
+	if res.ParamsVersion != common.ParamsVersion(v.config) {
+		return ProofResult{}, sdkerrors.ErrPolicyMismatch
+	}
+	switch res.ProofVersion {
+	case CredentialProofVersion:
+		_, err = v.VerifyCredentialAgeContext(ctx, res.Proof, res.IssuerKey, challenge)
+	case RangeProofVersion:
+		_, err = v.VerifyAgeRangeContext(ctx, res.Proof)
+	default:
+		_, err = v.VerifyAgeContext(ctx, res.Proof)
+	}
+	if err != nil {
+		return ProofResult{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,76 @@
+package age
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/cmp"
+)
+
+// Bit widths used by the date gadgets. Years fit in 14 bits, MMDD in 11 bits,
+// so every YYYYMMDD value accepted by SplitDate is below 2^28.
+const (
+	yearBits     = 14
+	monthDayBits = 11
+	dateBits     = 28
+)
+
+func init() {
+	solver.RegisterHint(splitDateHint)
+}
+
+// SplitDate constrains date = year*10000 + monthDay with year < 2^14 and
+// monthDay < 2^11, and returns both parts. The split is unique under these
+// bounds; calendar validity is checked natively by the prover.
+func SplitDate(api frontend.API, date frontend.Variable) (year frontend.Variable, monthDay frontend.Variable) {
+	parts, err := api.Compiler().NewHint(splitDateHint, 2, date)
+	if err != nil {
+		panic(err)
+	}
+	year, monthDay = parts[0], parts[1]
+	bits.ToBinary(api, year, bits.WithNbDigits(yearBits))
+	bits.ToBinary(api, monthDay, bits.WithNbDigits(monthDayBits))
+	api.AssertIsEqual(date, api.Add(api.Mul(year, 10000), monthDay))
+	return year, monthDay
+}
+
+// AgeAt returns the age on currentDate of someone born on birthDate (both YYYYMMDD).
+// mode selects international full age (0) or Korean year-age (1). In full-age mode
+// the age increases once currentDate's MMDD reaches birthDate's MMDD, so a
+// February 29 birthday is reached on March 1 in common years.
+// AgeAt also asserts birthDate <= currentDate, so the result is in [0, 2^14).
+func AgeAt(api frontend.API, birthDate, currentDate, mode frontend.Variable) frontend.Variable {
+	api.AssertIsBoolean(mode)
+
+	birthYear, birthMonthDay := SplitDate(api, birthDate)
+	currentYear, currentMonthDay := SplitDate(api, currentDate)
+
+	dates := cmp.NewBoundedComparator(api, big.NewInt(1<<dateBits), false)
+	dates.AssertIsLessEq(birthDate, currentDate)
+
+	yearAge := api.Sub(currentYear, birthYear)
+	beforeBirthday := dates.IsLess(currentMonthDay, birthMonthDay)
+	fullAge := api.Sub(yearAge, beforeBirthday)
+
+	return api.Select(mode, yearAge, fullAge)
+}
+
+// AssertAgeAtLeast asserts limit <= age for an age returned by AgeAt.
+func AssertAgeAtLeast(api frontend.API, age, limit frontend.Variable) {
+	ages := cmp.NewBoundedComparator(api, big.NewInt(1<<yearBits), false)
+	ages.AssertIsLessEq(limit, age)
+}
+
+// AssertAgeBelow asserts age < limit for an age returned by AgeAt. limit may be
+// NoMaxAge (2^14), which every such age is below.
+func AssertAgeBelow(api frontend.API, age, limit frontend.Variable) {
+	ages := cmp.NewBoundedComparator(api, big.NewInt(1<<(yearBits+1)), false)
+	ages.AssertIsLessEq(api.Add(age, 1), limit)
+}
+
+func splitDateHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].DivMod(inputs[0], big.NewInt(10000), outputs[1])
+	return nil
+}
@@ -1,21 +1,26 @@
 package age
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"os"
 	"testing"
 
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 )
 
 type ageGolden struct {
-	Proof       string `json:"proof"`
-	BirthYear   int    `json:"birth_year"`
-	TargetYear  int    `json:"target_year"`
-	LimitAge    int    `json:"limit_age"`
-	ParamsHash  string `json:"params_version"`
-	VerifyingID string `json:"vk_id"`
+	Proof       string            `json:"proof"`
+	BirthDate   int               `json:"birth_date"`
+	TargetDate  int               `json:"target_date"`
+	AgeMode     string            `json:"age_mode"`
+	LimitAge    int               `json:"limit_age"`
+	ParamsHash  string            `json:"params_version"`
+	VerifyingID string            `json:"vk_id"`
+	Calldata    *backend.Calldata `json:"solidity_calldata"`
 }
 
 func TestGoldenProofAge(t *testing.T) {
@@ -29,8 +34,9 @@ func TestGoldenProofAge(t *testing.T) {
 		t.Fatalf("golden parse failed: %v", err)
 	}
 
-	cfg := common.DefaultSharedConfig()
-	if g.TargetYear != cfg.TargetYear || g.LimitAge != cfg.LimitAge {
+	cfg := common.DefaultSharedConfigWithDate(g.TargetDate)
+	cfg.AgeMode = g.AgeMode
+	if g.LimitAge != cfg.LimitAge {
 		t.Fatalf("golden policy mismatch")
 	}
 	if g.VerifyingID != AgeVerifyingKeyID() {
@@ -54,4 +60,38 @@ func TestGoldenProofAge(t *testing.T) {
 	if err != nil || !ok {
 		t.Fatalf("golden verification failed: %v", err)
 	}
+
+	calldata, err := verifier.SolidityCalldata(ProofResult{Proof: proofBytes, ProofVersion: ProofVersion})
+	if err != nil {
+		t.Fatalf("solidity calldata failed: %v", err)
+	}
+	if g.Calldata == nil || !bytes.Equal(calldata.Encode(), g.Calldata.Encode()) {
+		t.Fatalf("solidity calldata does not match golden vector")
+	}
+}
+
+// TestEmbeddedConstraintSystems fails when a circuit changed without
+// regenerating the shipped constraint systems (go run ./cmd/setup -ccs-only).
+func TestEmbeddedConstraintSystems(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		backend string
+		circuit frontend.Circuit
+		data    []byte
+	}{
+		{"age", backend.Groth16, &AgeCircuit{}, ageCCSData},
+		{"age_plonk", backend.PLONK, &AgeCircuit{}, agePlonkCCSData},
+		{"age_credential", backend.Groth16, &CredentialAgeCircuit{}, credentialCCSData},
+		{"age_range", backend.Groth16, &AgeRangeCircuit{}, rangeCCSData},
+	} {
+		ccs, err := backend.Compile(c.backend, c.circuit)
+		if err != nil {
+			t.Fatalf("%s compile: %v", c.name, err)
+		}
+		var buf bytes.Buffer
+		ccs.WriteTo(&buf)
+		if !bytes.Equal(buf.Bytes(), c.data) {
+			t.Fatalf("%s.ccs is stale", c.name)
+		}
+	}
 }
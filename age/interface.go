@@ -12,8 +12,15 @@ type AgeVerifier interface {
 
 // AgeProver defines the interface for generating age proofs.
 type AgeProver interface {
-	// GenerateAgeProof creates a proof for age verification without revealing birth year.
-	GenerateAgeProof(birthYear int, currentYear int, limitAge int) ([]byte, error)
+	// GenerateAgeProof creates a proof for age verification without revealing the birth date (YYYYMMDD).
+	GenerateAgeProof(birthDate int, currentDate int, limitAge int) ([]byte, error)
+}
+
+// CredentialAgeVerifier verifies age proofs over issuer-signed birth dates.
+type CredentialAgeVerifier interface {
+	// VerifyCredentialAge validates a proof made with a credential from a trusted
+	// issuer for the verifier-issued challenge.
+	VerifyCredentialAge(proof []byte, issuerKey string, challenge string) (bool, error)
 }
 
 // MetaAgeVerifier enforces metadata matching for vk_id and params_version.
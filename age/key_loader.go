@@ -0,0 +1,166 @@
+package age
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// Key file names (without extension) written by identify-cli generate-keys.
+const (
+	keyFileAge        = "age"
+	keyFileAgePlonk   = "age_plonk"
+	keyFileCredential = "age_credential"
+	keyFileRange      = "age_range"
+)
+
+// NewProverFromPK creates an age prover from proving key bytes, e.g. from a
+// custom setup ceremony. The key must belong to cfg.Backend; cfg.ExpectedPK,
+// when set, must equal its fingerprint (E2004). Credential and range proofs
+// use the embedded keys.
+func NewProverFromPK(pkBytes []byte, cfg ProverConfig) (*Prover, error) {
+	return newProver(pkBytes, cfg)
+}
+
+// NewProverFromFile is NewProverFromPK for a proving key file.
+func NewProverFromFile(path string, cfg ProverConfig) (*Prover, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newProver(data, cfg)
+}
+
+// NewProverFromDir creates an age prover from a key directory written by
+// identify-cli generate-keys: age.pk (age_plonk.pk for PLONK) and, when
+// present, age_credential.pk and age_range.pk. The fingerprints of the
+// verifying keys next to them are used as envelope vk_ids unless cfg.VKID is set.
+func NewProverFromDir(dir string, cfg ProverConfig) (*Prover, error) {
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return nil, err
+	}
+	name := keyFileAge
+	if backendName == backend.PLONK {
+		name = keyFileAgePlonk
+	}
+	data, err := readKeyFile(filepath.Join(dir, name+".pk"))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.VKID == "" {
+		if cfg.VKID, err = optionalKeyID(filepath.Join(dir, name+".vk")); err != nil {
+			return nil, err
+		}
+	}
+	p, err := newProver(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range []struct {
+		name string
+		data *[]byte
+		vkID *string
+	}{
+		{keyFileCredential, &p.credentialPKData, &p.credentialVKID},
+		{keyFileRange, &p.rangePKData, &p.rangeVKID},
+	} {
+		pkData, err := readOptionalKeyFile(filepath.Join(dir, k.name+".pk"))
+		if err != nil {
+			return nil, err
+		}
+		if pkData == nil {
+			continue
+		}
+		vkID, err := optionalKeyID(filepath.Join(dir, k.name+".vk"))
+		if err != nil {
+			return nil, err
+		}
+		*k.data, *k.vkID = pkData, vkID
+	}
+	return p, nil
+}
+
+// NewVerifierFromVK creates an age verifier from verifying key bytes for
+// cfg.Backend; cfg.ExpectedVK, when set, must equal its fingerprint (E2004).
+// Credential and range proofs use the embedded keys.
+func NewVerifierFromVK(vkBytes []byte, cfg VerifierConfig) (*Verifier, error) {
+	return newVerifier(vkBytes, credentialVerifyingKeyData, rangeVerifyingKeyData, cfg)
+}
+
+// NewVerifierFromFile is NewVerifierFromVK for a verifying key file.
+func NewVerifierFromFile(path string, cfg VerifierConfig) (*Verifier, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifierFromVK(data, cfg)
+}
+
+// NewVerifierFromDir creates an age verifier from a key directory written by
+// identify-cli generate-keys: age.vk (age_plonk.vk for PLONK) and, when
+// present, age_credential.vk and age_range.vk.
+func NewVerifierFromDir(dir string, cfg VerifierConfig) (*Verifier, error) {
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return nil, err
+	}
+	name := keyFileAge
+	if backendName == backend.PLONK {
+		name = keyFileAgePlonk
+	}
+	data, err := readKeyFile(filepath.Join(dir, name+".vk"))
+	if err != nil {
+		return nil, err
+	}
+	credentialData, err := readOptionalKeyFile(filepath.Join(dir, keyFileCredential+".vk"))
+	if err != nil {
+		return nil, err
+	}
+	if credentialData == nil {
+		credentialData = credentialVerifyingKeyData
+	}
+	rangeData, err := readOptionalKeyFile(filepath.Join(dir, keyFileRange+".vk"))
+	if err != nil {
+		return nil, err
+	}
+	if rangeData == nil {
+		rangeData = rangeVerifyingKeyData
+	}
+	return newVerifier(data, credentialData, rangeData, cfg)
+}
+
+// readKeyFile reads a key file, reporting failures as E2003.
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyRead.Code, "key file read failed", err)
+	}
+	return data, nil
+}
+
+// readOptionalKeyFile is readKeyFile returning nil when the file does not exist.
+func readOptionalKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyRead.Code, "key file read failed", err)
+	}
+	return data, nil
+}
+
+// optionalKeyID returns the fingerprint of the verifying key at path, or ""
+// when the file does not exist.
+func optionalKeyID(path string) (string, error) {
+	data, err := readOptionalKeyFile(path)
+	if err != nil || data == nil {
+		return "", err
+	}
+	return backend.KeyID(data), nil
+}
@@ -0,0 +1,56 @@
+package age
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestKeyLoading(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"age.pk", "age.vk"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s failed: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewProverFromDir(dir, ProverConfig{Config: cfg, ExpectedPK: EmbeddedAgeProvingKeyID})
+	if err != nil {
+		t.Fatalf("prover from dir failed: %v", err)
+	}
+	if prover.VerifyingKeyID() != EmbeddedAgeVerifyingKeyID {
+		t.Fatalf("unexpected vk id: %s", prover.VerifyingKeyID())
+	}
+	verifier, err := NewVerifierFromDir(dir, VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier from dir failed: %v", err)
+	}
+	env, err := prover.GenerateAgeEnvelope(20000101, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyEnvelope(env, ""); err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+
+	if _, err := NewVerifierFromFile("age.vk", VerifierConfig{Config: cfg, ExpectedVK: EmbeddedAgePlonkVerifyingKeyID}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected E2004, got %v", err)
+	}
+	if _, err := NewProverFromFile("age.pk", ProverConfig{Config: cfg, ExpectedPK: EmbeddedAgePlonkProvingKeyID}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected E2004, got %v", err)
+	}
+	if _, err := NewProverFromDir(dir, ProverConfig{Config: cfg, Backend: "plonk"}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyRead.Code {
+		t.Fatalf("expected E2003 for missing age_plonk.pk, got %v", err)
+	}
+	if _, err := NewProverFromPK(nil, ProverConfig{Config: cfg}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyNotFound.Code {
+		t.Fatalf("expected E1004, got %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package age
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+)
 
 // ProvingKeyBytes returns a copy of the embedded proving key bytes.
 func ProvingKeyBytes() []byte {
@@ -13,3 +17,23 @@ func ProvingKeyBytes() []byte {
 func ProvingKeyBase64() string {
 	return base64.StdEncoding.EncodeToString(ageProvingKeyData)
 }
+
+// PlonkProvingKeyBytes returns a copy of the embedded PLONK age proving key bytes.
+func PlonkProvingKeyBytes() []byte {
+	out := make([]byte, len(agePlonkProvingKeyData))
+	copy(out, agePlonkProvingKeyData)
+	return out
+}
+
+// RangeProvingKeyBase64 returns the embedded age range proving key as a base64 string.
+func RangeProvingKeyBase64() string {
+	return base64.StdEncoding.EncodeToString(rangeProvingKeyData)
+}
+
+// ProvingKeyBase64For returns the embedded age proving key for a backend as a base64 string.
+func ProvingKeyBase64For(backendName string) string {
+	if backendName == backend.PLONK {
+		return base64.StdEncoding.EncodeToString(agePlonkProvingKeyData)
+	}
+	return base64.StdEncoding.EncodeToString(ageProvingKeyData)
+}
@@ -10,6 +10,15 @@ type PolicyBundle struct {
 	Config        common.SharedConfig `json:"config"`
 	ParamsVersion string              `json:"params_version"`
 	VKID          string              `json:"vk_id"`
+	AgeMode       string              `json:"age_mode"`
+	Backend       string              `json:"backend"`       // backend.Groth16 or backend.PLONK
+	ProofVersion  string              `json:"proof_version"` // ProofVersion, "-plonk" suffixed for PLONK
+	// Credential age proofs (CredentialAgeCircuit)
+	CredentialVKID string   `json:"credential_vk_id,omitempty"`
+	TrustedIssuers []string `json:"trusted_issuers,omitempty"`
+	// Age range proofs (AgeRangeCircuit), set when the verifier enforces bounds
+	AgeRange  *AgeRange `json:"age_range,omitempty"`
+	RangeVKID string    `json:"range_vk_id,omitempty"`
 }
 
 // EnforcePolicy checks vk_id and params_version against the server bundle.
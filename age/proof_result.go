@@ -1,28 +1,51 @@
 package age
 
-import "github.com/ghdehrl12345/identify_sdk/v2/common"
+import (
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
 
 // ProofVersion is the semantic version for age proofs.
-const ProofVersion = "age-proof-v1"
+const ProofVersion = "age-proof-v2"
 
-// ProofResult contains proof bytes and metadata for wire transfer.
+// CredentialProofVersion is the semantic version for credential age proofs.
+const CredentialProofVersion = "age-credential-proof-v1"
+
+// ProofResult contains proof bytes and metadata for wire transfer. Envelope
+// converts it into the canonical proof envelope.
 type ProofResult struct {
-	Proof         []byte
-	ProofVersion  string
-	VKID          string
-	ParamsVersion string
+	Proof         []byte `json:"proof"`
+	ProofVersion  string `json:"proof_version"`
+	VKID          string `json:"vk_id"`
+	ParamsVersion string `json:"params_version"`
+	IssuerKey     string `json:"issuer_key,omitempty"` // set for credential age proofs
 }
 
 // GenerateProofResult creates an age proof with metadata.
-func (p *Prover) GenerateProofResult(birthYear int, currentYear int, limitAge int) (ProofResult, error) {
-	proof, err := p.GenerateAgeProof(birthYear, currentYear, limitAge)
+func (p *Prover) GenerateProofResult(birthDate int, currentDate int, limitAge int) (ProofResult, error) {
+	proof, err := p.GenerateAgeProof(birthDate, currentDate, limitAge)
+	if err != nil {
+		return ProofResult{}, err
+	}
+	return ProofResult{
+		Proof:         proof,
+		ProofVersion:  backend.ProofVersion(ProofVersion, p.Backend()),
+		VKID:          p.VerifyingKeyID(),
+		ParamsVersion: common.ParamsVersion(p.config),
+	}, nil
+}
+
+// GenerateCredentialProofResult creates a credential age proof for challenge with metadata.
+func (p *Prover) GenerateCredentialProofResult(cred Credential, holderSecret string, challenge string, currentDate int, limitAge int) (ProofResult, error) {
+	proof, err := p.GenerateCredentialAgeProof(cred, holderSecret, challenge, currentDate, limitAge)
 	if err != nil {
 		return ProofResult{}, err
 	}
 	return ProofResult{
 		Proof:         proof,
-		ProofVersion:  ProofVersion,
-		VKID:          AgeProvingKeyID(),
+		ProofVersion:  CredentialProofVersion,
+		VKID:          p.credentialVKID,
 		ParamsVersion: common.ParamsVersion(p.config),
+		IssuerKey:     cred.IssuerKey,
 	}, nil
 }
@@ -1,32 +1,70 @@
 package age
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
-	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
-	"golang.org/x/crypto/blake2b"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
 //go:embed age.pk
 var ageProvingKeyData []byte
 
 // EmbeddedAgeProvingKeyID is the blake2b-256 fingerprint of the embedded age proving key.
-var EmbeddedAgeProvingKeyID = blake2bAgeSumHex(ageProvingKeyData)
+var EmbeddedAgeProvingKeyID = backend.KeyID(ageProvingKeyData)
+
+//go:embed age_plonk.pk
+var agePlonkProvingKeyData []byte
+
+// Serialized age constraint systems written by cmd/setup next to the proving
+// keys, so provers skip compiling.
+var (
+	//go:embed age.ccs
+	ageCCSData []byte
+
+	//go:embed age_plonk.ccs
+	agePlonkCCSData []byte
+)
+
+// EmbeddedAgePlonkProvingKeyID is the blake2b-256 fingerprint of the embedded PLONK age proving key.
+var EmbeddedAgePlonkProvingKeyID = backend.KeyID(agePlonkProvingKeyData)
 
 // Prover implements the AgeProver interface for generating age proofs.
+// Credential age proofs always use Groth16.
 type Prover struct {
-	provingKey groth16.ProvingKey
+	provingKey *backend.ProvingKey
 	ccs        constraint.ConstraintSystem
 	config     common.SharedConfig
+	pkID       string
+	vkID       string
+
+	credentialOnce       sync.Once
+	credentialPKData     []byte
+	credentialVKID       string
+	credentialProvingKey *backend.ProvingKey
+	credentialCCS        constraint.ConstraintSystem
+	credentialErr        error
+
+	rangeOnce       sync.Once
+	rangePKData     []byte
+	rangeVKID       string
+	rangeProvingKey *backend.ProvingKey
+	rangeCCS        constraint.ConstraintSystem
+	rangeErr        error
+}
+
+// ProverConfig holds configuration for an age prover built from external keys.
+type ProverConfig struct {
+	Config     common.SharedConfig
+	Backend    string // optional: backend.Groth16 (default) or backend.PLONK for age proofs
+	ExpectedPK string // optional: expected proving key fingerprint
+	VKID       string // optional: verifying key fingerprint for an external proving key (envelope vk_id)
 }
 
 // NewProver creates an age prover with default config.
@@ -36,68 +74,133 @@ func NewProver() (*Prover, error) {
 
 // NewProverWithConfig creates an age prover with custom config.
 func NewProverWithConfig(cfg common.SharedConfig) (*Prover, error) {
-	var circuit AgeCircuit
-	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	return NewProverWithBackend(cfg, backend.Groth16)
+}
+
+// NewProverWithBackend creates an age prover using the embedded key for the
+// given proving backend (backend.Groth16 or backend.PLONK).
+func NewProverWithBackend(cfg common.SharedConfig, backendName string) (*Prover, error) {
+	backendName, err := backend.Normalize(backendName)
 	if err != nil {
-		return nil, fmt.Errorf("age circuit compile failed: %w", err)
+		return nil, err
 	}
+	pkData := ageProvingKeyData
+	if backendName == backend.PLONK {
+		pkData = agePlonkProvingKeyData
+	}
+	return newProver(pkData, ProverConfig{Config: cfg, Backend: backendName})
+}
 
-	pk := groth16.NewProvingKey(ecc.BN254)
-	if _, err := pk.ReadFrom(bytes.NewReader(ageProvingKeyData)); err != nil {
-		return nil, fmt.Errorf("age proving key parse failed: %w", err)
+// newProver creates an age prover from proving key bytes for cfg.Backend. The
+// credential and range keys default to the embedded ones.
+func newProver(pkData []byte, cfg ProverConfig) (*Prover, error) {
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkData) == 0 {
+		return nil, sdkerrors.New(sdkerrors.ErrKeyNotFound.Code, "age proving key is empty (run setup)")
+	}
+	pkID := backend.KeyID(pkData)
+	if cfg.ExpectedPK != "" && cfg.ExpectedPK != pkID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "age proving key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedPK, pkID))
+	}
+	ccsData := ageCCSData
+	if backendName == backend.PLONK {
+		ccsData = agePlonkCCSData
+	}
+	ccs, err := backend.LoadConstraintSystem(backendName, ccsData, &AgeCircuit{})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCircuitCompile.Code, "age circuit load failed", err)
+	}
+	pk, err := backend.LoadProvingKey(backendName, pkData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "age proving key parse failed", err)
+	}
+
+	// The embedded verifying key matches only the embedded proving key.
+	vkID := cfg.VKID
+	switch {
+	case vkID != "":
+	case pkID == EmbeddedAgeProvingKeyID:
+		vkID = EmbeddedAgeVerifyingKeyID
+	case pkID == EmbeddedAgePlonkProvingKeyID:
+		vkID = EmbeddedAgePlonkVerifyingKeyID
 	}
 
 	return &Prover{
-		provingKey: pk,
-		ccs:        ccs,
-		config:     cfg,
+		provingKey:       pk,
+		ccs:              ccs,
+		config:           cfg.Config,
+		pkID:             pkID,
+		vkID:             vkID,
+		credentialPKData: credentialProvingKeyData,
+		credentialVKID:   EmbeddedCredentialVerifyingKeyID,
+		rangePKData:      rangeProvingKeyData,
+		rangeVKID:        EmbeddedRangeVerifyingKeyID,
 	}, nil
 }
 
 // GenerateAgeProof creates a proof for age verification.
-func (p *Prover) GenerateAgeProof(birthYear int, currentYear int, limitAge int) ([]byte, error) {
-	if currentYear == 0 {
-		currentYear = p.config.TargetYear
+// birthDate and currentDate are YYYYMMDD; the age is counted with the configured AgeMode.
+func (p *Prover) GenerateAgeProof(birthDate int, currentDate int, limitAge int) ([]byte, error) {
+	return p.GenerateAgeProofContext(context.Background(), birthDate, currentDate, limitAge)
+}
+
+// GenerateAgeProofContext is GenerateAgeProof that gives up once ctx is
+// canceled or its deadline passes, returning an E1019 error. The backend
+// prover cannot be interrupted, so an abandoned proof finishes in the
+// background.
+func (p *Prover) GenerateAgeProofContext(ctx context.Context, birthDate int, currentDate int, limitAge int) ([]byte, error) {
+	if currentDate == 0 {
+		currentDate = p.config.CurrentDate()
 	}
 	if limitAge == 0 {
 		limitAge = p.config.LimitAge
 	}
+	if err := common.ValidateDate(birthDate); err != nil {
+		return nil, fmt.Errorf("age birth date: %w", err)
+	}
+	mode, err := common.AgeModeCode(p.config.AgeMode)
+	if err != nil {
+		return nil, err
+	}
 
 	var publicCurr big.Int
-	publicCurr.SetInt64(int64(currentYear))
+	publicCurr.SetInt64(int64(currentDate))
 	var publicLimit big.Int
 	publicLimit.SetInt64(int64(limitAge))
 
 	assignment := AgeCircuit{
-		CurrentYear: publicCurr,
+		CurrentDate: publicCurr,
 		LimitAge:    publicLimit,
-		BirthYear:   birthYear,
+		Mode:        mode,
+		BirthDate:   birthDate,
 	}
 
-	witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	proof, err := backend.ProveContext(ctx, p.ccs, p.provingKey, &assignment)
 	if err != nil {
-		return nil, fmt.Errorf("age witness creation failed: %w", err)
+		return nil, fmt.Errorf("age %w", err)
 	}
+	return proof, nil
+}
 
-	proof, err := groth16.Prove(p.ccs, p.provingKey, witness)
-	if err != nil {
-		return nil, fmt.Errorf("age proof generation failed: %w", err)
-	}
+// AgeMode returns the age counting mode used for proofs.
+func (p *Prover) AgeMode() string {
+	return ageModeOrDefault(p.config.AgeMode)
+}
+
+// Backend returns the proving backend used for age proofs.
+func (p *Prover) Backend() string {
+	return p.provingKey.Backend
+}
 
-	var buf bytes.Buffer
-	proof.WriteTo(&buf)
-	return buf.Bytes(), nil
+// ProvingKeyID returns the fingerprint of the age proving key this prover was built from.
+func (p *Prover) ProvingKeyID() string {
+	return p.pkID
 }
 
 // AgeProvingKeyID returns the fingerprint of the embedded age proving key.
 func AgeProvingKeyID() string {
 	return EmbeddedAgeProvingKeyID
 }
-
-func blake2bAgeSumHex(data []byte) string {
-	if len(data) == 0 {
-		return ""
-	}
-	sum := blake2b.Sum256(data)
-	return hex.EncodeToString(sum[:])
-}
@@ -1,9 +1,15 @@
 package age
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
@@ -18,7 +24,7 @@ func TestAgeProofVerification(t *testing.T) {
 		t.Fatalf("verifier init failed: %v", err)
 	}
 
-	proof, err := prover.GenerateAgeProof(2000, cfg.TargetYear, cfg.LimitAge)
+	proof, err := prover.GenerateAgeProof(20000101, cfg.CurrentDate(), cfg.LimitAge)
 	if err != nil {
 		t.Fatalf("proof generation failed: %v", err)
 	}
@@ -29,6 +35,33 @@ func TestAgeProofVerification(t *testing.T) {
 	}
 }
 
+func TestAgePlonkBackend(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewProverWithBackend(cfg, backend.PLONK)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Backend: backend.PLONK})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	res, err := prover.GenerateProofResult(20000101, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if res.ProofVersion != ProofVersion+"-plonk" || res.VKID != EmbeddedAgePlonkVerifyingKeyID {
+		t.Fatalf("unexpected proof metadata: %s %s", res.ProofVersion, res.VKID)
+	}
+	ok, err := verifier.VerifyAgeWithMeta(res.Proof, EmbeddedAgePlonkVerifyingKeyID, res.ParamsVersion)
+	if err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if bundle := verifier.PolicyBundle(); bundle.Backend != backend.PLONK || bundle.VKID != EmbeddedAgePlonkVerifyingKeyID {
+		t.Fatalf("policy bundle does not signal plonk: %+v", bundle)
+	}
+}
+
 func TestAgeVerifyWithMeta(t *testing.T) {
 	cfg := common.DefaultSharedConfig()
 	prover, err := NewProverWithConfig(cfg)
@@ -40,7 +73,7 @@ func TestAgeVerifyWithMeta(t *testing.T) {
 		t.Fatalf("verifier init failed: %v", err)
 	}
 
-	proof, err := prover.GenerateAgeProof(2000, cfg.TargetYear, cfg.LimitAge)
+	proof, err := prover.GenerateAgeProof(20000101, cfg.CurrentDate(), cfg.LimitAge)
 	if err != nil {
 		t.Fatalf("proof generation failed: %v", err)
 	}
@@ -50,3 +83,321 @@ func TestAgeVerifyWithMeta(t *testing.T) {
 		t.Fatalf("expected policy mismatch error, got %v", err)
 	}
 }
+
+func TestAgeDatePrecision(t *testing.T) {
+	cases := []struct {
+		name   string
+		mode   string
+		birth  int
+		today  int
+		passes bool
+	}{
+		{"day before 20th birthday", common.AgeModeInternational, 20061231, 20261230, false},
+		{"on 20th birthday", common.AgeModeInternational, 20061231, 20261231, true},
+		{"december birth in january", common.AgeModeInternational, 20061201, 20260110, false},
+		{"leap day birth on feb 28", common.AgeModeInternational, 20040229, 20240228, false},
+		{"leap day birth on mar 1", common.AgeModeInternational, 20040229, 20240301, true},
+		{"korean year-age in january", common.AgeModeKoreanYear, 20061201, 20260110, true},
+		{"korean year-age one year short", common.AgeModeKoreanYear, 20070101, 20261231, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := common.DefaultSharedConfigWithDate(tc.today)
+			cfg.AgeMode = tc.mode
+			prover, err := NewProverWithConfig(cfg)
+			if err != nil {
+				t.Fatalf("prover init failed: %v", err)
+			}
+			if got := common.AgeAt(tc.birth, tc.today, tc.mode) >= cfg.LimitAge; got != tc.passes {
+				t.Fatalf("native age check = %v, want %v", got, tc.passes)
+			}
+
+			proof, err := prover.GenerateAgeProof(tc.birth, 0, 0)
+			if !tc.passes {
+				if err == nil {
+					t.Fatalf("expected proof generation to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("proof generation failed: %v", err)
+			}
+			verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+			if err != nil {
+				t.Fatalf("verifier init failed: %v", err)
+			}
+			if ok, err := verifier.VerifyAge(proof); err != nil || !ok {
+				t.Fatalf("verification failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAgeModeMismatch(t *testing.T) {
+	cfg := common.DefaultSharedConfigWithDate(20260110)
+	cfg.AgeMode = common.AgeModeKoreanYear
+	prover, err := NewProverWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	proof, err := prover.GenerateAgeProof(20061201, 0, 0)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+
+	strict := cfg
+	strict.AgeMode = common.AgeModeInternational
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: strict})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	if _, err := verifier.VerifyAge(proof); err == nil {
+		t.Fatalf("expected verification failure for mismatched age mode")
+	}
+	if verifier.PolicyBundle().AgeMode != common.AgeModeInternational {
+		t.Fatalf("policy bundle should expose the enforced age mode")
+	}
+}
+
+func TestCredentialAgeProof(t *testing.T) {
+	issuer, err := GenerateIssuer()
+	if err != nil {
+		t.Fatalf("issuer init failed: %v", err)
+	}
+	holderSecret, err := commitment.NewHolderSecret()
+	if err != nil {
+		t.Fatalf("holder secret failed: %v", err)
+	}
+	holder, err := commitment.HolderCommitment(holderSecret)
+	if err != nil {
+		t.Fatalf("holder commitment failed: %v", err)
+	}
+	cred, err := issuer.Issue(20000101, holder)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewProverWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TrustedIssuers: []string{issuer.PublicKey()}})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	challenge := "123456789012345678901234567890"
+	res, err := prover.GenerateCredentialProofResult(cred, holderSecret, challenge, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	ok, err := verifier.VerifyCredentialAge(res.Proof, res.IssuerKey, challenge)
+	if err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if _, err := verifier.VerifyCredentialAge(res.Proof, res.IssuerKey, "123456789012345678901234567891"); err == nil {
+		t.Fatal("expected a proof replayed for another challenge to fail")
+	}
+	if _, err := verifier.VerifyCredentialAge(res.Proof, res.IssuerKey, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected missing challenge error, got %v", err)
+	}
+
+	otherSecret, err := commitment.NewHolderSecret()
+	if err != nil {
+		t.Fatalf("holder secret failed: %v", err)
+	}
+	if _, err := prover.GenerateCredentialAgeProof(cred, otherSecret, challenge, 0, 0); sdkerrors.CodeOf(err) != sdkerrors.ErrCredentialInvalid.Code {
+		t.Fatalf("expected a foreign holder secret to be rejected, got %v", err)
+	}
+
+	other, err := GenerateIssuer()
+	if err != nil {
+		t.Fatalf("issuer init failed: %v", err)
+	}
+	if _, err := verifier.VerifyCredentialAge(res.Proof, other.PublicKey(), challenge); err != sdkerrors.ErrIssuerUntrusted {
+		t.Fatalf("expected untrusted issuer error, got %v", err)
+	}
+}
+
+func TestCredentialTampered(t *testing.T) {
+	issuer, err := GenerateIssuer()
+	if err != nil {
+		t.Fatalf("issuer init failed: %v", err)
+	}
+	holderSecret, err := commitment.NewHolderSecret()
+	if err != nil {
+		t.Fatalf("holder secret failed: %v", err)
+	}
+	holder, err := commitment.HolderCommitment(holderSecret)
+	if err != nil {
+		t.Fatalf("holder commitment failed: %v", err)
+	}
+	cred, err := issuer.Issue(20100101, holder)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	cred.BirthDate = 19900101
+
+	if err := VerifyCredential(cred); err != sdkerrors.ErrCredentialInvalid {
+		t.Fatalf("expected invalid credential error, got %v", err)
+	}
+	prover, err := NewProver()
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	if _, err := prover.GenerateCredentialAgeProof(cred, holderSecret, "42", 0, 0); err == nil {
+		t.Fatal("expected proof generation to reject a tampered credential")
+	}
+}
+
+func TestVerifyAgeBatch(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	for _, name := range []string{backend.Groth16, backend.PLONK} {
+		prover, err := NewProverWithBackend(cfg, name)
+		if err != nil {
+			t.Fatalf("%s prover init failed: %v", name, err)
+		}
+		verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Backend: name})
+		if err != nil {
+			t.Fatalf("%s verifier init failed: %v", name, err)
+		}
+
+		var proofs [][]byte
+		for _, birthDate := range []int{19800101, 19900101, 20000101} {
+			proof, err := prover.GenerateAgeProof(birthDate, cfg.CurrentDate(), cfg.LimitAge)
+			if err != nil {
+				t.Fatalf("%s proof generation failed: %v", name, err)
+			}
+			proofs = append(proofs, proof)
+		}
+		// Proven against last year's date, so it does not match the verifier's policy.
+		stale, err := prover.GenerateAgeProof(19900101, cfg.CurrentDate()-10000, cfg.LimitAge)
+		if err != nil {
+			t.Fatalf("%s proof generation failed: %v", name, err)
+		}
+		proofs = append(proofs, stale, []byte("garbage"))
+
+		want := []string{"", "", "", sdkerrors.ErrVerificationFail.Code, sdkerrors.ErrProofFormat.Code}
+		for i, res := range verifier.VerifyAgeBatch(proofs) {
+			if res.Valid != (want[i] == "") || res.Code != want[i] {
+				t.Fatalf("%s item %d: expected code %q, got valid=%v code=%q err=%v", name, i, want[i], res.Valid, res.Code, res.Err)
+			}
+		}
+	}
+}
+
+func TestAgeRangeProof(t *testing.T) {
+	cfg := common.DefaultSharedConfigWithDate(20250615)
+	prover, err := NewProverWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, AgeRange: AgeRange{Min: 18, Max: 65}})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	// 18 on the day of the proof; 65 the day after.
+	proof, err := prover.GenerateAgeRangeProof(20070615, 0, 18, 65)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyAgeRange(proof); err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if _, err := prover.GenerateAgeRangeProof(19600615, 0, 18, 65); err == nil {
+		t.Fatal("expected out of range error at the exclusive upper bound")
+	}
+
+	// An under-19 proof does not satisfy the 18-65 policy.
+	under, err := prover.GenerateAgeRangeProof(20070615, 0, 0, 19)
+	if err != nil {
+		t.Fatalf("under-age proof generation failed: %v", err)
+	}
+	if _, err := verifier.VerifyAgeRange(under); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+		t.Fatalf("expected E1003 for other bounds, got %v", err)
+	}
+	youth, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, AgeRange: AgeRange{Max: 19}})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	if ok, err := youth.VerifyAgeRange(under); err != nil || !ok {
+		t.Fatalf("under-age verification failed: %v", err)
+	}
+
+	bundle := verifier.PolicyBundle()
+	if bundle.AgeRange == nil || *bundle.AgeRange != (AgeRange{Min: 18, Max: 65}) || bundle.RangeVKID != RangeVerifyingKeyID() {
+		t.Fatalf("policy bundle does not carry the range: %+v", bundle)
+	}
+	if _, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, AgeRange: AgeRange{Min: 30, Max: 20}}); sdkerrors.CodeOf(err) != sdkerrors.ErrInvalidConfig.Code {
+		t.Fatalf("expected E4003 for an empty range, got %v", err)
+	}
+}
+
+func TestAgeEnvelope(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewProverWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	env, err := prover.GenerateAgeEnvelope(20000101, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	decoded, err := envelope.DecodeJSON(data)
+	if err != nil {
+		t.Fatalf("json decode failed: %v", err)
+	}
+	ok, err := verifier.VerifyEnvelope(decoded, "")
+	if err != nil || !ok {
+		t.Fatalf("envelope verification failed: %v", err)
+	}
+
+	wrongKey := decoded
+	wrongKey.VKID = CredentialVerifyingKeyID()
+	if _, err := verifier.VerifyEnvelope(wrongKey, ""); err != sdkerrors.ErrKeyMismatch {
+		t.Fatalf("expected key mismatch, got %v", err)
+	}
+	unknown := decoded
+	unknown.ProofVersion = "age-proof-v1"
+	if _, err := verifier.VerifyEnvelope(unknown, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrPolicyMismatch.Code {
+		t.Fatalf("expected proof version rejection, got %v", err)
+	}
+}
+
+func TestAgeContextCanceled(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewProverWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prover.GenerateAgeProofContext(canceled, 20000101, cfg.CurrentDate(), cfg.LimitAge); sdkerrors.CodeOf(err) != sdkerrors.ErrCanceled.Code {
+		t.Fatalf("expected canceled proof generation, got %v", err)
+	}
+	env, err := prover.GenerateAgeEnvelope(20000101, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	_, err = verifier.VerifyEnvelopeContext(canceled, env, "")
+	if sdkerrors.CodeOf(err) != sdkerrors.ErrCanceled.Code || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled verification, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package age
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// RangeProofVersion is the semantic version for age range proofs.
+const RangeProofVersion = "age-range-proof-v1"
+
+//go:embed age_range.pk
+var rangeProvingKeyData []byte
+
+//go:embed age_range.vk
+var rangeVerifyingKeyData []byte
+
+//go:embed age_range.ccs
+var rangeCCSData []byte
+
+// EmbeddedRangeProvingKeyID is the blake2b-256 fingerprint of the embedded age range proving key.
+var EmbeddedRangeProvingKeyID = backend.KeyID(rangeProvingKeyData)
+
+// EmbeddedRangeVerifyingKeyID is the blake2b-256 fingerprint of the embedded age range verifying key.
+var EmbeddedRangeVerifyingKeyID = backend.KeyID(rangeVerifyingKeyData)
+
+// AgeRange bounds an age as Min <= age < Max. Zero leaves a bound open, so
+// {Max: 19} is "under 19" and {Min: 18, Max: 65} is "18 to 64".
+type AgeRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// Validate checks that the bounds are non-negative, below NoMaxAge and non-empty.
+func (r AgeRange) Validate() error {
+	if r.Min < 0 || r.Max < 0 || r.Min >= NoMaxAge || r.Max >= NoMaxAge {
+		return fmt.Errorf("age range bounds out of range: [%d, %d)", r.Min, r.Max)
+	}
+	if r.Max != 0 && r.Max <= r.Min {
+		return fmt.Errorf("age range is empty: [%d, %d)", r.Min, r.Max)
+	}
+	return nil
+}
+
+// IsZero reports whether both bounds are open.
+func (r AgeRange) IsZero() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Contains reports whether age lies in the range.
+func (r AgeRange) Contains(age int) bool {
+	return age >= r.Min && (r.Max == 0 || age < r.Max)
+}
+
+// GenerateAgeRangeProof proves minAge <= age < maxAge for a birth date (YYYYMMDD);
+// 0 leaves a bound open. currentDate 0 selects the configured date.
+// Age range proofs always use Groth16; the circuit is compiled on first use.
+func (p *Prover) GenerateAgeRangeProof(birthDate int, currentDate int, minAge int, maxAge int) ([]byte, error) {
+	return p.GenerateAgeRangeProofContext(context.Background(), birthDate, currentDate, minAge, maxAge)
+}
+
+// GenerateAgeRangeProofContext is GenerateAgeRangeProof with a context (see
+// GenerateAgeProofContext).
+func (p *Prover) GenerateAgeRangeProofContext(ctx context.Context, birthDate int, currentDate int, minAge int, maxAge int) ([]byte, error) {
+	if currentDate == 0 {
+		currentDate = p.config.CurrentDate()
+	}
+	bounds := AgeRange{Min: minAge, Max: maxAge}
+	if err := bounds.Validate(); err != nil {
+		return nil, err
+	}
+	if err := common.ValidateDate(birthDate); err != nil {
+		return nil, fmt.Errorf("age birth date: %w", err)
+	}
+	if age := common.AgeAt(birthDate, currentDate, p.config.AgeMode); !bounds.Contains(age) {
+		return nil, fmt.Errorf("age is outside the range [%d, %d)", minAge, maxAge)
+	}
+	if err := p.loadRangeCircuit(); err != nil {
+		return nil, err
+	}
+	assignment, err := rangeAssignment(currentDate, bounds, p.config.AgeMode)
+	if err != nil {
+		return nil, err
+	}
+	assignment.BirthDate = birthDate
+
+	proof, err := backend.ProveContext(ctx, p.rangeCCS, p.rangeProvingKey, assignment)
+	if err != nil {
+		return nil, fmt.Errorf("age range %w", err)
+	}
+	return proof, nil
+}
+
+func (p *Prover) loadRangeCircuit() error {
+	p.rangeOnce.Do(func() {
+		ccs, err := backend.LoadConstraintSystem(backend.Groth16, rangeCCSData, &AgeRangeCircuit{})
+		if err != nil {
+			p.rangeErr = fmt.Errorf("age range circuit load failed: %w", err)
+			return
+		}
+		pk, err := backend.LoadProvingKey(backend.Groth16, p.rangePKData)
+		if err != nil {
+			p.rangeErr = fmt.Errorf("age range proving key parse failed: %w", err)
+			return
+		}
+		p.rangeCCS = ccs
+		p.rangeProvingKey = pk
+	})
+	return p.rangeErr
+}
+
+// VerifyAgeRange validates an age range proof against the bounds in
+// VerifierConfig.AgeRange and the configured current date.
+func (v *Verifier) VerifyAgeRange(proofBytes []byte) (bool, error) {
+	return v.VerifyAgeRangeContext(context.Background(), proofBytes)
+}
+
+// VerifyAgeRangeContext is VerifyAgeRange with a context (see VerifyAgeContext).
+func (v *Verifier) VerifyAgeRangeContext(ctx context.Context, proofBytes []byte) (bool, error) {
+	if v.ageRange.IsZero() {
+		return false, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "age range not configured")
+	}
+	assignment, err := rangeAssignment(v.config.CurrentDate(), v.ageRange, v.config.AgeMode)
+	if err != nil {
+		return false, err
+	}
+	if err := backend.VerifyContext(ctx, v.rangeVerifyingKey, proofBytes, assignment); err != nil {
+		return false, backend.VerifyError(err, "age")
+	}
+	return true, nil
+}
+
+// AgeRange returns the bounds enforced by VerifyAgeRange.
+func (v *Verifier) AgeRange() AgeRange {
+	return v.ageRange
+}
+
+// rangeAssignment builds the public circuit assignment for an age range.
+func rangeAssignment(currentDate int, bounds AgeRange, ageMode string) (*AgeRangeCircuit, error) {
+	mode, err := common.AgeModeCode(ageMode)
+	if err != nil {
+		return nil, err
+	}
+	maxAge := bounds.Max
+	if maxAge == 0 {
+		maxAge = NoMaxAge
+	}
+
+	var publicCurr big.Int
+	publicCurr.SetInt64(int64(currentDate))
+
+	return &AgeRangeCircuit{
+		CurrentDate: publicCurr,
+		MinAge:      bounds.Min,
+		MaxAge:      maxAge,
+		Mode:        mode,
+	}, nil
+}
+
+// RangeProvingKeyID returns the fingerprint of the embedded age range proving key.
+func RangeProvingKeyID() string {
+	return EmbeddedRangeProvingKeyID
+}
+
+// RangeVerifyingKeyID returns the fingerprint of the embedded age range verifying key.
+func RangeVerifyingKeyID() string {
+	return EmbeddedRangeVerifyingKeyID
+}
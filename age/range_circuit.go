@@ -0,0 +1,27 @@
+package age
+
+import "github.com/consensys/gnark/frontend"
+
+// NoMaxAge is the MaxAge assigned for an open upper bound: AgeAt never returns
+// 2^14 or more, so age < NoMaxAge always holds.
+const NoMaxAge = 1 << yearBits
+
+// AgeRangeCircuit enforces MinAge <= age(BirthDate, CurrentDate) < MaxAge
+// without revealing BirthDate. An open lower bound is MinAge 0 and an open
+// upper bound is MaxAge NoMaxAge.
+type AgeRangeCircuit struct {
+	CurrentDate frontend.Variable `gnark:",public"`
+	MinAge      frontend.Variable `gnark:",public"`
+	MaxAge      frontend.Variable `gnark:",public"`
+	Mode        frontend.Variable `gnark:",public"`
+
+	BirthDate frontend.Variable
+}
+
+// Define implements the gnark circuit definition.
+func (c *AgeRangeCircuit) Define(api frontend.API) error {
+	age := AgeAt(api, c.BirthDate, c.CurrentDate, c.Mode)
+	AssertAgeAtLeast(api, age, c.MinAge)
+	AssertAgeBelow(api, age, c.MaxAge)
+	return nil
+}
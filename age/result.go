@@ -0,0 +1,40 @@
+package age
+
+import (
+	"context"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+)
+
+// VerifyAgeResult is VerifyAgeContext reporting a common.VerificationResult:
+// the error code and stage of a rejected proof, or the verifier's vk_id,
+// params_version and proof_version for a valid one.
+func (v *Verifier) VerifyAgeResult(ctx context.Context, proofBytes []byte) common.VerificationResult {
+	start := time.Now()
+	_, err := v.VerifyAgeContext(ctx, proofBytes)
+	return common.NewVerificationResult(start, map[string]string{
+		common.ClaimVKID:          v.VerifyingKeyID(),
+		common.ClaimParamsVersion: common.ParamsVersion(v.config),
+		common.ClaimProofVersion:  backend.ProofVersion(ProofVersion, v.Backend()),
+	}, err)
+}
+
+// VerifyEnvelopeResult is VerifyEnvelopeContext reporting a
+// common.VerificationResult. A valid credential age envelope also reports its
+// issuer_key.
+func (v *Verifier) VerifyEnvelopeResult(ctx context.Context, env envelope.Envelope, challenge string) common.VerificationResult {
+	start := time.Now()
+	res, err := v.verifyEnvelope(ctx, env, challenge)
+	claims := map[string]string{
+		common.ClaimVKID:          res.VKID,
+		common.ClaimParamsVersion: res.ParamsVersion,
+		common.ClaimProofVersion:  res.ProofVersion,
+	}
+	if res.IssuerKey != "" {
+		claims[common.ClaimIssuerKey] = res.IssuerKey
+	}
+	return common.NewVerificationResult(start, claims, err)
+}
@@ -0,0 +1,35 @@
+package age
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+)
+
+// ExportSolidity writes a Solidity verifier contract for the (Groth16) age verifying key.
+func (v *Verifier) ExportSolidity(w io.Writer) error {
+	return backend.ExportSolidity(v.verifyingKey, w)
+}
+
+// SolidityCalldata converts an age proof into the calldata layout of the
+// contract written by ExportSolidity. The public inputs (current date, limit
+// age, age mode) come from the verifier's policy; credential age proofs are not supported.
+func (v *Verifier) SolidityCalldata(res ProofResult) (*backend.Calldata, error) {
+	if res.ProofVersion == CredentialProofVersion {
+		return nil, errors.New("credential age proofs have no solidity verifier")
+	}
+	assignment, err := v.ageAssignment()
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := backend.SolidityCalldata(v.verifyingKey, res.Proof, assignment)
+	if err != nil {
+		var berr *backend.Error
+		if errors.As(err, &berr) {
+			return nil, backend.VerifyError(err, "age")
+		}
+		return nil, err
+	}
+	return calldata, nil
+}
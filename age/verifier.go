@@ -1,36 +1,51 @@
 package age
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
-	"encoding/hex"
 	"fmt"
 	"math/big"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
-	"golang.org/x/crypto/blake2b"
 )
 
 //go:embed age.vk
 var ageVerifyingKeyData []byte
 
 // EmbeddedAgeVerifyingKeyID is the blake2b-256 fingerprint of the embedded age verifying key.
-var EmbeddedAgeVerifyingKeyID = blake2bAgeVerifierSumHex(ageVerifyingKeyData)
+var EmbeddedAgeVerifyingKeyID = backend.KeyID(ageVerifyingKeyData)
+
+//go:embed age_plonk.vk
+var agePlonkVerifyingKeyData []byte
+
+// EmbeddedAgePlonkVerifyingKeyID is the blake2b-256 fingerprint of the embedded PLONK age verifying key.
+var EmbeddedAgePlonkVerifyingKeyID = backend.KeyID(agePlonkVerifyingKeyData)
 
 // Verifier implements the AgeVerifier interface.
 type Verifier struct {
-	verifyingKey groth16.VerifyingKey
-	config       common.SharedConfig
+	verifyingKey           *backend.VerifyingKey
+	vkID                   string
+	credentialVerifyingKey *backend.VerifyingKey
+	credentialVKID         string
+	config                 common.SharedConfig
+	trustedIssuers         map[string]bool
+	rangeVerifyingKey      *backend.VerifyingKey
+	rangeVKID              string
+	ageRange               AgeRange
 }
 
 // VerifierConfig holds configuration for the age verifier.
 type VerifierConfig struct {
-	Config     common.SharedConfig
-	ExpectedVK string // optional: expected verifying key fingerprint
+	Config         common.SharedConfig
+	ExpectedVK     string   // optional: expected verifying key fingerprint
+	TrustedIssuers []string // optional: hex issuer public keys accepted for credential age proofs
+	Backend        string   // optional: backend.Groth16 (default) or backend.PLONK for age proofs
+	AgeRange       AgeRange // optional: bounds enforced by VerifyAgeRange
+	// Production refuses PLONK age keys built from the shipped test SRS
+	// (backend.TestSRSID), including the embedded ones, with E4003.
+	Production bool
 }
 
 // NewVerifier creates an age verifier with default config.
@@ -40,50 +55,108 @@ func NewVerifier() (*Verifier, error) {
 
 // NewVerifierWithConfig creates an age verifier with custom config.
 func NewVerifierWithConfig(cfg VerifierConfig) (*Verifier, error) {
-	vk := groth16.NewVerifyingKey(ecc.BN254)
-	if len(ageVerifyingKeyData) == 0 {
-		return nil, fmt.Errorf("embedded age verifying key is empty (run setup)")
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return nil, err
+	}
+	vkData := ageVerifyingKeyData
+	if backendName == backend.PLONK {
+		vkData = agePlonkVerifyingKeyData
+	}
+	return newVerifier(vkData, credentialVerifyingKeyData, rangeVerifyingKeyData, cfg)
+}
+
+// newVerifier creates an age verifier from age, credential age and age range
+// verifying key bytes.
+func newVerifier(vkData []byte, credentialVKData []byte, rangeVKData []byte, cfg VerifierConfig) (*Verifier, error) {
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return nil, err
+	}
+	if len(vkData) == 0 {
+		return nil, sdkerrors.New(sdkerrors.ErrKeyNotFound.Code, "age verifying key is empty (run setup)")
+	}
+	vk, err := backend.LoadVerifyingKey(backendName, vkData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "age verifying key parse failed", err)
+	}
+	vkID := backend.KeyID(vkData)
+	if cfg.ExpectedVK != "" && cfg.ExpectedVK != vkID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "age verifying key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedVK, vkID))
 	}
-	if _, err := vk.ReadFrom(bytes.NewReader(ageVerifyingKeyData)); err != nil {
-		return nil, fmt.Errorf("age verifying key parse failed: %w", err)
+	if cfg.Production {
+		if err := backend.CheckProductionKey(vk); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidConfig.Code, "age verifying key not fit for production", err)
+		}
 	}
-	if cfg.ExpectedVK != "" && cfg.ExpectedVK != EmbeddedAgeVerifyingKeyID {
-		return nil, fmt.Errorf("age verifying key fingerprint mismatch: expected %s got %s", cfg.ExpectedVK, EmbeddedAgeVerifyingKeyID)
+
+	credentialVK, err := backend.LoadVerifyingKey(backend.Groth16, credentialVKData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "credential age verifying key parse failed", err)
+	}
+	if err := cfg.AgeRange.Validate(); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidConfig.Code, "age range invalid", err)
+	}
+	rangeVK, err := backend.LoadVerifyingKey(backend.Groth16, rangeVKData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "age range verifying key parse failed", err)
+	}
+	trusted := make(map[string]bool, len(cfg.TrustedIssuers))
+	for _, key := range cfg.TrustedIssuers {
+		if _, err := parseIssuerKey(key); err != nil {
+			return nil, err
+		}
+		trusted[key] = true
 	}
 
 	return &Verifier{
-		verifyingKey: vk,
-		config:       pickAgeSharedConfig(cfg.Config),
+		verifyingKey:           vk,
+		vkID:                   vkID,
+		credentialVerifyingKey: credentialVK,
+		credentialVKID:         backend.KeyID(credentialVKData),
+		config:                 pickAgeSharedConfig(cfg.Config),
+		trustedIssuers:         trusted,
+		rangeVerifyingKey:      rangeVK,
+		rangeVKID:              backend.KeyID(rangeVKData),
+		ageRange:               cfg.AgeRange,
 	}, nil
 }
 
 // VerifyAge validates a proof asserting adulthood.
 func (v *Verifier) VerifyAge(proofBytes []byte) (bool, error) {
-	proof := groth16.NewProof(ecc.BN254)
-	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
-		return false, fmt.Errorf("proof format error: %w", err)
-	}
-
-	var publicCurr big.Int
-	publicCurr.SetInt64(int64(v.config.TargetYear))
-	var publicLimit big.Int
-	publicLimit.SetInt64(int64(v.config.LimitAge))
+	return v.VerifyAgeContext(context.Background(), proofBytes)
+}
 
-	assignment := AgeCircuit{
-		CurrentYear: publicCurr,
-		LimitAge:    publicLimit,
+// VerifyAgeContext is VerifyAge that gives up once ctx is canceled or its
+// deadline passes, returning an E1019 error.
+func (v *Verifier) VerifyAgeContext(ctx context.Context, proofBytes []byte) (bool, error) {
+	assignment, err := v.ageAssignment()
+	if err != nil {
+		return false, err
+	}
+	if err := backend.VerifyContext(ctx, v.verifyingKey, proofBytes, assignment); err != nil {
+		return false, backend.VerifyError(err, "age")
 	}
+	return true, nil
+}
 
-	publicWitness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+// ageAssignment builds the public circuit assignment for the configured policy.
+func (v *Verifier) ageAssignment() (*AgeCircuit, error) {
+	mode, err := common.AgeModeCode(v.config.AgeMode)
 	if err != nil {
-		return false, fmt.Errorf("public witness creation failed: %w", err)
+		return nil, err
 	}
 
-	if err := groth16.Verify(proof, v.verifyingKey, publicWitness); err != nil {
-		return false, fmt.Errorf("age verification failed: %w", err)
-	}
+	var publicCurr big.Int
+	publicCurr.SetInt64(int64(v.config.CurrentDate()))
+	var publicLimit big.Int
+	publicLimit.SetInt64(int64(v.config.LimitAge))
 
-	return true, nil
+	return &AgeCircuit{
+		CurrentDate: publicCurr,
+		LimitAge:    publicLimit,
+		Mode:        mode,
+	}, nil
 }
 
 // GetConfig returns the shared configuration.
@@ -91,13 +164,39 @@ func (v *Verifier) GetConfig() common.SharedConfig {
 	return v.config
 }
 
+// AgeMode returns the age counting mode enforced by the verifier.
+func (v *Verifier) AgeMode() string {
+	return ageModeOrDefault(v.config.AgeMode)
+}
+
 // PolicyBundle returns the shared config with metadata for client sync.
 func (v *Verifier) PolicyBundle() PolicyBundle {
-	return PolicyBundle{
-		Config:        v.config,
-		ParamsVersion: common.ParamsVersion(v.config),
-		VKID:          AgeVerifyingKeyID(),
+	bundle := PolicyBundle{
+		Config:         v.config,
+		ParamsVersion:  common.ParamsVersion(v.config),
+		VKID:           v.VerifyingKeyID(),
+		AgeMode:        v.AgeMode(),
+		Backend:        v.verifyingKey.Backend,
+		ProofVersion:   backend.ProofVersion(ProofVersion, v.verifyingKey.Backend),
+		CredentialVKID: v.credentialVKID,
+		TrustedIssuers: v.TrustedIssuers(),
+	}
+	if !v.ageRange.IsZero() {
+		bounds := v.ageRange
+		bundle.AgeRange = &bounds
+		bundle.RangeVKID = v.rangeVKID
 	}
+	return bundle
+}
+
+// Backend returns the proving backend accepted for age proofs.
+func (v *Verifier) Backend() string {
+	return v.verifyingKey.Backend
+}
+
+// VerifyingKeyID returns the fingerprint of the age verifying key this verifier was built from.
+func (v *Verifier) VerifyingKeyID() string {
+	return v.vkID
 }
 
 // AgeVerifyingKeyID returns the fingerprint of the embedded age verifying key.
@@ -107,20 +206,29 @@ func AgeVerifyingKeyID() string {
 
 // VerifyAgeWithMeta verifies proof and enforces vk_id/params_version metadata match.
 func (v *Verifier) VerifyAgeWithMeta(proofBytes []byte, vkID string, paramsVersion string) (bool, error) {
-	if vkID != "" && vkID != AgeVerifyingKeyID() {
+	return v.VerifyAgeWithMetaContext(context.Background(), proofBytes, vkID, paramsVersion)
+}
+
+// VerifyAgeWithMetaContext is VerifyAgeWithMeta with a context.
+func (v *Verifier) VerifyAgeWithMetaContext(ctx context.Context, proofBytes []byte, vkID string, paramsVersion string) (bool, error) {
+	if vkID != "" && vkID != v.VerifyingKeyID() {
 		return false, sdkerrors.ErrKeyMismatch
 	}
 	expectedParams := common.ParamsVersion(v.config)
 	if paramsVersion != "" && paramsVersion != expectedParams {
 		return false, sdkerrors.ErrPolicyMismatch
 	}
-	return v.VerifyAge(proofBytes)
+	return v.VerifyAgeContext(ctx, proofBytes)
 }
 
 func pickAgeSharedConfig(cfg common.SharedConfig) common.SharedConfig {
 	def := common.DefaultSharedConfig()
-	if cfg.TargetYear == 0 {
+	if cfg.TargetYear == 0 && cfg.TargetDate == 0 && !cfg.DynamicDate {
 		cfg.TargetYear = def.TargetYear
+		cfg.DynamicDate = def.DynamicDate
+	}
+	if cfg.TargetYear == 0 {
+		cfg.TargetYear = cfg.TargetDate / 10000
 	}
 	if cfg.LimitAge == 0 {
 		cfg.LimitAge = def.LimitAge
@@ -128,10 +236,9 @@ func pickAgeSharedConfig(cfg common.SharedConfig) common.SharedConfig {
 	return cfg
 }
 
-func blake2bAgeVerifierSumHex(data []byte) string {
-	if len(data) == 0 {
-		return ""
+func ageModeOrDefault(mode string) string {
+	if mode == "" {
+		return common.AgeModeInternational
 	}
-	sum := blake2b.Sum256(data)
-	return hex.EncodeToString(sum[:])
+	return mode
 }
@@ -0,0 +1,102 @@
+package attribute
+
+import (
+	"math/big"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/selector"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// PredicateSlot is one public predicate of PredicateCircuit. Op 0 is an
+// unused slot; 1 is equality with Value, 2 membership in Set and 3 the range
+// Min <= attribute < Max over integers below 2^64.
+type PredicateSlot struct {
+	Index frontend.Variable
+	Op    frontend.Variable
+	Value frontend.Variable
+	Set   [MaxSetSize]frontend.Variable
+	Min   frontend.Variable
+	Max   frontend.Variable
+}
+
+// PredicateCircuit proves that attributes signed by IssuerKey under SchemaID
+// satisfy every predicate. Attribute values stay private. The signature also
+// covers MiMC(HolderSecret), so only the holder can prove with a credential,
+// and the verifier-issued Challenge ties each proof to one presentation.
+type PredicateCircuit struct {
+	SchemaID   frontend.Variable            `gnark:",public"`
+	IssuerKey  eddsa.PublicKey              `gnark:",public"`
+	Predicates [MaxPredicates]PredicateSlot `gnark:",public"`
+	Challenge  frontend.Variable            `gnark:",public"`
+
+	Attributes   [MaxAttributes]frontend.Variable
+	HolderSecret frontend.Variable
+	Signature    eddsa.Signature
+}
+
+// Define implements the gnark circuit definition.
+func (c *PredicateCircuit) Define(api frontend.API) error {
+	holderHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	holderHash.Write(c.HolderSecret)
+
+	// Signature over MiMC(schemaID, holder, attributes)
+	msgHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	msgHash.Write(c.SchemaID, holderHash.Sum())
+	msgHash.Write(c.Attributes[:]...)
+
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	sigHash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	if err := eddsa.Verify(curve, c.Signature, msgHash.Sum(), c.IssuerKey, &sigHash); err != nil {
+		return err
+	}
+	// The challenge must be non-zero; the check also constrains it, so a
+	// proof does not verify for any other challenge.
+	api.AssertIsDifferent(c.Challenge, 0)
+
+	for _, p := range c.Predicates {
+		value := selector.Mux(api, p.Index, c.Attributes[:]...)
+
+		isEqual := api.IsZero(api.Sub(value, p.Value))
+
+		product := frontend.Variable(1)
+		for _, e := range p.Set {
+			product = api.Mul(product, api.Sub(value, e))
+		}
+		isIn := api.IsZero(product)
+
+		// Ranges compare integers below 2^64; other slots compare 0 so that
+		// string-encoded attributes never reach the bounded comparator.
+		useRange := api.IsZero(api.Sub(p.Op, opRange))
+		ranged := api.Select(useRange, value, 0)
+		bits.ToBinary(api, ranged, bits.WithNbDigits(rangeBits))
+		ints := cmp.NewBoundedComparator(api, new(big.Int).Lsh(big.NewInt(1), rangeBits+1), false)
+		inRange := api.Mul(ints.IsLess(ranged, p.Max), api.Sub(1, ints.IsLess(ranged, p.Min)))
+
+		useNone := api.IsZero(api.Sub(p.Op, opNone))
+		useEqual := api.IsZero(api.Sub(p.Op, opEqual))
+		useIn := api.IsZero(api.Sub(p.Op, opIn))
+		api.AssertIsEqual(api.Add(useNone, useEqual, useIn, useRange), 1)
+
+		holds := api.Add(useNone, api.Mul(useEqual, isEqual), api.Mul(useIn, isIn), api.Mul(useRange, inRange))
+		api.AssertIsEqual(holds, 1)
+	}
+	return nil
+}
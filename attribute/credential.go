@@ -0,0 +1,243 @@
+// Package attribute issues signed attribute credentials and proves predicates
+// (equality, set membership, range) over chosen attributes without revealing
+// the others. It generalizes the birth-date credentials of package age.
+package attribute
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// MaxAttributes is the number of attribute slots a credential signs.
+const MaxAttributes = 8
+
+// valueDomain separates string attribute values from other hashed strings.
+const valueDomain = "idz-attr"
+
+// Schema names the attributes of a credential in slot order, e.g.
+// ["region", "tier"]. Its ID is signed with the attributes, so a credential
+// cannot be read under another schema.
+type Schema []string
+
+// Validate checks that the schema has 1 to MaxAttributes unique, non-empty names.
+func (s Schema) Validate() error {
+	if len(s) == 0 || len(s) > MaxAttributes {
+		return fmt.Errorf("schema must name 1 to %d attributes, got %d", MaxAttributes, len(s))
+	}
+	seen := make(map[string]bool, len(s))
+	for _, name := range s {
+		if name == "" || strings.ContainsRune(name, 0) || seen[name] {
+			return fmt.Errorf("schema attribute name %q invalid or repeated", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Index returns the slot of the attribute name, or -1.
+func (s Schema) Index(name string) int {
+	for i, n := range s {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ID returns the schema identifier as a decimal field element.
+func (s Schema) ID() string {
+	h := sha256.New()
+	h.Write([]byte(valueDomain + "-schema"))
+	for _, name := range s {
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+	}
+	return hashToField(h.Sum(nil)).String()
+}
+
+// IntValue encodes an integer attribute (tier, region code, date). Range
+// predicates compare encoded values as integers.
+func IntValue(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+// StringValue encodes a string attribute as a field element. Such values
+// support equality and set membership but not meaningful ranges.
+func StringValue(s string) string {
+	sum := sha256.Sum256([]byte(valueDomain + "\x00" + s))
+	return hashToField(sum[:]).String()
+}
+
+// Credential is an issuer-signed attribute vector held by the user. The
+// signature covers the holder commitment too, so proving with the credential
+// needs the holder secret behind it.
+type Credential struct {
+	Schema     Schema   `json:"schema"`
+	Attributes []string `json:"attributes"` // decimal field elements in schema order
+	Holder     string   `json:"holder"`     // decimal holder commitment (commitment.HolderCommitment)
+	IssuerKey  string   `json:"issuer_key"` // hex, compressed BabyJubJub public key
+	Signature  string   `json:"signature"`  // hex, EdDSA (MiMC) signature over H(schema ID, holder, attributes)
+}
+
+// Issuer signs attribute credentials with an EdDSA key over BabyJubJub.
+type Issuer struct {
+	key *eddsa.PrivateKey
+}
+
+// GenerateIssuer creates an issuer with a fresh random key.
+func GenerateIssuer() (*Issuer, error) {
+	key, err := eddsa.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSetupFailed.Code, "issuer key generation failed", err)
+	}
+	return &Issuer{key: key}, nil
+}
+
+// NewIssuerFromBytes restores an issuer from a serialized private key (see Issuer.Bytes).
+// Keys of age.Issuer are accepted as well.
+func NewIssuerFromBytes(keyBytes []byte) (*Issuer, error) {
+	var key eddsa.PrivateKey
+	if _, err := key.SetBytes(keyBytes); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "issuer key parse failed", err)
+	}
+	return &Issuer{key: &key}, nil
+}
+
+// Bytes returns the serialized private key. Store it in a KMS; it is not a public value.
+func (i *Issuer) Bytes() []byte {
+	return i.key.Bytes()
+}
+
+// PublicKey returns the issuer public key as hex, as listed in VerifierConfig.TrustedIssuers.
+func (i *Issuer) PublicKey() string {
+	return hex.EncodeToString(i.key.PublicKey.Bytes())
+}
+
+// Issue signs attributes (see IntValue and StringValue) in schema order for
+// the holder whose holder commitment (see commitment.HolderCommitment) is
+// holder, and returns the credential.
+func (i *Issuer) Issue(schema Schema, attributes []string, holder string) (Credential, error) {
+	msg, err := credentialMessage(schema, attributes, holder)
+	if err != nil {
+		return Credential{}, err
+	}
+	sig, err := i.key.Sign(msg, mimc.NewMiMC())
+	if err != nil {
+		return Credential{}, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "credential signing failed", err)
+	}
+	return Credential{
+		Schema:     append(Schema(nil), schema...),
+		Attributes: append([]string(nil), attributes...),
+		Holder:     holder,
+		IssuerKey:  i.PublicKey(),
+		Signature:  hex.EncodeToString(sig),
+	}, nil
+}
+
+// VerifyCredential checks the credential signature natively, outside the circuit.
+func VerifyCredential(cred Credential) error {
+	pub, err := parseIssuerKey(cred.IssuerKey)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(cred.Signature)
+	if err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "signature decode failed", err)
+	}
+	msg, err := credentialMessage(cred.Schema, cred.Attributes, cred.Holder)
+	if err != nil {
+		return err
+	}
+	ok, err := pub.Verify(sig, msg, mimc.NewMiMC())
+	if err != nil || !ok {
+		return sdkerrors.ErrCredentialInvalid
+	}
+	return nil
+}
+
+// credentialMessage is the signed message: MiMC(schema ID, holder, attributes
+// padded with zeros to MaxAttributes), matching the circuit.
+func credentialMessage(schema Schema, attributes []string, holder string) ([]byte, error) {
+	values, err := attributeValues(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+	holderElem, err := commitment.ParseHolderCommitment(holder)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "holder commitment invalid", err)
+	}
+	h := mimc.NewMiMC()
+	var id fr.Element
+	id.SetString(schema.ID())
+	idBytes := id.Bytes()
+	h.Write(idBytes[:])
+	holderBytes := holderElem.Bytes()
+	h.Write(holderBytes[:])
+	for _, v := range values {
+		var e fr.Element
+		e.SetBigInt(v)
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+	return h.Sum(nil), nil
+}
+
+// attributeValues parses attributes against schema and pads them to MaxAttributes.
+func attributeValues(schema Schema, attributes []string) ([MaxAttributes]*big.Int, error) {
+	var out [MaxAttributes]*big.Int
+	if err := schema.Validate(); err != nil {
+		return out, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "schema invalid", err)
+	}
+	if len(attributes) != len(schema) {
+		return out, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "attribute count mismatch", fmt.Errorf("schema has %d attributes, got %d", len(schema), len(attributes)))
+	}
+	for i := range out {
+		out[i] = new(big.Int)
+		if i >= len(attributes) {
+			continue
+		}
+		v, err := parseValue(attributes[i])
+		if err != nil {
+			return out, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, fmt.Sprintf("attribute %s invalid", schema[i]), err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// parseValue parses a decimal field element.
+func parseValue(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.String() != s || v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+		return nil, fmt.Errorf("value %q is not a decimal field element", s)
+	}
+	return v, nil
+}
+
+func parseIssuerKey(keyHex string) (*eddsa.PublicKey, error) {
+	raw, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "issuer key decode failed", err)
+	}
+	var pub eddsa.PublicKey
+	if _, err := pub.SetBytes(raw); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, fmt.Sprintf("issuer key parse failed: %s", keyHex), err)
+	}
+	return &pub, nil
+}
+
+func hashToField(sum []byte) *big.Int {
+	v := new(big.Int).SetBytes(sum)
+	return v.Mod(v, fr.Modulus())
+}
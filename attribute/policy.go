@@ -0,0 +1,210 @@
+package attribute
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Limits of a predicate policy, fixed by the circuit.
+const (
+	MaxPredicates = 4
+	MaxSetSize    = 8
+
+	// rangeBits bounds the integers range predicates compare.
+	rangeBits = 64
+)
+
+// Predicate operators.
+const (
+	// OpEqual holds when the attribute equals Values[0].
+	OpEqual = "eq"
+	// OpIn holds when the attribute is one of Values.
+	OpIn = "in"
+	// OpRange holds when Min <= attribute < Max; an empty bound is open.
+	// Ranges apply to integer attributes (IntValue), which are below 2^64.
+	OpRange = "range"
+)
+
+// Operator codes assigned in PredicateCircuit (0 is an unused slot).
+const (
+	opNone = iota
+	opEqual
+	opIn
+	opRange
+)
+
+// Predicate is a condition on one attribute, in the JSON form servers publish:
+//
+//	{"attribute": "region", "op": "in", "values": ["11", "26"]}
+//	{"attribute": "tier", "op": "range", "min": "3"}
+type Predicate struct {
+	Attribute string   `json:"attribute"`
+	Op        string   `json:"op"`
+	Values    []string `json:"values,omitempty"` // eq: one value; in: 1 to MaxSetSize values
+	Min       string   `json:"min,omitempty"`    // range: inclusive lower bound
+	Max       string   `json:"max,omitempty"`    // range: exclusive upper bound
+}
+
+// Policy is the set of predicates a verifier enforces over credentials of a
+// schema. All predicates must hold; attributes without a predicate stay hidden.
+type Policy struct {
+	Schema     Schema      `json:"schema"`
+	Predicates []Predicate `json:"predicates"`
+}
+
+// ParsePolicy decodes a JSON policy, rejecting unknown fields, and validates it.
+func ParsePolicy(data []byte) (Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return Policy{}, fmt.Errorf("attribute policy parse failed: %w", err)
+	}
+	if dec.More() {
+		return Policy{}, fmt.Errorf("attribute policy parse failed: trailing data")
+	}
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+// Validate checks the schema and that every predicate names a schema
+// attribute with well-formed values for its operator.
+func (p Policy) Validate() error {
+	_, err := p.compile()
+	return err
+}
+
+// Evaluate reports whether credential attributes (in schema order) satisfy every predicate.
+func (p Policy) Evaluate(attributes []string) (bool, error) {
+	slots, err := p.compile()
+	if err != nil {
+		return false, err
+	}
+	values, err := attributeValues(p.Schema, attributes)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range slots {
+		if !s.holds(values[s.index]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// predicateSlot is a predicate as assigned to the circuit.
+type predicateSlot struct {
+	index    int
+	op       int
+	value    *big.Int // eq value
+	set      [MaxSetSize]*big.Int
+	min, max *big.Int
+}
+
+func (s predicateSlot) holds(v *big.Int) bool {
+	switch s.op {
+	case opEqual:
+		return v.Cmp(s.value) == 0
+	case opIn:
+		for _, e := range s.set {
+			if v.Cmp(e) == 0 {
+				return true
+			}
+		}
+		return false
+	case opRange:
+		return v.Cmp(s.min) >= 0 && v.Cmp(s.max) < 0
+	}
+	return true
+}
+
+// unusedSlot is an always-true slot with all values zero.
+func unusedSlot() predicateSlot {
+	zero := new(big.Int)
+	s := predicateSlot{op: opNone, value: zero, min: zero, max: zero}
+	for i := range s.set {
+		s.set[i] = zero
+	}
+	return s
+}
+
+// compile validates the policy and converts it to circuit slots.
+func (p Policy) compile() ([]predicateSlot, error) {
+	if err := p.Schema.Validate(); err != nil {
+		return nil, fmt.Errorf("attribute policy schema invalid: %w", err)
+	}
+	if len(p.Predicates) == 0 || len(p.Predicates) > MaxPredicates {
+		return nil, fmt.Errorf("attribute policy must have 1 to %d predicates, got %d", MaxPredicates, len(p.Predicates))
+	}
+	slots := make([]predicateSlot, len(p.Predicates))
+	for i, pred := range p.Predicates {
+		s, err := pred.compile(p.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("attribute predicate %d: %w", i, err)
+		}
+		slots[i] = s
+	}
+	return slots, nil
+}
+
+func (pred Predicate) compile(schema Schema) (predicateSlot, error) {
+	s := unusedSlot()
+	if s.index = schema.Index(pred.Attribute); s.index < 0 {
+		return s, fmt.Errorf("attribute %q not in schema", pred.Attribute)
+	}
+
+	var err error
+	switch pred.Op {
+	case OpEqual:
+		if len(pred.Values) != 1 || pred.Min != "" || pred.Max != "" {
+			return s, fmt.Errorf("eq takes exactly one value")
+		}
+		s.op = opEqual
+		s.value, err = parseValue(pred.Values[0])
+	case OpIn:
+		if len(pred.Values) == 0 || len(pred.Values) > MaxSetSize || pred.Min != "" || pred.Max != "" {
+			return s, fmt.Errorf("in takes 1 to %d values", MaxSetSize)
+		}
+		s.op = opIn
+		for i := range s.set {
+			// Unused entries repeat the last value, which keeps the set unchanged.
+			if s.set[i], err = parseValue(pred.Values[min(i, len(pred.Values)-1)]); err != nil {
+				break
+			}
+		}
+	case OpRange:
+		if len(pred.Values) != 0 || (pred.Min == "" && pred.Max == "") {
+			return s, fmt.Errorf("range takes min and/or max and no values")
+		}
+		s.op = opRange
+		if pred.Min != "" {
+			if s.min, err = parseValue(pred.Min); err != nil {
+				break
+			}
+			if s.min.BitLen() > rangeBits {
+				return s, fmt.Errorf("range min %s exceeds 64 bits", pred.Min)
+			}
+		}
+		// An open upper bound is 2^64, which no integer attribute reaches.
+		limit := new(big.Int).Lsh(big.NewInt(1), rangeBits)
+		s.max = limit
+		if pred.Max != "" {
+			if s.max, err = parseValue(pred.Max); err != nil {
+				break
+			}
+			if s.max.Cmp(limit) > 0 {
+				return s, fmt.Errorf("range max %s exceeds 2^64", pred.Max)
+			}
+			if s.max.Cmp(s.min) <= 0 {
+				return s, fmt.Errorf("range [%s, %s) is empty", pred.Min, pred.Max)
+			}
+		}
+	default:
+		return s, fmt.Errorf("unknown op %q", pred.Op)
+	}
+	return s, err
+}
@@ -0,0 +1,137 @@
+package attribute
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// CircuitName names the predicate circuit in the key manifest.
+const CircuitName = "attribute"
+
+// ProofVersion is the semantic version for attribute predicate proofs.
+const ProofVersion = "attribute-proof-v1"
+
+//go:embed attribute.pk
+var provingKeyData []byte
+
+//go:embed attribute.ccs
+var ccsData []byte
+
+// EmbeddedProvingKeyID is the blake2b-256 fingerprint of the embedded attribute proving key.
+var EmbeddedProvingKeyID = backend.KeyID(provingKeyData)
+
+// Prover generates attribute predicate proofs. Predicate proofs use Groth16.
+type Prover struct {
+	provingKey *backend.ProvingKey
+	ccs        constraint.ConstraintSystem
+}
+
+// NewProver creates an attribute prover from the embedded proving key.
+func NewProver() (*Prover, error) {
+	ccs, err := backend.LoadConstraintSystem(backend.Groth16, ccsData, &PredicateCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("attribute circuit load failed: %w", err)
+	}
+	if len(provingKeyData) == 0 {
+		return nil, fmt.Errorf("embedded attribute proving key is empty (run setup)")
+	}
+	pk, err := backend.LoadProvingKey(backend.Groth16, provingKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("attribute proving key parse failed: %w", err)
+	}
+	return &Prover{provingKey: pk, ccs: ccs}, nil
+}
+
+// GeneratePredicateProof proves that cred satisfies every predicate of policy,
+// typically the policy published by the verifier. holderSecret must be the
+// secret behind cred.Holder, and challenge is the verifier-issued value the
+// proof is bound to. Only the predicates, the schema, the issuer key and the
+// challenge are public.
+func (p *Prover) GeneratePredicateProof(cred Credential, holderSecret string, challenge string, policy Policy) ([]byte, error) {
+	if err := VerifyCredential(cred); err != nil {
+		return nil, err
+	}
+	secretInt, err := commitment.ParseChallenge(holderSecret)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCredentialInvalid.Code, "holder secret invalid", err)
+	}
+	if holder, err := commitment.HolderCommitment(secretInt.String()); err != nil || holder != cred.Holder {
+		return nil, sdkerrors.New(sdkerrors.ErrCredentialInvalid.Code, "holder secret does not match credential")
+	}
+	if !slices.Equal(cred.Schema, policy.Schema) {
+		return nil, sdkerrors.New(sdkerrors.ErrPolicyMismatch.Code, "credential schema differs from policy schema")
+	}
+	ok, err := policy.Evaluate(cred.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sdkerrors.New(sdkerrors.ErrCredentialInvalid.Code, "credential does not satisfy the policy")
+	}
+
+	assignment, err := publicAssignment(policy, cred.IssuerKey, challenge)
+	if err != nil {
+		return nil, err
+	}
+	assignment.HolderSecret = secretInt
+	values, err := attributeValues(cred.Schema, cred.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		assignment.Attributes[i] = v
+	}
+	sig, _ := hex.DecodeString(cred.Signature)
+	assignment.Signature.Assign(tedwards.BN254, sig)
+
+	proof, err := backend.Prove(p.ccs, p.provingKey, assignment)
+	if err != nil {
+		return nil, fmt.Errorf("attribute %w", err)
+	}
+	return proof, nil
+}
+
+// ProvingKeyID returns the fingerprint of the embedded attribute proving key.
+func (p *Prover) ProvingKeyID() string {
+	return EmbeddedProvingKeyID
+}
+
+// publicAssignment builds the public part of the circuit assignment for a
+// policy, issuer key and challenge.
+func publicAssignment(policy Policy, issuerKey string, challenge string) (*PredicateCircuit, error) {
+	slots, err := policy.compile()
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidConfig.Code, "attribute policy invalid", err)
+	}
+	pub, err := parseIssuerKey(issuerKey)
+	if err != nil {
+		return nil, err
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+
+	assignment := &PredicateCircuit{SchemaID: policy.Schema.ID(), Challenge: challengeInt}
+	assignment.IssuerKey.Assign(tedwards.BN254, pub.Bytes())
+	for i := range assignment.Predicates {
+		s := unusedSlot()
+		if i < len(slots) {
+			s = slots[i]
+		}
+		a := &assignment.Predicates[i]
+		a.Index, a.Op, a.Value, a.Min, a.Max = s.index, s.op, s.value, s.min, s.max
+		for j := range a.Set {
+			a.Set[j] = s.set[j]
+		}
+	}
+	return assignment, nil
+}
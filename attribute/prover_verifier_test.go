@@ -0,0 +1,156 @@
+package attribute
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+var testSchema = Schema{"region", "tier", "nationality"}
+
+const testChallenge = "123456789012345678901234567890"
+
+func testCredential(t *testing.T) (*Issuer, Credential, string) {
+	t.Helper()
+	issuer, err := GenerateIssuer()
+	if err != nil {
+		t.Fatalf("issuer init failed: %v", err)
+	}
+	holderSecret, err := commitment.NewHolderSecret()
+	if err != nil {
+		t.Fatalf("holder secret failed: %v", err)
+	}
+	holder, err := commitment.HolderCommitment(holderSecret)
+	if err != nil {
+		t.Fatalf("holder commitment failed: %v", err)
+	}
+	cred, err := issuer.Issue(testSchema, []string{IntValue(11), IntValue(3), StringValue("KR")}, holder)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	return issuer, cred, holderSecret
+}
+
+func TestPredicateProof(t *testing.T) {
+	issuer, cred, holderSecret := testCredential(t)
+	policy, err := ParsePolicy([]byte(`{
+		"schema": ["region", "tier", "nationality"],
+		"predicates": [
+			{"attribute": "region", "op": "in", "values": ["11", "26", "27"]},
+			{"attribute": "tier", "op": "range", "min": "3"},
+			{"attribute": "nationality", "op": "eq", "values": ["` + StringValue("KR") + `"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("policy parse failed: %v", err)
+	}
+
+	prover, err := NewProver()
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifier(VerifierConfig{Policy: policy, TrustedIssuers: []string{issuer.PublicKey()}})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	proof, err := prover.GeneratePredicateProof(cred, holderSecret, testChallenge, policy)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyPredicates(proof, cred.IssuerKey, testChallenge); err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+
+	// A proof does not verify for another challenge, and the credential
+	// cannot be presented without its holder secret.
+	if _, err := verifier.VerifyPredicates(proof, cred.IssuerKey, "123456789012345678901234567891"); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+		t.Fatalf("expected E1003 for a replayed proof, got %v", err)
+	}
+	if _, err := verifier.VerifyPredicates(proof, cred.IssuerKey, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected E1012 for a missing challenge, got %v", err)
+	}
+	otherSecret, _ := commitment.NewHolderSecret()
+	if _, err := prover.GeneratePredicateProof(cred, otherSecret, testChallenge, policy); sdkerrors.CodeOf(err) != sdkerrors.ErrCredentialInvalid.Code {
+		t.Fatalf("expected E1014 for a foreign holder secret, got %v", err)
+	}
+
+	// A proof for a weaker policy does not satisfy the verifier's policy.
+	weaker := Policy{Schema: testSchema, Predicates: []Predicate{{Attribute: "tier", Op: OpRange, Min: "1"}}}
+	weakProof, err := prover.GeneratePredicateProof(cred, holderSecret, testChallenge, weaker)
+	if err != nil {
+		t.Fatalf("weaker proof generation failed: %v", err)
+	}
+	if _, err := verifier.VerifyPredicates(weakProof, cred.IssuerKey, testChallenge); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+		t.Fatalf("expected E1003 for a weaker policy, got %v", err)
+	}
+
+	// Predicates the credential does not satisfy are refused by the prover.
+	gold := Policy{Schema: testSchema, Predicates: []Predicate{{Attribute: "tier", Op: OpRange, Min: "4", Max: "10"}}}
+	if _, err := prover.GeneratePredicateProof(cred, holderSecret, testChallenge, gold); sdkerrors.CodeOf(err) != sdkerrors.ErrCredentialInvalid.Code {
+		t.Fatalf("expected E1014 for an unsatisfied policy, got %v", err)
+	}
+
+	other, _ := GenerateIssuer()
+	if _, err := verifier.VerifyPredicates(proof, other.PublicKey(), testChallenge); err != sdkerrors.ErrIssuerUntrusted {
+		t.Fatalf("expected untrusted issuer error, got %v", err)
+	}
+}
+
+func TestCredentialTamper(t *testing.T) {
+	_, cred, _ := testCredential(t)
+	if err := VerifyCredential(cred); err != nil {
+		t.Fatalf("credential verification failed: %v", err)
+	}
+	tampered := cred
+	tampered.Attributes = []string{IntValue(11), IntValue(9), StringValue("KR")}
+	if err := VerifyCredential(tampered); err == nil {
+		t.Fatal("expected tampered attributes to be rejected")
+	}
+	renamed := cred
+	renamed.Schema = Schema{"region", "level", "nationality"}
+	if err := VerifyCredential(renamed); err == nil {
+		t.Fatal("expected a credential read under another schema to be rejected")
+	}
+	rebound := cred
+	rebound.Holder = "1"
+	if err := VerifyCredential(rebound); err == nil {
+		t.Fatal("expected a credential moved to another holder to be rejected")
+	}
+}
+
+func TestParsePolicyRejects(t *testing.T) {
+	for _, input := range []string{
+		`{"schema": ["a"], "predicates": []}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "b", "op": "eq", "values": ["1"]}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "eq", "values": ["1", "2"]}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "gt", "values": ["1"]}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "range", "min": "5", "max": "5"}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "range", "max": "18446744073709551617"}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "in", "values": ["1","2","3","4","5","6","7","8","9"]}]}`,
+		`{"schema": ["a", "a"], "predicates": [{"attribute": "a", "op": "eq", "values": ["1"]}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "eq", "values": ["01"]}]}`,
+		`{"schema": ["a"], "predicates": [{"attribute": "a", "op": "eq", "values": ["1"]}], "extra": true}`,
+	} {
+		if _, err := ParsePolicy([]byte(input)); err == nil {
+			t.Fatalf("expected policy to be rejected: %s", input)
+		}
+	}
+}
+
+// TestEmbeddedConstraintSystem fails when the circuit changed without
+// regenerating attribute.ccs (go run ./cmd/setup -ccs-only -circuits attribute).
+func TestEmbeddedConstraintSystem(t *testing.T) {
+	ccs, err := backend.Compile(backend.Groth16, &PredicateCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	var buf bytes.Buffer
+	ccs.WriteTo(&buf)
+	if !bytes.Equal(buf.Bytes(), ccsData) {
+		t.Fatalf("attribute.ccs is stale")
+	}
+}
@@ -0,0 +1,20 @@
+package attribute
+
+import (
+	"context"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
+
+// VerifyPredicatesResult is VerifyPredicates with a context, reporting a
+// common.VerificationResult: the error code and stage of a rejected proof, or
+// the vk_id and issuer_key for a valid one.
+func (v *Verifier) VerifyPredicatesResult(ctx context.Context, proofBytes []byte, issuerKey string, challenge string) common.VerificationResult {
+	start := time.Now()
+	_, err := v.verifyPredicates(ctx, proofBytes, issuerKey, challenge)
+	return common.NewVerificationResult(start, map[string]string{
+		common.ClaimVKID:      v.VerifyingKeyID(),
+		common.ClaimIssuerKey: issuerKey,
+	}, err)
+}
@@ -0,0 +1,102 @@
+package attribute
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+//go:embed attribute.vk
+var verifyingKeyData []byte
+
+// EmbeddedVerifyingKeyID is the blake2b-256 fingerprint of the embedded attribute verifying key.
+var EmbeddedVerifyingKeyID = backend.KeyID(verifyingKeyData)
+
+// VerifierConfig holds configuration for the attribute verifier.
+type VerifierConfig struct {
+	Policy         Policy   // predicates enforced by VerifyPredicates
+	TrustedIssuers []string // hex issuer public keys accepted
+	ExpectedVK     string   // optional: expected verifying key fingerprint
+}
+
+// Verifier verifies attribute predicate proofs against a fixed policy.
+type Verifier struct {
+	verifyingKey   *backend.VerifyingKey
+	policy         Policy
+	trustedIssuers map[string]bool
+}
+
+// NewVerifier creates an attribute verifier enforcing cfg.Policy.
+func NewVerifier(cfg VerifierConfig) (*Verifier, error) {
+	if err := cfg.Policy.Validate(); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidConfig.Code, "attribute policy invalid", err)
+	}
+	if len(verifyingKeyData) == 0 {
+		return nil, sdkerrors.ErrKeyNotFound
+	}
+	vk, err := backend.LoadVerifyingKey(backend.Groth16, verifyingKeyData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "attribute verifying key parse failed", err)
+	}
+	if cfg.ExpectedVK != "" && cfg.ExpectedVK != EmbeddedVerifyingKeyID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "attribute verifying key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedVK, EmbeddedVerifyingKeyID))
+	}
+	trusted := make(map[string]bool, len(cfg.TrustedIssuers))
+	for _, key := range cfg.TrustedIssuers {
+		if _, err := parseIssuerKey(key); err != nil {
+			return nil, err
+		}
+		trusted[key] = true
+	}
+	return &Verifier{
+		verifyingKey:   vk,
+		policy:         cfg.Policy,
+		trustedIssuers: trusted,
+	}, nil
+}
+
+// VerifyPredicates validates a proof that a credential from issuerKey (hex)
+// satisfies the verifier's policy, made for challenge, which the verifier
+// issued for this presentation and must not accept twice. The issuer must be
+// listed in VerifierConfig.TrustedIssuers.
+func (v *Verifier) VerifyPredicates(proofBytes []byte, issuerKey string, challenge string) (bool, error) {
+	return v.verifyPredicates(context.Background(), proofBytes, issuerKey, challenge)
+}
+
+func (v *Verifier) verifyPredicates(ctx context.Context, proofBytes []byte, issuerKey string, challenge string) (bool, error) {
+	if !v.trustedIssuers[issuerKey] {
+		return false, sdkerrors.ErrIssuerUntrusted
+	}
+	assignment, err := publicAssignment(v.policy, issuerKey, challenge)
+	if err != nil {
+		return false, err
+	}
+	if err := backend.VerifyContext(ctx, v.verifyingKey, proofBytes, assignment); err != nil {
+		return false, backend.VerifyError(err, "attribute")
+	}
+	return true, nil
+}
+
+// Policy returns the enforced policy, for publishing to clients.
+func (v *Verifier) Policy() Policy {
+	return v.policy
+}
+
+// TrustedIssuers returns the issuer public keys accepted by VerifyPredicates.
+func (v *Verifier) TrustedIssuers() []string {
+	out := make([]string, 0, len(v.trustedIssuers))
+	for k := range v.trustedIssuers {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// VerifyingKeyID returns the fingerprint of the embedded attribute verifying key.
+func (v *Verifier) VerifyingKeyID() string {
+	return EmbeddedVerifyingKeyID
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// LoginRequest is one login proof submitted to VerifyLoginBatch.
+type LoginRequest struct {
+	Proof      []byte
+	Commitment string
+	Salt       string
+	// Challenge is used as-is; when ChallengeToken is set it is validated and
+	// its challenge is used instead.
+	Challenge      string
+	ChallengeToken string
+	Channel        string // optional channel binding
+}
+
+// BatchResult is the outcome of one entry of a batch verification call.
+type BatchResult struct {
+	Valid bool
+	Code  string // error code (e.g. E1003) when Err is set
+	Err   error
+}
+
+// VerifyLoginBatch verifies many login proofs in one call and returns one result
+// per request, in order. A malformed or invalid entry only fails its own result.
+// Groth16 proofs share a single randomized pairing check; PLONK proofs are
+// verified in parallel.
+func (v *Verifier) VerifyLoginBatch(requests []LoginRequest) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	items := make([]backend.BatchItem, 0, len(requests))
+	index := make([]int, 0, len(requests))
+	tokens := make(map[int]ChallengeTokenClaims)
+	for i, req := range requests {
+		challenge := req.Challenge
+		if req.ChallengeToken != "" {
+			claims, err := v.tokenClaims(req.ChallengeToken, req.Channel)
+			if err != nil {
+				results[i] = batchResult(err)
+				continue
+			}
+			challenge = claims.Challenge
+			tokens[i] = claims
+		}
+		assignment, err := v.loginAssignment(req.Commitment, req.Salt, challenge, req.Channel)
+		if err != nil {
+			results[i] = batchResult(err)
+			continue
+		}
+		items = append(items, backend.BatchItem{Proof: req.Proof, Public: assignment})
+		index = append(index, i)
+	}
+
+	for j, err := range backend.VerifyBatch(v.verifyingKey, items) {
+		if err != nil {
+			err = backend.VerifyError(err, "proof")
+		} else if claims, ok := tokens[index[j]]; ok {
+			err = v.consumeToken(claims)
+		}
+		results[index[j]] = batchResult(err)
+	}
+	return results
+}
+
+func batchResult(err error) BatchResult {
+	if err == nil {
+		return BatchResult{Valid: true}
+	}
+	code := sdkerrors.CodeOf(err)
+	if code == "" {
+		code = sdkerrors.ErrVerificationFail.Code
+	}
+	return BatchResult{Code: code, Err: err}
+}
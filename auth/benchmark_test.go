@@ -19,9 +19,9 @@ func BenchmarkVerifyLogin(b *testing.B) {
 
 	secret := "bench-secret"
 	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
-	challenge := 4242
+	challenge := "4242"
 
-	proof, commitment, _, err := prover.GenerateProof(secret, 2000, cfg.TargetYear, cfg.LimitAge, challenge, salt)
+	proof, commitment, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
 	if err != nil {
 		b.Fatalf("proof generation failed: %v", err)
 	}
@@ -34,6 +34,40 @@ func BenchmarkVerifyLogin(b *testing.B) {
 	}
 }
 
+func BenchmarkVerifyLoginBatch(b *testing.B) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		b.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		b.Fatalf("verifier init failed: %v", err)
+	}
+
+	secret := "bench-secret"
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "4242"
+
+	proof, commitment, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		b.Fatalf("proof generation failed: %v", err)
+	}
+	requests := make([]LoginRequest, 32)
+	for i := range requests {
+		requests[i] = LoginRequest{Proof: proof, Commitment: commitment, Salt: salt, Challenge: challenge}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, res := range verifier.VerifyLoginBatch(requests) {
+			if !res.Valid {
+				b.Fatalf("verification failed: %v", res.Err)
+			}
+		}
+	}
+}
+
 func BenchmarkGenerateProof(b *testing.B) {
 	cfg := common.DefaultSharedConfig()
 	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
@@ -43,11 +77,11 @@ func BenchmarkGenerateProof(b *testing.B) {
 
 	secret := "bench-secret"
 	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
-	challenge := 4242
+	challenge := "4242"
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		if _, _, _, err := prover.GenerateProof(secret, 2000, cfg.TargetYear, cfg.LimitAge, challenge, salt); err != nil {
+		if _, _, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt); err != nil {
 			b.Fatalf("proof generation failed: %v", err)
 		}
 	}
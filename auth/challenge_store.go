@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// DefaultMaxOutstandingChallenges is the per-user limit of unconsumed
+// challenges unless ChallengeStoreConfig.MaxOutstanding says otherwise.
+const DefaultMaxOutstandingChallenges = 5
+
+// DefaultChallengeCompactThreshold is the number of stale lines a
+// FileChallengeStore tolerates before rewriting its file, unless
+// ChallengeStoreConfig.CompactThreshold says otherwise.
+const DefaultChallengeCompactThreshold = 1024
+
+// Challenge is a one-time login challenge issued by a ChallengeStore. The
+// server sends ID and Challenge to the client, which proves for Challenge and
+// returns ID with the proof.
+type Challenge struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Challenge string `json:"challenge"` // decimal field element
+	ExpiresAt int64  `json:"exp"`       // unix seconds
+}
+
+// ChallengeStore issues server-side login challenges that can be consumed
+// once, for deployments that do not use stateless challenge tokens.
+//
+// Issuing needs only a user ID, so anyone can exhaust a victim's
+// MaxOutstanding and lock them out of login until those challenges expire.
+// The endpoint that issues challenges must be rate limited by caller (client
+// address, session or API key), not just by user.
+type ChallengeStore interface {
+	// Issue creates a challenge for userID. It fails with E1020 while the user
+	// already has the maximum number of unexpired, unconsumed challenges.
+	Issue(userID string) (Challenge, error)
+	// Consume removes the challenge with id and returns it. Unknown ids fail
+	// with E1012, expired challenges with E1011 and consumed ones with E1013.
+	Consume(id string) (Challenge, error)
+}
+
+// ChallengeStoreConfig holds the limits of a challenge store.
+type ChallengeStoreConfig struct {
+	TTL            time.Duration // challenge lifetime (default DefaultChallengeTTL)
+	MaxOutstanding int           // unconsumed challenges per user (default DefaultMaxOutstandingChallenges)
+	// CompactThreshold is the number of stale lines (consumed, expired or
+	// superseded entries) after which a FileChallengeStore rewrites its file
+	// (default DefaultChallengeCompactThreshold).
+	CompactThreshold int
+}
+
+type challengeEntry struct {
+	Challenge
+	Used bool `json:"used,omitempty"`
+}
+
+// MemoryChallengeStore is an in-memory implementation of ChallengeStore.
+// Consumed challenges are remembered until they expire so a replay is
+// reported as such. Challenges are indexed by user, so issuing only looks at
+// the user's own entries; expired entries of all users are swept at most once
+// per TTL as new challenges are issued.
+type MemoryChallengeStore struct {
+	config    ChallengeStoreConfig
+	entries   map[string]*challengeEntry
+	users     map[string]map[string]struct{} // user ID -> challenge IDs
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewMemoryChallengeStore creates a new in-memory challenge store.
+func NewMemoryChallengeStore(config ChallengeStoreConfig) *MemoryChallengeStore {
+	if config.TTL <= 0 {
+		config.TTL = DefaultChallengeTTL
+	}
+	if config.MaxOutstanding <= 0 {
+		config.MaxOutstanding = DefaultMaxOutstandingChallenges
+	}
+	if config.CompactThreshold <= 0 {
+		config.CompactThreshold = DefaultChallengeCompactThreshold
+	}
+	return &MemoryChallengeStore{
+		config:    config,
+		entries:   make(map[string]*challengeEntry),
+		users:     make(map[string]map[string]struct{}),
+		lastSweep: time.Now(),
+	}
+}
+
+// Issue creates a challenge for userID.
+func (m *MemoryChallengeStore) Issue(userID string) (Challenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.issue(userID, func(challengeEntry) error { return nil })
+}
+
+// Consume removes the challenge with id and returns it.
+func (m *MemoryChallengeStore) Consume(id string) (Challenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.consume(id, func(challengeEntry) error { return nil })
+}
+
+// issue creates a challenge for userID and records it once persist succeeds.
+// m.mu must be held.
+func (m *MemoryChallengeStore) issue(userID string, persist func(challengeEntry) error) (Challenge, error) {
+	if userID == "" {
+		return Challenge{}, sdkerrors.ErrMissingArguments
+	}
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= m.config.TTL {
+		m.sweep(now.Unix())
+		m.lastSweep = now
+	}
+	outstanding := 0
+	for id := range m.users[userID] {
+		switch e := m.entries[id]; {
+		case e.ExpiresAt <= now.Unix():
+			m.remove(id)
+		case !e.Used:
+			outstanding++
+		}
+	}
+	if outstanding >= m.config.MaxOutstanding {
+		return Challenge{}, sdkerrors.New(sdkerrors.ErrRateLimited.Code, "too many outstanding challenges")
+	}
+	id, err := generateJTI()
+	if err != nil {
+		return Challenge{}, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge id generation failed", err)
+	}
+	challenge, err := commitment.NewChallenge()
+	if err != nil {
+		return Challenge{}, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge generation failed", err)
+	}
+	e := challengeEntry{Challenge: Challenge{
+		ID:        id,
+		UserID:    userID,
+		Challenge: challenge,
+		ExpiresAt: now.Add(m.config.TTL).Unix(),
+	}}
+	if err := persist(e); err != nil {
+		return Challenge{}, err
+	}
+	m.put(&e)
+	return e.Challenge, nil
+}
+
+// put records e, replacing an entry with the same ID. m.mu must be held.
+func (m *MemoryChallengeStore) put(e *challengeEntry) {
+	m.entries[e.ID] = e
+	ids := m.users[e.UserID]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		m.users[e.UserID] = ids
+	}
+	ids[e.ID] = struct{}{}
+}
+
+// remove drops the entry with id. m.mu must be held.
+func (m *MemoryChallengeStore) remove(id string) {
+	e, ok := m.entries[id]
+	if !ok {
+		return
+	}
+	delete(m.entries, id)
+	if ids := m.users[e.UserID]; ids != nil {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(m.users, e.UserID)
+		}
+	}
+}
+
+// sweep drops the entries that expired at or before now (unix seconds). m.mu
+// must be held.
+func (m *MemoryChallengeStore) sweep(now int64) {
+	for id, e := range m.entries {
+		if e.ExpiresAt <= now {
+			m.remove(id)
+		}
+	}
+}
+
+// consume marks the challenge with id used once persist succeeds. m.mu must be held.
+func (m *MemoryChallengeStore) consume(id string, persist func(challengeEntry) error) (Challenge, error) {
+	e, ok := m.entries[id]
+	if !ok {
+		return Challenge{}, sdkerrors.New(sdkerrors.ErrChallengeInvalid.Code, "challenge not issued")
+	}
+	if e.ExpiresAt <= time.Now().Unix() {
+		m.remove(id)
+		return Challenge{}, sdkerrors.ErrChallengeExpired
+	}
+	if e.Used {
+		return Challenge{}, sdkerrors.New(ErrJTIAlreadyUsed.Code, "challenge already used")
+	}
+	used := *e
+	used.Used = true
+	if err := persist(used); err != nil {
+		return Challenge{}, err
+	}
+	e.Used = true
+	return e.Challenge, nil
+}
+
+// consumeChallenge consumes challenge id from store and checks it was issued to userID.
+func consumeChallenge(store ChallengeStore, userID string, id string) (Challenge, error) {
+	if userID == "" || id == "" {
+		return Challenge{}, sdkerrors.ErrMissingArguments
+	}
+	c, err := store.Consume(id)
+	if err != nil {
+		return Challenge{}, err
+	}
+	if c.UserID != userID {
+		return Challenge{}, sdkerrors.New(sdkerrors.ErrChallengeInvalid.Code, "challenge issued to another user")
+	}
+	return c, nil
+}
+
+// FileChallengeStore is a ChallengeStore persisted to an append-only file with
+// one JSON object per line, so outstanding and consumed challenges survive
+// restarts. Every change is synced before it returns. The file is compacted to
+// the unexpired challenges when the store is opened and again once it holds
+// CompactThreshold stale lines.
+type FileChallengeStore struct {
+	mem   *MemoryChallengeStore
+	path  string
+	file  *os.File
+	lines int // lines in the file
+}
+
+// NewFileChallengeStore opens (or creates) the store at path and loads the
+// challenges that have not expired.
+func NewFileChallengeStore(path string, config ChallengeStoreConfig) (*FileChallengeStore, error) {
+	mem := NewMemoryChallengeStore(config)
+	if err := loadChallenges(path, mem); err != nil {
+		return nil, err
+	}
+	f := &FileChallengeStore{mem: mem, path: path}
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// compact rewrites the file with the unexpired entries, swaps it in and
+// reopens it for appending. f.mem.mu must be held once the store is shared.
+func (f *FileChallengeStore) compact() error {
+	f.mem.sweep(time.Now().Unix())
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("challenge store compact failed: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, e := range f.mem.entries {
+		line, _ := json.Marshal(e)
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("challenge store compact failed: %w", err)
+	}
+	tmp.Close()
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("challenge store open failed: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.lines = len(f.mem.entries)
+	return nil
+}
+
+// loadChallenges replays the file at path into mem; a later line for an id
+// replaces an earlier one.
+func loadChallenges(path string, mem *MemoryChallengeStore) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("challenge store open failed: %w", err)
+	}
+	defer file.Close()
+	now := time.Now().Unix()
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e challengeEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("challenge store line %d invalid: %w", line, err)
+		}
+		mem.remove(e.ID)
+		if e.ExpiresAt <= now {
+			continue
+		}
+		mem.put(&e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("challenge store read failed: %w", err)
+	}
+	return nil
+}
+
+// Issue creates a challenge for userID and appends it to the file.
+func (f *FileChallengeStore) Issue(userID string) (Challenge, error) {
+	f.mem.mu.Lock()
+	defer f.mem.mu.Unlock()
+	return f.mem.issue(userID, f.append)
+}
+
+// Consume removes the challenge with id, records that in the file and returns it.
+func (f *FileChallengeStore) Consume(id string) (Challenge, error) {
+	f.mem.mu.Lock()
+	defer f.mem.mu.Unlock()
+	return f.mem.consume(id, f.append)
+}
+
+// append writes e to the file, first compacting it once it holds
+// CompactThreshold stale lines. f.mem.mu must be held.
+func (f *FileChallengeStore) append(e challengeEntry) error {
+	if f.lines-len(f.mem.entries) >= f.mem.config.CompactThreshold {
+		if err := f.compact(); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("challenge store write failed: %w", err)
+	}
+	f.lines++
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("challenge store sync failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (f *FileChallengeStore) Close() error {
+	return f.file.Close()
+}
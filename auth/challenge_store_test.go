@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestMemoryChallengeStore(t *testing.T) {
+	store := NewMemoryChallengeStore(ChallengeStoreConfig{MaxOutstanding: 2})
+	c, err := store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if c.ID == "" || c.Challenge == "" || c.UserID != "alice" {
+		t.Fatalf("unexpected challenge %+v", c)
+	}
+	if _, err := store.Issue("alice"); err != nil {
+		t.Fatalf("second issue failed: %v", err)
+	}
+	if _, err := store.Issue("alice"); sdkerrors.CodeOf(err) != sdkerrors.ErrRateLimited.Code {
+		t.Fatalf("expected the outstanding limit to apply, got %v", err)
+	}
+	if _, err := store.Issue("bob"); err != nil {
+		t.Fatalf("limit must be per user: %v", err)
+	}
+
+	got, err := store.Consume(c.ID)
+	if err != nil || got != c {
+		t.Fatalf("consume failed: %+v %v", got, err)
+	}
+	if _, err := store.Consume(c.ID); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected E1013 on reuse, got %v", err)
+	}
+	if _, err := store.Consume("unknown"); sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected E1012 for an unknown id, got %v", err)
+	}
+	if _, err := store.Issue("alice"); err != nil {
+		t.Fatalf("consuming must free an outstanding slot: %v", err)
+	}
+
+	expiring := NewMemoryChallengeStore(ChallengeStoreConfig{TTL: time.Nanosecond})
+	c, err = expiring.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if _, err := expiring.Consume(c.ID); sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeExpired.Code {
+		t.Fatalf("expected E1011 for an expired challenge, got %v", err)
+	}
+}
+
+func TestFileChallengeStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.jsonl")
+	store, err := NewFileChallengeStore(path, ChallengeStoreConfig{MaxOutstanding: 2})
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	used, err := store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	pending, err := store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if _, err := store.Consume(used.ID); err != nil {
+		t.Fatalf("consume failed: %v", err)
+	}
+	store.Close()
+
+	reopened, err := NewFileChallengeStore(path, ChallengeStoreConfig{MaxOutstanding: 2})
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.Consume(used.ID); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected E1013 after reopen, got %v", err)
+	}
+	if _, err := reopened.Issue("alice"); err != nil {
+		t.Fatalf("issue after reopen failed: %v", err)
+	}
+	if _, err := reopened.Issue("alice"); sdkerrors.CodeOf(err) != sdkerrors.ErrRateLimited.Code {
+		t.Fatalf("expected outstanding challenges to persist, got %v", err)
+	}
+	if got, err := reopened.Consume(pending.ID); err != nil || got != pending {
+		t.Fatalf("consume after reopen failed: %+v %v", got, err)
+	}
+}
+
+func TestFileChallengeStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.jsonl")
+	store, err := NewFileChallengeStore(path, ChallengeStoreConfig{MaxOutstanding: 1, CompactThreshold: 4})
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	var last Challenge
+	for i := 0; i < 20; i++ {
+		if last, err = store.Issue("alice"); err != nil {
+			t.Fatalf("issue %d failed: %v", i, err)
+		}
+		if _, err := store.Consume(last.ID); err != nil {
+			t.Fatalf("consume %d failed: %v", i, err)
+		}
+	}
+	pending, err := store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	store.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	// 21 live entries plus fewer than CompactThreshold stale lines.
+	if lines := strings.Count(string(data), "\n"); lines >= 21+4 {
+		t.Fatalf("expected the file to be compacted, got %d lines", lines)
+	}
+	reopened, err := NewFileChallengeStore(path, ChallengeStoreConfig{MaxOutstanding: 1})
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.Consume(last.ID); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected consumed challenges to survive compaction, got %v", err)
+	}
+	if got, err := reopened.Consume(pending.ID); err != nil || got != pending {
+		t.Fatalf("expected outstanding challenges to survive compaction: %+v %v", got, err)
+	}
+}
+
+func TestVerifyLoginWithChallengeID(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	store := NewMemoryChallengeStore(ChallengeStoreConfig{})
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, ChallengeStore: store})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	stored, salt, err := prover.CalculateCommitment("test-secret", 20000101)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	c, err := store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	proof, _, _, err := prover.GenerateProof("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, c.Challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+
+	if _, err := verifier.VerifyLoginWithChallengeID(proof, stored, salt, "bob", c.ID, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected another user's challenge to be rejected, got %v", err)
+	}
+	c, err = store.Issue("alice")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	proof, _, _, err = prover.GenerateProof("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, c.Challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyLoginWithChallengeID(proof, stored, salt, "alice", c.ID, ""); !ok || err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if _, err := verifier.VerifyLoginWithChallengeID(proof, stored, salt, "alice", c.ID, ""); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected challenge reuse to be rejected, got %v", err)
+	}
+
+	plain, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	if _, err := plain.VerifyLoginWithChallengeID(proof, stored, salt, "alice", c.ID, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrInvalidConfig.Code {
+		t.Fatalf("expected a missing store to be a config error, got %v", err)
+	}
+}
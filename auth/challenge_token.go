@@ -8,19 +8,36 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
-const ChallengeTokenVersion = "ct-v1"
+// Challenge token versions.
+const (
+	// ChallengeTokenVersionV1 carries a small integer challenge as a JSON number.
+	// It is still accepted so clients built before ct-v2 keep working during rollout.
+	ChallengeTokenVersionV1 = "ct-v1"
+	// ChallengeTokenVersionV2 carries a full-width field-element challenge as a decimal string.
+	ChallengeTokenVersionV2 = "ct-v2"
+	// ChallengeTokenVersion is the version issued by default.
+	ChallengeTokenVersion = ChallengeTokenVersionV2
+)
+
+// MinChallengeBits is the minimum bit length of an explicit ct-v2 challenge.
+// Challenges from commitment.NewChallenge or DeriveChallenge are full-width
+// field elements and fall below it with negligible probability.
+const MinChallengeBits = 128
 
 // ChallengeTokenClaims represents the stateless challenge payload.
+// Challenge is a decimal field element; ct-v1 tokens encode it as a JSON number.
 type ChallengeTokenClaims struct {
 	UserID        string `json:"user_id"`
-	Challenge     int    `json:"challenge"`
+	Challenge     string `json:"challenge"`
 	ExpiresAt     int64  `json:"exp"`
 	Nonce         string `json:"nonce"`
 	JTI           string `json:"jti"` // Unique token ID for replay prevention
@@ -28,6 +45,51 @@ type ChallengeTokenClaims struct {
 	ParamsVersion string `json:"params_version"`
 	KeyID         string `json:"kid,omitempty"`
 	Version       string `json:"v"`
+	// ChannelBinding optionally pins the token to one channel (decimal field element).
+	ChannelBinding string `json:"cb,omitempty"`
+}
+
+type challengeTokenClaimsJSON ChallengeTokenClaims
+
+type challengeTokenWire struct {
+	challengeTokenClaimsJSON
+	Challenge json.RawMessage `json:"challenge"`
+}
+
+// MarshalJSON encodes Challenge as a string, or as a number for ct-v1 tokens.
+func (c ChallengeTokenClaims) MarshalJSON() ([]byte, error) {
+	wire := challengeTokenWire{challengeTokenClaimsJSON: challengeTokenClaimsJSON(c)}
+	if c.Version == ChallengeTokenVersionV1 {
+		if _, err := strconv.ParseInt(c.Challenge, 10, 64); err != nil {
+			return nil, fmt.Errorf("ct-v1 challenge must be an integer: %w", err)
+		}
+		wire.Challenge = json.RawMessage(c.Challenge)
+	} else {
+		wire.Challenge, _ = json.Marshal(c.Challenge)
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON accepts Challenge as a JSON string or number.
+func (c *ChallengeTokenClaims) UnmarshalJSON(data []byte) error {
+	var wire challengeTokenWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*c = ChallengeTokenClaims(wire.challengeTokenClaimsJSON)
+	c.Challenge = ""
+	if len(wire.Challenge) == 0 || string(wire.Challenge) == "null" {
+		return nil
+	}
+	if wire.Challenge[0] == '"' {
+		return json.Unmarshal(wire.Challenge, &c.Challenge)
+	}
+	var n json.Number
+	if err := json.Unmarshal(wire.Challenge, &n); err != nil {
+		return err
+	}
+	c.Challenge = n.String()
+	return nil
 }
 
 // IssueChallengeToken creates a signed, stateless challenge token using HMAC-SHA256.
@@ -36,11 +98,16 @@ func IssueChallengeToken(secret []byte, claims ChallengeTokenClaims) (string, er
 }
 
 // IssueChallengeTokenWithKey creates a signed challenge token with an explicit key ID.
+// ct-v2 tokens derive the challenge when claims.Challenge is empty; an explicit
+// one must have at least MinChallengeBits bits (E1012).
 func IssueChallengeTokenWithKey(secret []byte, keyID string, claims ChallengeTokenClaims) (string, error) {
 	if len(secret) == 0 {
 		return "", sdkerrors.ErrTokenKeyMissing
 	}
-	if claims.ExpiresAt == 0 || claims.Challenge == 0 || claims.UserID == "" {
+	if claims.Version == "" {
+		claims.Version = ChallengeTokenVersion
+	}
+	if claims.ExpiresAt == 0 || claims.UserID == "" || (claims.Challenge == "" && claims.Version == ChallengeTokenVersionV1) {
 		return "", sdkerrors.ErrMissingArguments
 	}
 	if claims.Nonce == "" {
@@ -57,8 +124,15 @@ func IssueChallengeTokenWithKey(secret []byte, keyID string, claims ChallengeTok
 		}
 		claims.JTI = jti
 	}
-	if claims.Version == "" {
-		claims.Version = ChallengeTokenVersion
+	if claims.Challenge == "" {
+		claims.Challenge = commitment.DeriveChallenge(claims.JTI, claims.Nonce)
+	}
+	challengeInt, err := commitment.ParseChallenge(claims.Challenge)
+	if err != nil {
+		return "", sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	if claims.Version != ChallengeTokenVersionV1 && challengeInt.BitLen() < MinChallengeBits {
+		return "", sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge too short", fmt.Errorf("%d bits, need %d", challengeInt.BitLen(), MinChallengeBits))
 	}
 	if claims.ParamsVersion == "" {
 		claims.ParamsVersion = common.ParamsVersion(common.DefaultSharedConfig())
@@ -145,8 +219,8 @@ func ValidateChallengeToken(token string, secret []byte, now time.Time, expected
 	if expectedParams != "" && claims.ParamsVersion != expectedParams {
 		return ChallengeTokenClaims{}, sdkerrors.ErrPolicyMismatch
 	}
-	if claims.Version != "" && claims.Version != ChallengeTokenVersion {
-		return ChallengeTokenClaims{}, sdkerrors.ErrChallengeInvalid
+	if err := checkClaimsVersion(claims); err != nil {
+		return ChallengeTokenClaims{}, err
 	}
 	return claims, nil
 }
@@ -166,12 +240,25 @@ func ValidateChallengeTokenWithKeySet(token string, keys map[string][]byte, now
 	if expectedParams != "" && claims.ParamsVersion != expectedParams {
 		return ChallengeTokenClaims{}, sdkerrors.ErrPolicyMismatch
 	}
-	if claims.Version != "" && claims.Version != ChallengeTokenVersion {
-		return ChallengeTokenClaims{}, sdkerrors.ErrChallengeInvalid
+	if err := checkClaimsVersion(claims); err != nil {
+		return ChallengeTokenClaims{}, err
 	}
 	return claims, nil
 }
 
+// checkClaimsVersion accepts ct-v1 (or unversioned) and ct-v2 tokens with a valid challenge.
+func checkClaimsVersion(claims ChallengeTokenClaims) error {
+	switch claims.Version {
+	case "", ChallengeTokenVersionV1, ChallengeTokenVersionV2:
+	default:
+		return sdkerrors.ErrChallengeInvalid
+	}
+	if _, err := commitment.ParseChallenge(claims.Challenge); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	return nil
+}
+
 func signHMAC(secret []byte, payload []byte) []byte {
 	mac := hmac.New(sha256.New, secret)
 	mac.Write(payload)
@@ -1,19 +1,24 @@
 package auth
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
+// testTokenChallenge is an explicit ct-v2 challenge above MinChallengeBits.
+const testTokenChallenge = "340282366920938463463374607431768211507"
+
 func TestChallengeTokenRoundTrip(t *testing.T) {
 	secret := []byte("test-secret")
 	cfg := common.DefaultSharedConfig()
 	claims := ChallengeTokenClaims{
 		UserID:        "user-123",
-		Challenge:     4242,
+		Challenge:     testTokenChallenge,
 		ExpiresAt:     time.Now().Add(5 * time.Minute).Unix(),
 		VKID:          "vk-abc",
 		ParamsVersion: common.ParamsVersion(cfg),
@@ -40,7 +45,7 @@ func TestChallengeTokenKeySet(t *testing.T) {
 	}
 	claims := ChallengeTokenClaims{
 		UserID:    "user-123",
-		Challenge: 4242,
+		Challenge: testTokenChallenge,
 		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
 	}
 	token, err := IssueChallengeTokenWithKey(keys["k1"], "k1", claims)
@@ -60,7 +65,7 @@ func TestChallengeTokenExpired(t *testing.T) {
 	secret := []byte("test-secret")
 	claims := ChallengeTokenClaims{
 		UserID:    "user-123",
-		Challenge: 1,
+		Challenge: testTokenChallenge,
 		ExpiresAt: time.Now().Add(-1 * time.Minute).Unix(),
 	}
 	token, err := IssueChallengeToken(secret, claims)
@@ -81,7 +86,7 @@ func TestChallengeTokenInvalidSignature(t *testing.T) {
 	secret := []byte("test-secret")
 	claims := ChallengeTokenClaims{
 		UserID:    "user-123",
-		Challenge: 1,
+		Challenge: testTokenChallenge,
 		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
 	}
 	token, err := IssueChallengeToken(secret, claims)
@@ -97,3 +102,135 @@ func TestChallengeTokenInvalidSignature(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestChallengeTokenV2DerivesFieldChallenge(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := ChallengeTokenClaims{
+		UserID:    "user-123",
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+	}
+	token, err := IssueChallengeToken(secret, claims)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	got, err := ValidateChallengeToken(token, secret, time.Now(), "", "")
+	if err != nil {
+		t.Fatalf("validate token: %v", err)
+	}
+	if got.Version != ChallengeTokenVersionV2 {
+		t.Fatalf("expected %s, got %s", ChallengeTokenVersionV2, got.Version)
+	}
+	if got.Challenge != commitment.DeriveChallenge(got.JTI, got.Nonce) {
+		t.Fatalf("challenge not derived from jti/nonce: %s", got.Challenge)
+	}
+	ch, err := commitment.ParseChallenge(got.Challenge)
+	if err != nil {
+		t.Fatalf("challenge parse: %v", err)
+	}
+	if ch.BitLen() < 128 {
+		t.Fatalf("challenge too short: %d bits", ch.BitLen())
+	}
+}
+
+func TestChallengeTokenV2RejectsShortChallenge(t *testing.T) {
+	secret := []byte("test-secret")
+	for _, challenge := range []string{"1", "4242", "170141183460469231731687303715884105727"} { // up to 2^127-1
+		_, err := IssueChallengeToken(secret, ChallengeTokenClaims{
+			UserID:    "user-123",
+			Challenge: challenge,
+			ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+		})
+		if sdkerrors.CodeOf(err) != sdkerrors.ErrChallengeInvalid.Code {
+			t.Fatalf("expected E1012 for ct-v2 challenge %s, got %v", challenge, err)
+		}
+	}
+}
+
+func TestChallengeTokenV1Compat(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := ChallengeTokenClaims{
+		UserID:    "user-123",
+		Challenge: "4242",
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+		Version:   ChallengeTokenVersionV1,
+	}
+	token, err := IssueChallengeToken(secret, claims)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	payload, _, err := splitToken(token)
+	if err != nil {
+		t.Fatalf("split token: %v", err)
+	}
+	var legacy struct {
+		Challenge int `json:"challenge"`
+	}
+	if err := json.Unmarshal(payload, &legacy); err != nil || legacy.Challenge != 4242 {
+		t.Fatalf("ct-v1 payload must carry a numeric challenge: %s", payload)
+	}
+
+	got, err := ValidateChallengeToken(token, secret, time.Now(), "", "")
+	if err != nil {
+		t.Fatalf("validate token: %v", err)
+	}
+	if got.Challenge != "4242" {
+		t.Fatalf("challenge mismatch: %s", got.Challenge)
+	}
+
+	verifier, err := NewVerifierWithConfig(VerifierConfig{TokenKey: secret, RejectLegacyTokens: true})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	if _, err := verifier.VerifyLoginWithToken(nil, "1", "00", token); err != sdkerrors.ErrChallengeInvalid {
+		t.Fatalf("expected legacy token rejection, got %v", err)
+	}
+}
+
+func TestVerifyLoginWithTokenConsumesJTI(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	tokenKey := []byte("token-key")
+	prover, err := NewUserProver()
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey, TokenStore: NewMemoryTokenStore()})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	stored, salt, err := prover.CalculateCommitment("test-secret", 20000101)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+		UserID:        "user-123",
+		ExpiresAt:     time.Now().Add(time.Minute).Unix(),
+		VKID:          verifier.VerifyingKeyID(),
+		ParamsVersion: common.ParamsVersion(cfg),
+	})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, err := ParseChallengeToken(token, tokenKey)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	proof, _, _, err := prover.GenerateProof("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, claims.Challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+
+	if _, err := verifier.VerifyLoginWithToken([]byte("bad"), stored, salt, token); err == nil {
+		t.Fatal("expected a bad proof to fail")
+	}
+	if ok, err := verifier.VerifyLoginWithToken(proof, stored, salt, token); !ok || err != nil {
+		t.Fatalf("a failed proof must not consume the token: %v", err)
+	}
+	if _, err := verifier.VerifyLoginWithToken(proof, stored, salt, token); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected token reuse to be rejected, got %v", err)
+	}
+	results := verifier.VerifyLoginBatch([]LoginRequest{{Proof: proof, Commitment: stored, Salt: salt, ChallengeToken: token}})
+	if results[0].Code != ErrJTIAlreadyUsed.Code {
+		t.Fatalf("expected batch token reuse to be rejected, got %+v", results[0])
+	}
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// SecretChange is a change-secret proof with the commitments it rotates between.
+// After VerifySecretChange succeeds the server replaces the stored commitment and
+// salt with NewCommitment and NewSalt.
+type SecretChange struct {
+	Proof         []byte
+	OldCommitment string
+	OldSalt       string
+	NewCommitment string
+	NewSalt       string
+}
+
+// GenerateChangeSecretProof proves knowledge of oldSecret behind the commitment
+// stored with oldSaltHex and commits newSecret under a fresh salt, keeping the
+// registered birth date (commitment.NoBirthDate if none). challenge comes from
+// a challenge token issued for the change. Change-secret proofs use Groth16
+// with the prover's commitment scheme; the circuit is compiled on first use.
+func (u *UserProver) GenerateChangeSecretProof(oldSecret string, newSecret string, birthDate int, oldSaltHex string, challenge string) (SecretChange, error) {
+	return u.GenerateChangeSecretProofContext(context.Background(), oldSecret, newSecret, birthDate, oldSaltHex, challenge)
+}
+
+// GenerateChangeSecretProofContext is GenerateChangeSecretProof that gives up
+// once ctx is canceled or its deadline passes, returning an E1019 error.
+func (u *UserProver) GenerateChangeSecretProofContext(ctx context.Context, oldSecret string, newSecret string, birthDate int, oldSaltHex string, challenge string) (SecretChange, error) {
+	if err := sdkerrors.FromContext(ctx); err != nil {
+		return SecretChange{}, err
+	}
+	oldCommit, oldSalt, oldDerived, err := commitment.ComputeCommitmentWithScheme(oldSecret, oldSaltHex, birthDate, u.scheme, u.config)
+	if err != nil {
+		return SecretChange{}, err
+	}
+	newSaltHex, err := GenerateSalt()
+	if err != nil {
+		return SecretChange{}, err
+	}
+	newCommit, newSalt, newDerived, err := commitment.ComputeCommitmentWithScheme(newSecret, newSaltHex, birthDate, u.scheme, u.config)
+	if err != nil {
+		return SecretChange{}, err
+	}
+	if err := sdkerrors.FromContext(ctx); err != nil {
+		return SecretChange{}, err
+	}
+	binding, err := commitment.ComputeChangeBinding(oldCommit, challenge, newCommit, u.scheme)
+	if err != nil {
+		return SecretChange{}, err
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return SecretChange{}, err
+	}
+	if err := u.loadChangeSecretCircuit(); err != nil {
+		return SecretChange{}, err
+	}
+
+	var oldHashInt, newHashInt, bindingInt big.Int
+	oldHashInt.SetString(oldCommit, 10)
+	newHashInt.SetString(newCommit, 10)
+	bindingInt.SetString(binding, 10)
+
+	proof, err := backend.ProveContext(ctx, u.changeCCS, u.changePK, &ChangeSecretCircuit{
+		OldHash:      oldHashInt,
+		OldSalt:      oldSalt,
+		NewHash:      newHashInt,
+		NewSalt:      newSalt,
+		Binding:      bindingInt,
+		Challenge:    challengeInt,
+		OldSecretKey: frToBigInt(oldDerived),
+		NewSecretKey: frToBigInt(newDerived),
+		BirthDate:    birthDate,
+	})
+	if err != nil {
+		return SecretChange{}, fmt.Errorf("change-secret %w", err)
+	}
+	return SecretChange{
+		Proof:         proof,
+		OldCommitment: oldCommit,
+		OldSalt:       oldSaltHex,
+		NewCommitment: newCommit,
+		NewSalt:       newSaltHex,
+	}, nil
+}
+
+func (u *UserProver) loadChangeSecretCircuit() error {
+	u.changeOnce.Do(func() {
+		ccs, err := loadConstraintSystem(CircuitChangeSecret, backend.Groth16, u.scheme)
+		if err != nil {
+			u.changeErr = fmt.Errorf("change-secret circuit load failed: %w", err)
+			return
+		}
+		data := u.changePKData
+		if data == nil {
+			data = embeddedProvingKey(CircuitChangeSecret, backend.Groth16, u.scheme)
+		}
+		pk, err := backend.LoadProvingKey(backend.Groth16, data)
+		if err != nil {
+			u.changeErr = fmt.Errorf("change-secret proving key parse failed: %w", err)
+			return
+		}
+		u.changeCCS = ccs
+		u.changePK = pk
+	})
+	return u.changeErr
+}
+
+// VerifySecretChange verifies a change-secret proof against a challenge token
+// issued for the change. The token is validated like a login token: if it
+// names a channel binding it must equal channel, the binding the server
+// observes for the request (see VerifyLoginWithTokenAndChannel). When
+// VerifierConfig.TokenStore is set, its JTI is consumed so the token cannot be
+// replayed (E1013).
+//
+// The caller must check that change.OldCommitment and change.OldSalt are the
+// values stored for the token's user before replacing them; a replayed change
+// then also fails because the stored commitment has already moved on.
+func (v *Verifier) VerifySecretChange(change SecretChange, challengeToken string, channel string) (bool, error) {
+	return v.VerifySecretChangeContext(context.Background(), change, challengeToken, channel)
+}
+
+// VerifySecretChangeContext is VerifySecretChange with a context (see
+// VerifyLoginContext). A canceled verification does not consume the token.
+func (v *Verifier) VerifySecretChangeContext(ctx context.Context, change SecretChange, challengeToken string, channel string) (bool, error) {
+	if err := v.loadChangeSecretKey(); err != nil {
+		return false, err
+	}
+	claims, err := v.tokenClaims(challengeToken, channel)
+	if err != nil {
+		return false, err
+	}
+	assignment, err := v.changeAssignment(change, claims.Challenge)
+	if err != nil {
+		return false, err
+	}
+	if err := backend.VerifyContext(ctx, v.changeVK, change.Proof, assignment); err != nil {
+		return false, backend.VerifyError(err, "proof")
+	}
+	if err := v.consumeToken(claims); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// changeAssignment builds the public assignment a change-secret proof is checked against.
+func (v *Verifier) changeAssignment(change SecretChange, challenge string) (frontend.Circuit, error) {
+	var oldHashInt, newHashInt big.Int
+	if _, ok := oldHashInt.SetString(change.OldCommitment, 10); !ok {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "old commitment parse failed", fmt.Errorf("%q", change.OldCommitment))
+	}
+	if _, ok := newHashInt.SetString(change.NewCommitment, 10); !ok {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "new commitment parse failed", fmt.Errorf("%q", change.NewCommitment))
+	}
+	oldSalt, err := saltStringToInt(change.OldSalt)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSaltParse.Code, "old salt parse failed", err)
+	}
+	newSalt, err := saltStringToInt(change.NewSalt)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSaltParse.Code, "new salt parse failed", err)
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	bindingStr, err := commitment.ComputeChangeBinding(change.OldCommitment, challenge, change.NewCommitment, v.scheme)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrBindingCompute.Code, "binding compute failed", err)
+	}
+	var bindingInt big.Int
+	bindingInt.SetString(bindingStr, 10)
+
+	return &ChangeSecretCircuit{
+		OldHash:   oldHashInt,
+		OldSalt:   oldSalt,
+		NewHash:   newHashInt,
+		NewSalt:   newSalt,
+		Binding:   bindingInt,
+		Challenge: challengeInt,
+	}, nil
+}
+
+func (v *Verifier) loadChangeSecretKey() error {
+	v.changeOnce.Do(func() {
+		if len(v.changeVKData) == 0 {
+			v.changeErr = sdkerrors.New(sdkerrors.ErrKeyNotFound.Code, "change-secret verifying key not configured (VerifierConfig.ChangeSecretVK)")
+			return
+		}
+		vk, err := backend.LoadVerifyingKey(backend.Groth16, v.changeVKData)
+		if err != nil {
+			v.changeErr = sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "change-secret verifying key parse failed", err)
+			return
+		}
+		v.changeVK = vk
+	})
+	return v.changeErr
+}
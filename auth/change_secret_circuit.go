@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// ChangeSecretCircuit proves knowledge of the secret behind the stored
+// commitment and that the new commitment commits to the same birth date under
+// a new secret and salt. The new commitment and salt are public, so a proof
+// rotates to exactly one new commitment; the binding ties it to one challenge.
+type ChangeSecretCircuit struct {
+	// Public inputs
+	OldHash   frontend.Variable `gnark:",public"`
+	OldSalt   frontend.Variable `gnark:",public"`
+	NewHash   frontend.Variable `gnark:",public"`
+	NewSalt   frontend.Variable `gnark:",public"`
+	Binding   frontend.Variable `gnark:",public"`
+	Challenge frontend.Variable `gnark:",public"`
+
+	// Private inputs
+	OldSecretKey frontend.Variable
+	NewSecretKey frontend.Variable
+	BirthDate    frontend.Variable // YYYYMMDD or commitment.NoBirthDate, unchanged by the rotation
+
+	// Hash selects the commitment hash at compile time (hasher.MiMC when empty).
+	Hash string `gnark:"-"`
+}
+
+// Define implements the gnark circuit definition.
+func (circuit *ChangeSecretCircuit) Define(api frontend.API) error {
+	// Old commitment: H(oldSecret, oldSalt, birthDate)
+	oldHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	oldHash.Write(circuit.OldSecretKey, circuit.OldSalt, circuit.BirthDate)
+	api.AssertIsEqual(oldHash.Sum(), circuit.OldHash)
+
+	// New commitment: H(newSecret, newSalt, birthDate)
+	newHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	newHash.Write(circuit.NewSecretKey, circuit.NewSalt, circuit.BirthDate)
+	api.AssertIsEqual(newHash.Sum(), circuit.NewHash)
+
+	// Challenge binding: H(oldCommitment, challenge, newCommitment)
+	bindHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	bindHash.Write(circuit.OldHash, circuit.Challenge, circuit.NewHash)
+	api.AssertIsEqual(bindHash.Sum(), circuit.Binding)
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestChangeSecret(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	tokenKey := []byte("token-key")
+	for _, scheme := range []int{commitment.SchemeV2, commitment.SchemeV3} {
+		policy := DefaultPolicy()
+		policy.Scheme = scheme
+		prover, err := NewUserProverWithPolicy(policy, cfg)
+		if err != nil {
+			t.Fatalf("v%d: prover init failed: %v", scheme, err)
+		}
+		verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey, Scheme: scheme, TokenStore: NewMemoryTokenStore()})
+		if err != nil {
+			t.Fatalf("v%d: verifier init failed: %v", scheme, err)
+		}
+
+		salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+		token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+			UserID:        "user-123",
+			ExpiresAt:     time.Now().Add(time.Minute).Unix(),
+			VKID:          verifier.VerifyingKeyID(),
+			ParamsVersion: common.ParamsVersion(cfg),
+		})
+		if err != nil {
+			t.Fatalf("v%d: issue token: %v", scheme, err)
+		}
+		claims, err := ParseChallengeToken(token, tokenKey)
+		if err != nil {
+			t.Fatalf("v%d: parse token: %v", scheme, err)
+		}
+
+		change, err := prover.GenerateChangeSecretProof("old-secret", "new-secret", 20000101, salt, claims.Challenge)
+		if err != nil {
+			t.Fatalf("v%d: change proof failed: %v", scheme, err)
+		}
+		stored, _, _, err := commitment.ComputeCommitmentWithScheme("old-secret", salt, 20000101, scheme, cfg)
+		if err != nil || change.OldCommitment != stored || change.NewSalt == salt {
+			t.Fatalf("v%d: unexpected change %+v: %v", scheme, change, err)
+		}
+
+		tampered := change
+		tampered.NewCommitment = stored
+		if _, err := verifier.VerifySecretChange(tampered, token, ""); err == nil {
+			t.Fatalf("v%d: expected a swapped new commitment to fail", scheme)
+		}
+		ok, err := verifier.VerifySecretChange(change, token, "")
+		if err != nil || !ok {
+			t.Fatalf("v%d: change verification failed: %v", scheme, err)
+		}
+		if _, err := verifier.VerifySecretChange(change, token, ""); sdkerrors.CodeOf(err) != ErrJTIAlreadyUsed.Code {
+			t.Fatalf("v%d: expected replay to be rejected with E1013, got %v", scheme, err)
+		}
+
+		// The new secret logs in against the rotated commitment.
+		proof, commit, _, err := prover.GenerateProof("new-secret", 20000101, 0, 0, "99", change.NewSalt)
+		if err != nil || commit != change.NewCommitment {
+			t.Fatalf("v%d: login with new secret failed: %v", scheme, err)
+		}
+		if ok, err := verifier.VerifyLogin(proof, commit, change.NewSalt, "99"); err != nil || !ok {
+			t.Fatalf("v%d: login verification failed: %v", scheme, err)
+		}
+	}
+}
+
+func TestChangeSecretWrongOldSecret(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	tokenKey := []byte("token-key")
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+		UserID:        "user-123",
+		ExpiresAt:     time.Now().Add(time.Minute).Unix(),
+		VKID:          verifier.VerifyingKeyID(),
+		ParamsVersion: common.ParamsVersion(cfg),
+	})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, _ := ParseChallengeToken(token, tokenKey)
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	stored, _, _, err := commitment.ComputeCommitment("old-secret", salt, 20000101, cfg)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	change, err := prover.GenerateChangeSecretProof("guessed-secret", "new-secret", 20000101, salt, claims.Challenge)
+	if err != nil {
+		t.Fatalf("change proof failed: %v", err)
+	}
+	if change.OldCommitment == stored {
+		t.Fatal("a wrong old secret must not reproduce the stored commitment")
+	}
+	// Presenting the stored commitment with a proof for another secret fails.
+	change.OldCommitment = stored
+	if _, err := verifier.VerifySecretChange(change, token, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+		t.Fatalf("expected E1003, got %v", err)
+	}
+}
+
+func TestChangeSecretChannelBinding(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	tokenKey := []byte("token-key")
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey, TokenStore: NewMemoryTokenStore()})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	channel := commitment.ChannelBindingFromBytes([]byte("session-public-key"))
+	other := commitment.ChannelBindingFromBytes([]byte("proxy-session-key"))
+	token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+		UserID:         "user-123",
+		ExpiresAt:      time.Now().Add(time.Minute).Unix(),
+		VKID:           verifier.VerifyingKeyID(),
+		ParamsVersion:  common.ParamsVersion(cfg),
+		ChannelBinding: channel,
+	})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, _ := ParseChallengeToken(token, tokenKey)
+
+	change, err := prover.GenerateChangeSecretProof("old-secret", "new-secret", 20000101, "deadbeefdeadbeefdeadbeefdeadbeef", claims.Challenge)
+	if err != nil {
+		t.Fatalf("change proof failed: %v", err)
+	}
+	// A mismatched channel is rejected before the token is consumed.
+	for _, wrong := range []string{"", other} {
+		if _, err := verifier.VerifySecretChange(change, token, wrong); sdkerrors.CodeOf(err) != sdkerrors.ErrChannelMismatch.Code {
+			t.Fatalf("channel %q: expected E1016, got %v", wrong, err)
+		}
+	}
+	ok, err := verifier.VerifySecretChange(change, token, channel)
+	if err != nil || !ok {
+		t.Fatalf("change verification failed: %v", err)
+	}
+}
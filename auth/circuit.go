@@ -2,41 +2,53 @@ package auth
 
 import (
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/ghdehrl12345/identify_sdk/v2/age"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
 )
 
 // UserCircuit defines the ZKP circuit for password-less authentication.
+// The birth date is part of the registered commitment, so the age predicate
+// is evaluated against the date fixed at registration rather than a per-login value.
 type UserCircuit struct {
 	// Public inputs
 	PublicHash  frontend.Variable `gnark:",public"`
 	Binding     frontend.Variable `gnark:",public"`
 	Salt        frontend.Variable `gnark:",public"`
-	CurrentYear frontend.Variable `gnark:",public"`
+	CurrentDate frontend.Variable `gnark:",public"` // YYYYMMDD
 	LimitAge    frontend.Variable `gnark:",public"`
+	Mode        frontend.Variable `gnark:",public"` // 0 = international full age, 1 = Korean year-age
 	Challenge   frontend.Variable `gnark:",public"`
+	Channel     frontend.Variable `gnark:",public"` // channel binding, 0 when unused
 
 	// Private inputs
 	SecretKey frontend.Variable
-	BirthYear frontend.Variable
+	BirthDate frontend.Variable // YYYYMMDD
+
+	// Hash selects the commitment hash at compile time (hasher.MiMC when empty).
+	Hash string `gnark:"-"`
 }
 
 // Define implements the gnark circuit definition.
 func (circuit *UserCircuit) Define(api frontend.API) error {
-	// Commitment: H(secret, salt)
-	mimcHash, _ := mimc.NewMiMC(api)
-	mimcHash.Write(circuit.SecretKey, circuit.Salt)
-	commitment := mimcHash.Sum()
-	api.AssertIsEqual(commitment, circuit.PublicHash)
-
-	// Challenge binding: H(commitment, challenge)
-	mimcBind, _ := mimc.NewMiMC(api)
-	mimcBind.Write(circuit.PublicHash, circuit.Challenge)
-	binding := mimcBind.Sum()
-	api.AssertIsEqual(binding, circuit.Binding)
-
-	// Age verification: age >= limit
-	myAge := api.Sub(circuit.CurrentYear, circuit.BirthYear)
-	api.AssertIsLessOrEqual(circuit.LimitAge, myAge)
+	// Commitment: H(secret, salt, birthDate)
+	commitHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	commitHash.Write(circuit.SecretKey, circuit.Salt, circuit.BirthDate)
+	api.AssertIsEqual(commitHash.Sum(), circuit.PublicHash)
+
+	// Challenge binding: H(commitment, challenge, channel)
+	bindHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	bindHash.Write(circuit.PublicHash, circuit.Challenge, circuit.Channel)
+	api.AssertIsEqual(bindHash.Sum(), circuit.Binding)
+
+	// Age verification: age(birthDate, currentDate) >= limit
+	myAge := age.AgeAt(api, circuit.BirthDate, circuit.CurrentDate, circuit.Mode)
+	age.AssertAgeAtLeast(api, myAge, circuit.LimitAge)
 
 	return nil
 }
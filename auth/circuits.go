@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// Auth circuits selectable via Policy.Circuit and VerifierConfig.Circuit.
+const (
+	// CircuitAgeLogin is UserCircuit: login plus the age predicate (default).
+	CircuitAgeLogin = "age-login"
+	// CircuitLogin is LoginCircuit: login without an age predicate.
+	CircuitLogin = "login"
+	// CircuitChangeSecret is ChangeSecretCircuit (Groth16 only). It names the
+	// change-secret keys and proof version and is not a Policy.Circuit value.
+	CircuitChangeSecret = "change-secret"
+)
+
+// normalizeCircuit validates a circuit name; empty selects CircuitAgeLogin.
+func normalizeCircuit(name string) (string, error) {
+	switch name {
+	case "", CircuitAgeLogin:
+		return CircuitAgeLogin, nil
+	case CircuitLogin:
+		return CircuitLogin, nil
+	default:
+		return "", fmt.Errorf("unknown auth circuit %q", name)
+	}
+}
+
+func newCircuit(name string, scheme int) frontend.Circuit {
+	hash, _ := commitment.HashForScheme(scheme)
+	switch name {
+	case CircuitLogin:
+		return &LoginCircuit{Hash: hash}
+	case CircuitChangeSecret:
+		return &ChangeSecretCircuit{Hash: hash}
+	}
+	return &UserCircuit{Hash: hash}
+}
+
+// keyName returns the embedded key file name (without extension) for a circuit,
+// backend and commitment scheme, e.g. "user", "login_plonk" or "user_poseidon2_plonk".
+func keyName(name string, be string, scheme int) string {
+	out := "user"
+	switch name {
+	case CircuitLogin:
+		out = "login"
+	case CircuitChangeSecret:
+		out = "change_secret"
+	}
+	if scheme == commitment.SchemeV3 {
+		out += "_" + hasher.Poseidon2
+	}
+	if be == backend.PLONK {
+		out += "_plonk"
+	}
+	return out
+}
+
+// loadConstraintSystem returns the constraint system of a circuit variant from
+// its embedded serialized form, parsed once per process.
+func loadConstraintSystem(name string, be string, scheme int) (constraint.ConstraintSystem, error) {
+	return backend.LoadConstraintSystem(be, constraintSystems[keyName(name, be, scheme)], newCircuit(name, scheme))
+}
+
+func embeddedProvingKey(name string, be string, scheme int) []byte {
+	return provingKeys[keyName(name, be, scheme)]
+}
+
+func embeddedVerifyingKey(name string, be string, scheme int) []byte {
+	return verifyingKeys[keyName(name, be, scheme)]
+}
+
+// ProvingKeyIDFor returns the fingerprint of the embedded proving key for a circuit and backend.
+func ProvingKeyIDFor(name string, be string) string {
+	return ProvingKeyIDForScheme(name, be, commitment.SchemeV2)
+}
+
+// ProvingKeyIDForScheme is ProvingKeyIDFor for the circuit variant of a commitment scheme.
+func ProvingKeyIDForScheme(name string, be string, scheme int) string {
+	return provingKeyIDs[keyName(name, be, scheme)]
+}
+
+// VerifyingKeyIDFor returns the fingerprint of the embedded verifying key for a circuit and backend.
+func VerifyingKeyIDFor(name string, be string) string {
+	return VerifyingKeyIDForScheme(name, be, commitment.SchemeV2)
+}
+
+// VerifyingKeyIDForScheme is VerifyingKeyIDFor for the circuit variant of a commitment scheme.
+func VerifyingKeyIDForScheme(name string, be string, scheme int) string {
+	return verifyingKeyIDs[keyName(name, be, scheme)]
+}
+
+// ProofVersionFor returns the proof version for a circuit, suffixed "-poseidon2" for SchemeV3
+// and "-plonk" for PLONK.
+func ProofVersionFor(name string, be string, scheme int) string {
+	version := ProofVersion
+	switch name {
+	case CircuitLogin:
+		version = LoginProofVersion
+	case CircuitChangeSecret:
+		version = ChangeSecretProofVersion
+	}
+	if scheme == commitment.SchemeV3 {
+		version += "-" + hasher.Poseidon2
+	}
+	return backend.ProofVersion(version, be)
+}
+
+func keyIDs(keys map[string][]byte, sum func([]byte) string) map[string]string {
+	out := make(map[string]string, len(keys))
+	for name, data := range keys {
+		out[name] = sum(data)
+	}
+	return out
+}
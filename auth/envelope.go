@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// GenerateEnvelope creates a channel-bound login proof (see
+// GenerateProofWithChannel) and wraps it in a proof envelope with the
+// prover's params version.
+func (u *UserProver) GenerateEnvelope(secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, saltHex string) (envelope.Envelope, error) {
+	return u.GenerateEnvelopeContext(context.Background(), secret, birthDate, currentDate, limitAge, challenge, channel, saltHex)
+}
+
+// GenerateEnvelopeContext is GenerateEnvelope with a context (see GenerateProofContext).
+func (u *UserProver) GenerateEnvelopeContext(ctx context.Context, secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, saltHex string) (envelope.Envelope, error) {
+	proof, commit, _, err := u.GenerateProofWithChannelContext(ctx, secret, birthDate, currentDate, limitAge, challenge, channel, saltHex)
+	if err != nil {
+		return envelope.Envelope{}, err
+	}
+	return u.Envelope(proof, commit, saltHex, u.config), nil
+}
+
+// Envelope wraps a login proof made by this prover in a hex-encoded proof
+// envelope with the commitment and salt as public inputs. vk_id is the
+// verifying key matching the prover's circuit, backend and scheme and
+// params_version is taken from cfg, the policy the proof was made for.
+func (u *UserProver) Envelope(proof []byte, commitment string, saltHex string, cfg common.SharedConfig) envelope.Envelope {
+	return envelope.New(proof, ProofVersionFor(u.circuit, u.Backend(), u.scheme), u.VerifyingKeyID(), common.ParamsVersion(cfg), map[string]string{
+		envelope.InputCommitment: commitment,
+		envelope.InputSalt:       saltHex,
+	})
+}
+
+// VerifyingKeyID returns the fingerprint of the verifying key that checks this
+// prover's proofs: Policy.VKID, the embedded verifying key for an embedded
+// proving key, or the verifying key found next to the proving key by
+// NewUserProverFromDir. It is empty when none of these is known.
+func (u *UserProver) VerifyingKeyID() string {
+	return u.vkID
+}
+
+// ProofResultFromEnvelope validates a login proof envelope and converts it
+// into a ProofResult whose VKID is the envelope's verifying key ID. The
+// envelope must carry exactly the commitment and salt public inputs.
+func ProofResultFromEnvelope(env envelope.Envelope) (ProofResult, error) {
+	if err := env.Validate(); err != nil {
+		return ProofResult{}, err
+	}
+	if err := env.ExpectInputs(envelope.InputCommitment, envelope.InputSalt); err != nil {
+		return ProofResult{}, err
+	}
+	return ProofResult{
+		Proof:         env.Proof,
+		Commitment:    env.PublicInputs[envelope.InputCommitment],
+		Salt:          env.PublicInputs[envelope.InputSalt],
+		ProofVersion:  env.ProofVersion,
+		VKID:          env.VKID,
+		ParamsVersion: env.ParamsVersion,
+	}, nil
+}
+
+// VerifyEnvelope checks the envelope's proof_version (E4002), vk_id (E2004) and
+// params_version (E4002) against this verifier and verifies the login proof
+// for challenge and channel.
+func (v *Verifier) VerifyEnvelope(env envelope.Envelope, challenge string, channel string) (bool, error) {
+	return v.VerifyEnvelopeContext(context.Background(), env, challenge, channel)
+}
+
+// VerifyEnvelopeContext is VerifyEnvelope with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyEnvelopeContext(ctx context.Context, env envelope.Envelope, challenge string, channel string) (bool, error) {
+	res, err := v.envelopeResult(env)
+	if err != nil {
+		return false, err
+	}
+	return v.VerifyLoginWithChannelContext(ctx, res.Proof, res.Commitment, res.Salt, challenge, channel)
+}
+
+// VerifyEnvelopeWithToken is VerifyEnvelope for a stateless challenge token.
+func (v *Verifier) VerifyEnvelopeWithToken(env envelope.Envelope, challengeToken string, channel string) (bool, error) {
+	return v.VerifyEnvelopeWithTokenContext(context.Background(), env, challengeToken, channel)
+}
+
+// VerifyEnvelopeWithTokenContext is VerifyEnvelopeWithToken with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyEnvelopeWithTokenContext(ctx context.Context, env envelope.Envelope, challengeToken string, channel string) (bool, error) {
+	res, err := v.envelopeResult(env)
+	if err != nil {
+		return false, err
+	}
+	return v.VerifyLoginWithTokenAndChannelContext(ctx, res.Proof, res.Commitment, res.Salt, challengeToken, channel)
+}
+
+// envelopeResult decodes env and checks its metadata against the verifier.
+func (v *Verifier) envelopeResult(env envelope.Envelope) (ProofResult, error) {
+	res, err := ProofResultFromEnvelope(env)
+	if err != nil {
+		return ProofResult{}, err
+	}
+	if want := ProofVersionFor(v.circuit, v.verifyingKey.Backend, v.scheme); res.ProofVersion != want {
+		return ProofResult{}, sdkerrors.Wrap(sdkerrors.ErrPolicyMismatch.Code, "proof version mismatch", fmt.Errorf("got %q, want %q", res.ProofVersion, want))
+	}
+	if res.VKID != v.VerifyingKeyID() {
+		return ProofResult{}, sdkerrors.ErrKeyMismatch
+	}
+	if res.ParamsVersion != common.ParamsVersion(v.config) {
+		return ProofResult{}, sdkerrors.ErrPolicyMismatch
+	}
+	return res, nil
+}
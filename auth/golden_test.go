@@ -1,24 +1,29 @@
 package auth
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"os"
 	"testing"
 
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 )
 
 type authGolden struct {
-	Proof       string `json:"proof"`
-	Commitment  string `json:"commitment"`
-	Salt        string `json:"salt"`
-	Challenge   int    `json:"challenge"`
-	BirthYear   int    `json:"birth_year"`
-	TargetYear  int    `json:"target_year"`
-	LimitAge    int    `json:"limit_age"`
-	ParamsHash  string `json:"params_version"`
-	VerifyingID string `json:"vk_id"`
+	Proof       string            `json:"proof"`
+	Commitment  string            `json:"commitment"`
+	Salt        string            `json:"salt"`
+	Challenge   string            `json:"challenge"`
+	BirthDate   int               `json:"birth_date"`
+	TargetDate  int               `json:"target_date"`
+	AgeMode     string            `json:"age_mode"`
+	LimitAge    int               `json:"limit_age"`
+	ParamsHash  string            `json:"params_version"`
+	VerifyingID string            `json:"vk_id"`
+	Calldata    *backend.Calldata `json:"solidity_calldata"`
 }
 
 func TestGoldenProofAuth(t *testing.T) {
@@ -32,8 +37,9 @@ func TestGoldenProofAuth(t *testing.T) {
 		t.Fatalf("golden parse failed: %v", err)
 	}
 
-	cfg := common.DefaultSharedConfig()
-	if g.TargetYear != cfg.TargetYear || g.LimitAge != cfg.LimitAge {
+	cfg := common.DefaultSharedConfigWithDate(g.TargetDate)
+	cfg.AgeMode = g.AgeMode
+	if g.LimitAge != cfg.LimitAge {
 		t.Fatalf("golden policy mismatch")
 	}
 	if g.VerifyingID != VerifyingKeyID() {
@@ -57,4 +63,44 @@ func TestGoldenProofAuth(t *testing.T) {
 	if err != nil || !ok {
 		t.Fatalf("golden verification failed: %v", err)
 	}
+
+	calldata, err := verifier.SolidityCalldata(ProofResult{Proof: proofBytes, Commitment: g.Commitment, Salt: g.Salt}, g.Challenge, "")
+	if err != nil {
+		t.Fatalf("solidity calldata failed: %v", err)
+	}
+	if g.Calldata == nil || !bytes.Equal(calldata.Encode(), g.Calldata.Encode()) {
+		t.Fatalf("solidity calldata does not match golden vector")
+	}
+}
+
+// TestEmbeddedConstraintSystems fails when a circuit changed without
+// regenerating the shipped constraint systems (go run ./cmd/setup -ccs-only).
+func TestEmbeddedConstraintSystems(t *testing.T) {
+	for _, c := range []struct {
+		circuit string
+		backend string
+		scheme  int
+	}{
+		{CircuitAgeLogin, backend.Groth16, commitment.SchemeV2},
+		{CircuitLogin, backend.Groth16, commitment.SchemeV2},
+		{CircuitAgeLogin, backend.PLONK, commitment.SchemeV2},
+		{CircuitLogin, backend.PLONK, commitment.SchemeV2},
+		{CircuitAgeLogin, backend.Groth16, commitment.SchemeV3},
+		{CircuitLogin, backend.Groth16, commitment.SchemeV3},
+		{CircuitAgeLogin, backend.PLONK, commitment.SchemeV3},
+		{CircuitLogin, backend.PLONK, commitment.SchemeV3},
+		{CircuitChangeSecret, backend.Groth16, commitment.SchemeV2},
+		{CircuitChangeSecret, backend.Groth16, commitment.SchemeV3},
+	} {
+		name := keyName(c.circuit, c.backend, c.scheme)
+		ccs, err := backend.Compile(c.backend, newCircuit(c.circuit, c.scheme))
+		if err != nil {
+			t.Fatalf("%s compile: %v", name, err)
+		}
+		var buf bytes.Buffer
+		ccs.WriteTo(&buf)
+		if !bytes.Equal(buf.Bytes(), constraintSystems[name]) {
+			t.Fatalf("%s.ccs is stale", name)
+		}
+	}
 }
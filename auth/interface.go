@@ -4,10 +4,10 @@ import "github.com/ghdehrl12345/identify_sdk/v2/common"
 
 // Authenticator defines the interface for ZKP-based authentication.
 type Authenticator interface {
-	// CreateCommitment derives a salted commitment from a user-secret for storage.
-	CreateCommitment(secret string) (commitment string, salt string, err error)
+	// CreateCommitment derives a salted commitment from a user-secret and birth date (YYYYMMDD) for storage.
+	CreateCommitment(secret string, birthDate int) (commitment string, salt string, err error)
 	// VerifyLogin checks whether a Groth16 proof matches the stored commitment/salt and the server-issued challenge.
-	VerifyLogin(proof []byte, publicCommitment string, salt string, challenge int) (bool, error)
+	VerifyLogin(proof []byte, publicCommitment string, salt string, challenge string) (bool, error)
 	// GetConfig returns the shared configuration (policy/KDF parameters).
 	GetConfig() common.SharedConfig
 }
@@ -18,9 +18,17 @@ type StatelessAuthenticator interface {
 	VerifyLoginWithToken(proof []byte, publicCommitment string, salt string, challengeToken string) (bool, error)
 }
 
+// ChannelAuthenticator verifies proofs bound to the requesting channel or session.
+type ChannelAuthenticator interface {
+	// VerifyLoginWithChannel verifies a proof whose binding includes the expected channel binding.
+	VerifyLoginWithChannel(proof []byte, publicCommitment string, salt string, challenge string, channel string) (bool, error)
+	// VerifyLoginWithTokenAndChannel validates a challenge token and verifies a channel-bound proof.
+	VerifyLoginWithTokenAndChannel(proof []byte, publicCommitment string, salt string, challengeToken string, channel string) (bool, error)
+}
+
 // MetaAuthenticator enforces metadata matching for vk_id and params_version.
 type MetaAuthenticator interface {
-	VerifyLoginWithMeta(proof []byte, publicCommitment string, salt string, challenge int, vkID string, paramsVersion string) (bool, error)
+	VerifyLoginWithMeta(proof []byte, publicCommitment string, salt string, challenge string, vkID string, paramsVersion string) (bool, error)
 }
 
 // PolicyProvider exposes policy metadata for client sync.
@@ -30,8 +38,8 @@ type PolicyProvider interface {
 
 // Prover defines the interface for generating ZKP proofs on the client side.
 type Prover interface {
-	// CalculateCommitment derives a commitment with a random salt.
-	CalculateCommitment(secret string) (commitment string, salt string, err error)
-	// GenerateProof creates a Groth16 proof for authentication.
-	GenerateProof(secret string, birthYear int, currentYear int, limitAge int, challenge int, saltHex string) (proof []byte, commitment string, binding string, err error)
+	// CalculateCommitment derives a commitment over secret and birth date (YYYYMMDD) with a random salt.
+	CalculateCommitment(secret string, birthDate int) (commitment string, salt string, err error)
+	// GenerateProof creates a Groth16 proof for authentication using the birth date committed at registration.
+	GenerateProof(secret string, birthDate int, currentDate int, limitAge int, challenge string, saltHex string) (proof []byte, commitment string, binding string, err error)
 }
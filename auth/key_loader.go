@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// NewVerifierFromVK creates a verifier from verifying key bytes, e.g. from a
+// custom setup ceremony. The key must belong to the circuit, backend and
+// scheme selected by cfg; cfg.ExpectedVK, when set, must equal its
+// fingerprint (E2004). VerifySecretChange needs cfg.ChangeSecretVK from the
+// same setup; the embedded change-secret key is not used.
+func NewVerifierFromVK(vkBytes []byte, cfg VerifierConfig) (*Verifier, error) {
+	return newVerifier(vkBytes, nil, cfg)
+}
+
+// NewVerifierFromFile is NewVerifierFromVK for a verifying key file.
+func NewVerifierFromFile(path string, cfg VerifierConfig) (*Verifier, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifier(data, nil, cfg)
+}
+
+// NewVerifierFromDir creates a verifier from a key directory written by
+// identify-cli generate-keys. It loads the verifying key named after cfg's
+// circuit, backend and scheme (user.vk, login_plonk.vk, ...) and, when
+// present, the matching change-secret verifying key (change_secret.vk,
+// change_secret_poseidon2.vk). Without it VerifySecretChange fails with E1004
+// unless cfg.ChangeSecretVK is set.
+func NewVerifierFromDir(dir string, cfg VerifierConfig) (*Verifier, error) {
+	circuitName, backendName, scheme, err := verifierSelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readKeyFile(filepath.Join(dir, keyName(circuitName, backendName, scheme)+".vk"))
+	if err != nil {
+		return nil, err
+	}
+	changeData, err := readOptionalKeyFile(filepath.Join(dir, keyName(CircuitChangeSecret, backend.Groth16, scheme)+".vk"))
+	if err != nil {
+		return nil, err
+	}
+	return newVerifier(data, changeData, cfg)
+}
+
+// NewUserProverFromFile is NewUserProverFromPKWithPolicy for a proving key file.
+func NewUserProverFromFile(path string, policy Policy, cfg common.SharedConfig) (*UserProver, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProverFromPKWithPolicy(data, policy, cfg)
+}
+
+// NewUserProverFromDir creates a prover from a key directory written by
+// identify-cli generate-keys. It loads the proving key named after policy's
+// circuit, backend and scheme and, when present, the matching change-secret
+// proving key. The fingerprint of the verifying key next to the proving key
+// becomes the prover's VerifyingKeyID unless policy.VKID is set.
+func NewUserProverFromDir(dir string, policy Policy, cfg common.SharedConfig) (*UserProver, error) {
+	circuitName, err := normalizeCircuit(policy.Circuit)
+	if err != nil {
+		return nil, err
+	}
+	backendName, err := backend.Normalize(policy.Backend)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := commitment.NormalizeScheme(policy.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	name := keyName(circuitName, backendName, scheme)
+	data, err := readKeyFile(filepath.Join(dir, name+".pk"))
+	if err != nil {
+		return nil, err
+	}
+	if policy.VKID == "" {
+		vkData, err := readOptionalKeyFile(filepath.Join(dir, name+".vk"))
+		if err != nil {
+			return nil, err
+		}
+		if vkData != nil {
+			policy.VKID = backend.KeyID(vkData)
+		}
+	}
+	changeData, err := readOptionalKeyFile(filepath.Join(dir, keyName(CircuitChangeSecret, backend.Groth16, scheme)+".pk"))
+	if err != nil {
+		return nil, err
+	}
+	u, err := NewUserProverFromPKWithPolicy(data, policy, cfg)
+	if err != nil {
+		return nil, err
+	}
+	u.changePKData = changeData
+	return u, nil
+}
+
+// readKeyFile reads a key file, reporting failures as E2003.
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyRead.Code, "key file read failed", err)
+	}
+	return data, nil
+}
+
+// readOptionalKeyFile is readKeyFile returning nil when the file does not exist.
+func readOptionalKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyRead.Code, "key file read failed", err)
+	}
+	return data, nil
+}
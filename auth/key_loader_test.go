@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestKeyLoading(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"login.pk", "login.vk"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s failed: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	cfg := common.DefaultSharedConfig()
+	policy := DefaultPolicy()
+	policy.Circuit = CircuitLogin
+	prover, err := NewUserProverFromDir(dir, policy, cfg)
+	if err != nil {
+		t.Fatalf("prover from dir failed: %v", err)
+	}
+	if prover.ProvingKeyID() != LoginProvingKeyID() || prover.VerifyingKeyID() != LoginVerifyingKeyID() {
+		t.Fatalf("unexpected key ids: %s %s", prover.ProvingKeyID(), prover.VerifyingKeyID())
+	}
+	verifier, err := NewVerifierFromDir(dir, VerifierConfig{Config: cfg, Circuit: CircuitLogin, ExpectedVK: LoginVerifyingKeyID()})
+	if err != nil {
+		t.Fatalf("verifier from dir failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	proof, commit, _, err := prover.GenerateProof("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, "55", salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyLogin(proof, commit, salt, "55"); err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+
+	// Without change_secret.vk in the directory, secret changes are refused
+	// rather than checked against the embedded key.
+	if _, err := verifier.VerifySecretChange(SecretChange{}, "", ""); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyNotFound.Code {
+		t.Fatalf("expected E1004 without a change-secret key, got %v", err)
+	}
+	changeVK, err := os.ReadFile("change_secret.vk")
+	if err != nil {
+		t.Fatalf("read change_secret.vk failed: %v", err)
+	}
+	withChange, err := NewVerifierFromDir(dir, VerifierConfig{Config: cfg, Circuit: CircuitLogin, ChangeSecretVK: changeVK, ExpectedChangeSecretVK: backend.KeyID(changeVK)})
+	if err != nil {
+		t.Fatalf("verifier with change-secret key failed: %v", err)
+	}
+	if _, err := withChange.VerifySecretChange(SecretChange{}, "", ""); sdkerrors.CodeOf(err) == sdkerrors.ErrKeyNotFound.Code {
+		t.Fatalf("change-secret key not used: %v", err)
+	}
+	if _, err := NewVerifierFromDir(dir, VerifierConfig{Config: cfg, Circuit: CircuitLogin, ChangeSecretVK: changeVK, ExpectedChangeSecretVK: VerifyingKeyID()}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected E2004 for a change-secret key mismatch, got %v", err)
+	}
+
+	// Fingerprint, read and parse failures carry error codes.
+	if _, err := NewVerifierFromFile("login.vk", VerifierConfig{Config: cfg, Circuit: CircuitLogin, ExpectedVK: VerifyingKeyID()}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected E2004, got %v", err)
+	}
+	policy.ExpectedPK = ProvingKeyID()
+	if _, err := NewUserProverFromFile("login.pk", policy, cfg); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected E2004, got %v", err)
+	}
+	if _, err := NewVerifierFromDir(dir, VerifierConfig{Config: cfg}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyRead.Code {
+		t.Fatalf("expected E2003 for missing user.vk, got %v", err)
+	}
+	if _, err := NewVerifierFromVK([]byte("not a key"), VerifierConfig{Config: cfg}); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyParse.Code {
+		t.Fatalf("expected E2001, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ type KeyVersion struct {
 	Version   string    // Semantic version (e.g., "v1.0.0")
 	VKID      string    // Verifying key fingerprint
 	PKID      string    // Proving key fingerprint
+	Backend   string    // Proving backend (backend.Groth16 when empty)
 	CreatedAt time.Time // When this version was created
 	ExpiresAt time.Time // When this version expires (0 = never)
 	IsActive  bool      // Whether this is the current active version
@@ -38,6 +39,7 @@ type KeyManager interface {
 type MemoryKeyManager struct {
 	versions      map[string]*KeyVersion
 	activeVersion string
+	now           func() time.Time // clock for expiry checks; time.Now by default
 	mu            sync.RWMutex
 }
 
@@ -45,6 +47,7 @@ type MemoryKeyManager struct {
 func NewMemoryKeyManager() *MemoryKeyManager {
 	return &MemoryKeyManager{
 		versions: make(map[string]*KeyVersion),
+		now:      time.Now,
 	}
 }
 
@@ -97,7 +100,7 @@ func (m *MemoryKeyManager) IsVersionValid(vkID string) bool {
 		return false
 	}
 	// Check expiration
-	if !v.ExpiresAt.IsZero() && time.Now().After(v.ExpiresAt) {
+	if !v.ExpiresAt.IsZero() && m.now().After(v.ExpiresAt) {
 		return false
 	}
 	return true
@@ -154,7 +157,7 @@ func (m *MemoryKeyManager) DeprecateVersion(vkID string) error {
 	}
 
 	// Set expiration to 30 days from now for graceful deprecation
-	v.ExpiresAt = time.Now().Add(30 * 24 * time.Hour)
+	v.ExpiresAt = m.now().Add(30 * 24 * time.Hour)
 	return nil
 }
 
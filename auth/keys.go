@@ -1,6 +1,10 @@
 package auth
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+)
 
 // ProvingKeyBytes returns a copy of the embedded proving key bytes.
 func ProvingKeyBytes() []byte {
@@ -13,3 +17,33 @@ func ProvingKeyBytes() []byte {
 func ProvingKeyBase64() string {
 	return base64.StdEncoding.EncodeToString(provingKeyData)
 }
+
+// LoginProvingKeyBytes returns a copy of the embedded login-only proving key bytes.
+func LoginProvingKeyBytes() []byte {
+	out := make([]byte, len(loginProvingKeyData))
+	copy(out, loginProvingKeyData)
+	return out
+}
+
+// LoginProvingKeyBase64 returns the embedded login-only proving key as a base64 string.
+func LoginProvingKeyBase64() string {
+	return base64.StdEncoding.EncodeToString(loginProvingKeyData)
+}
+
+// ProvingKeyBytesFor returns a copy of the embedded proving key for a circuit and backend.
+func ProvingKeyBytesFor(circuit string, backend string) []byte {
+	data := embeddedProvingKey(circuit, backend, commitment.SchemeV2)
+	out := make([]byte, len(data))
+	copy(out, data)
+	return out
+}
+
+// ProvingKeyBase64For returns the embedded proving key for a circuit and backend as a base64 string.
+func ProvingKeyBase64For(circuit string, backend string) string {
+	return base64.StdEncoding.EncodeToString(embeddedProvingKey(circuit, backend, commitment.SchemeV2))
+}
+
+// ProvingKeyBase64ForScheme is ProvingKeyBase64For for the circuit variant of a commitment scheme.
+func ProvingKeyBase64ForScheme(circuit string, backend string, scheme int) string {
+	return base64.StdEncoding.EncodeToString(embeddedProvingKey(circuit, backend, scheme))
+}
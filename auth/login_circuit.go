@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// LoginCircuit proves knowledge of the secret behind a registered commitment
+// without any age predicate. The birth date stays a private part of the
+// commitment preimage (commitment.NoBirthDate when none was registered).
+type LoginCircuit struct {
+	// Public inputs
+	PublicHash frontend.Variable `gnark:",public"`
+	Binding    frontend.Variable `gnark:",public"`
+	Salt       frontend.Variable `gnark:",public"`
+	Challenge  frontend.Variable `gnark:",public"`
+	Channel    frontend.Variable `gnark:",public"` // channel binding, 0 when unused
+
+	// Private inputs
+	SecretKey frontend.Variable
+	BirthDate frontend.Variable
+
+	// Hash selects the commitment hash at compile time (hasher.MiMC when empty).
+	Hash string `gnark:"-"`
+}
+
+// Define implements the gnark circuit definition.
+func (circuit *LoginCircuit) Define(api frontend.API) error {
+	// Commitment: H(secret, salt, birthDate)
+	commitHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	commitHash.Write(circuit.SecretKey, circuit.Salt, circuit.BirthDate)
+	api.AssertIsEqual(commitHash.Sum(), circuit.PublicHash)
+
+	// Challenge binding: H(commitment, challenge, channel)
+	bindHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	bindHash.Write(circuit.PublicHash, circuit.Challenge, circuit.Channel)
+	api.AssertIsEqual(bindHash.Sum(), circuit.Binding)
+
+	return nil
+}
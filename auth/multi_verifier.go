@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// MultiVerifier holds several verifiers keyed by the vk_id of their verifying
+// key and routes each proof to the verifier named by the proof's vk_id. A key
+// is accepted while KeyManager.IsVersionValid reports it valid, so during a
+// rotation proofs made with a deprecated key keep verifying until its grace
+// period ends. It is safe for concurrent use.
+//
+// Challenge tokens are validated by the selected verifier and must therefore
+// be issued for the vk_id the client proves with.
+type MultiVerifier struct {
+	manager   KeyManager
+	mu        sync.RWMutex
+	verifiers map[string]*Verifier
+}
+
+// NewMultiVerifier creates a multi-key verifier backed by manager and adds the
+// given verifiers (see Add).
+func NewMultiVerifier(manager KeyManager, verifiers ...*Verifier) (*MultiVerifier, error) {
+	if manager == nil {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "key manager is required")
+	}
+	m := &MultiVerifier{manager: manager, verifiers: make(map[string]*Verifier)}
+	for _, v := range verifiers {
+		if err := m.Add(v); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add registers v under its VerifyingKeyID. The key version must already be
+// registered with the key manager.
+func (m *MultiVerifier) Add(v *Verifier) error {
+	vkID := v.VerifyingKeyID()
+	if _, err := m.manager.GetVersion(vkID); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrKeyNotFound.Code, "key version not registered", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.verifiers[vkID]; exists {
+		return fmt.Errorf("verifier already added: %s", vkID)
+	}
+	m.verifiers[vkID] = v
+	return nil
+}
+
+// Remove drops the verifier for vkID, e.g. once its key version has expired.
+func (m *MultiVerifier) Remove(vkID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.verifiers, vkID)
+}
+
+// VKIDs returns the vk_ids of the held verifiers.
+func (m *MultiVerifier) VKIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.verifiers))
+	for vkID := range m.verifiers {
+		out = append(out, vkID)
+	}
+	return out
+}
+
+// Verifier returns the verifier and key version for vkID. An empty vkID
+// selects the active version. Unknown keys fail with E2004 and keys the
+// manager no longer accepts with E2006.
+func (m *MultiVerifier) Verifier(vkID string) (*Verifier, KeyVersion, error) {
+	if vkID == "" {
+		active, err := m.manager.GetActiveVersion()
+		if err != nil {
+			return nil, KeyVersion{}, sdkerrors.Wrap(sdkerrors.ErrKeyNotFound.Code, "no active key version", err)
+		}
+		vkID = active.VKID
+	}
+	m.mu.RLock()
+	v, ok := m.verifiers[vkID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, KeyVersion{}, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "no verifier for vk_id", fmt.Errorf("%q", vkID))
+	}
+	if !m.manager.IsVersionValid(vkID) {
+		return nil, KeyVersion{}, sdkerrors.Wrap(sdkerrors.ErrKeyRotation.Code, "key version no longer valid", fmt.Errorf("%q", vkID))
+	}
+	version, err := m.manager.GetVersion(vkID)
+	if err != nil {
+		return nil, KeyVersion{}, sdkerrors.Wrap(sdkerrors.ErrKeyNotFound.Code, "key version not registered", err)
+	}
+	return v, *version, nil
+}
+
+// VerifyLogin verifies a channel-bound login proof with the verifier for vkID
+// and returns the key version that verified it.
+func (m *MultiVerifier) VerifyLogin(proofBytes []byte, publicCommitment string, salt string, challenge string, channel string, vkID string) (bool, KeyVersion, error) {
+	return m.VerifyLoginContext(context.Background(), proofBytes, publicCommitment, salt, challenge, channel, vkID)
+}
+
+// VerifyLoginContext is VerifyLogin with a context (see Verifier.VerifyLoginContext).
+func (m *MultiVerifier) VerifyLoginContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challenge string, channel string, vkID string) (bool, KeyVersion, error) {
+	v, version, err := m.Verifier(vkID)
+	if err != nil {
+		return false, KeyVersion{}, err
+	}
+	ok, err := v.VerifyLoginWithChannelContext(ctx, proofBytes, publicCommitment, salt, challenge, channel)
+	return ok, version, err
+}
+
+// VerifyLoginWithToken is VerifyLogin for a stateless challenge token.
+func (m *MultiVerifier) VerifyLoginWithToken(proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string, vkID string) (bool, KeyVersion, error) {
+	return m.VerifyLoginWithTokenContext(context.Background(), proofBytes, publicCommitment, salt, challengeToken, channel, vkID)
+}
+
+// VerifyLoginWithTokenContext is VerifyLoginWithToken with a context.
+func (m *MultiVerifier) VerifyLoginWithTokenContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string, vkID string) (bool, KeyVersion, error) {
+	v, version, err := m.Verifier(vkID)
+	if err != nil {
+		return false, KeyVersion{}, err
+	}
+	ok, err := v.VerifyLoginWithTokenAndChannelContext(ctx, proofBytes, publicCommitment, salt, challengeToken, channel)
+	return ok, version, err
+}
+
+// VerifyEnvelope routes a proof envelope by its vk_id and verifies it (see
+// Verifier.VerifyEnvelope).
+func (m *MultiVerifier) VerifyEnvelope(env envelope.Envelope, challenge string, channel string) (bool, KeyVersion, error) {
+	return m.VerifyEnvelopeContext(context.Background(), env, challenge, channel)
+}
+
+// VerifyEnvelopeContext is VerifyEnvelope with a context.
+func (m *MultiVerifier) VerifyEnvelopeContext(ctx context.Context, env envelope.Envelope, challenge string, channel string) (bool, KeyVersion, error) {
+	v, version, err := m.Verifier(env.VKID)
+	if err != nil {
+		return false, KeyVersion{}, err
+	}
+	ok, err := v.VerifyEnvelopeContext(ctx, env, challenge, channel)
+	return ok, version, err
+}
+
+// VerifyEnvelopeWithToken is VerifyEnvelope for a stateless challenge token.
+func (m *MultiVerifier) VerifyEnvelopeWithToken(env envelope.Envelope, challengeToken string, channel string) (bool, KeyVersion, error) {
+	return m.VerifyEnvelopeWithTokenContext(context.Background(), env, challengeToken, channel)
+}
+
+// VerifyEnvelopeWithTokenContext is VerifyEnvelopeWithToken with a context.
+func (m *MultiVerifier) VerifyEnvelopeWithTokenContext(ctx context.Context, env envelope.Envelope, challengeToken string, channel string) (bool, KeyVersion, error) {
+	v, version, err := m.Verifier(env.VKID)
+	if err != nil {
+		return false, KeyVersion{}, err
+	}
+	ok, err := v.VerifyEnvelopeWithTokenContext(ctx, env, challengeToken, channel)
+	return ok, version, err
+}
+
+// VerifyLoginWithTokenResult routes a login proof by vkID like
+// VerifyLoginWithToken and reports a common.VerificationResult; routing
+// failures are reported at the policy stage.
+func (m *MultiVerifier) VerifyLoginWithTokenResult(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string, vkID string) common.VerificationResult {
+	start := time.Now()
+	v, _, err := m.Verifier(vkID)
+	if err != nil {
+		return common.NewVerificationResult(start, nil, err)
+	}
+	return v.VerifyLoginWithTokenResult(ctx, proofBytes, publicCommitment, salt, challengeToken, channel)
+}
+
+// VerifyEnvelopeWithTokenResult routes a proof envelope by its vk_id like
+// VerifyEnvelopeWithToken and reports a common.VerificationResult.
+func (m *MultiVerifier) VerifyEnvelopeWithTokenResult(ctx context.Context, env envelope.Envelope, challengeToken string, channel string) common.VerificationResult {
+	start := time.Now()
+	v, _, err := m.Verifier(env.VKID)
+	if err != nil {
+		return common.NewVerificationResult(start, nil, err)
+	}
+	return v.VerifyEnvelopeWithTokenResult(ctx, env, challengeToken, channel)
+}
+
+// PolicyBundle returns the policy bundle of the active key version, so new
+// clients prove with the current key.
+func (m *MultiVerifier) PolicyBundle() PolicyBundle {
+	v, _, err := m.Verifier("")
+	if err != nil {
+		return PolicyBundle{}
+	}
+	return v.PolicyBundle()
+}
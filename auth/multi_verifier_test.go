@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestMultiVerifierRotation(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	oldVerifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	newVerifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Backend: backend.PLONK})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	manager := NewMemoryKeyManager()
+	oldID, newID := oldVerifier.VerifyingKeyID(), newVerifier.VerifyingKeyID()
+	for _, kv := range []KeyVersion{
+		{Version: "v1.0.0", VKID: oldID, Backend: backend.Groth16, CreatedAt: time.Now()},
+		{Version: "v2.0.0", VKID: newID, Backend: backend.PLONK, CreatedAt: time.Now()},
+	} {
+		if err := manager.RegisterVersion(kv); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+	}
+	multi, err := NewMultiVerifier(manager, oldVerifier, newVerifier)
+	if err != nil {
+		t.Fatalf("multi verifier init failed: %v", err)
+	}
+
+	// Rotate: the PLONK key becomes active and the Groth16 key enters its grace period.
+	if err := manager.SetActiveVersion(newID); err != nil {
+		t.Fatalf("activate failed: %v", err)
+	}
+	if err := manager.DeprecateVersion(oldID); err != nil {
+		t.Fatalf("deprecate failed: %v", err)
+	}
+	if bundle := multi.PolicyBundle(); bundle.VKID != newID || bundle.Backend != backend.PLONK {
+		t.Fatalf("expected active PLONK bundle, got %s %s", bundle.VKID, bundle.Backend)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "9191"
+	env, err := prover.GenerateEnvelope("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, "", salt)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	ok, version, err := multi.VerifyEnvelope(env, challenge, "")
+	if err != nil || !ok {
+		t.Fatalf("grace-period proof rejected: %v", err)
+	}
+	if version.Version != "v1.0.0" || version.VKID != oldID {
+		t.Fatalf("unexpected verifying version: %+v", version)
+	}
+
+	// A ProofResult names the verifying key, so its vk_id routes the proof.
+	res, err := prover.GenerateProofResult("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if res.VKID != oldID {
+		t.Fatalf("proof result vk_id %s, expected %s", res.VKID, oldID)
+	}
+	if ok, err := oldVerifier.VerifyLoginWithMeta(res.Proof, res.Commitment, res.Salt, challenge, res.VKID, res.ParamsVersion); err != nil || !ok {
+		t.Fatalf("proof result rejected with its metadata: %v", err)
+	}
+	if ok, version, err := multi.VerifyLogin(res.Proof, res.Commitment, res.Salt, challenge, "", res.VKID); err != nil || !ok || version.VKID != oldID {
+		t.Fatalf("proof result not routed by its vk_id: %+v %v", version, err)
+	}
+
+	// The proof does not verify under the other key.
+	if _, _, err := multi.VerifyLogin(env.Proof, env.PublicInputs["commitment"], salt, challenge, "", newID); err == nil {
+		t.Fatalf("expected proof to fail under the new key")
+	}
+	if _, _, err := multi.VerifyLogin(env.Proof, env.PublicInputs["commitment"], salt, challenge, "", "unknown"); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyMismatch.Code {
+		t.Fatalf("expected unknown vk_id rejection, got %v", err)
+	}
+
+	// After the grace period the old key is refused.
+	manager.now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+	if _, _, err := multi.VerifyEnvelope(env, challenge, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrKeyRotation.Code {
+		t.Fatalf("expected expired key rejection, got %v", err)
+	}
+}
@@ -10,6 +10,11 @@ type PolicyBundle struct {
 	Config        common.SharedConfig `json:"config"`
 	ParamsVersion string              `json:"params_version"`
 	VKID          string              `json:"vk_id"`
+	AgeMode       string              `json:"age_mode"`
+	Circuit       string              `json:"circuit"`       // CircuitAgeLogin or CircuitLogin
+	Backend       string              `json:"backend"`       // backend.Groth16 or backend.PLONK
+	Scheme        int                 `json:"scheme"`        // commitment scheme: 2 (MiMC) or 3 (Poseidon2)
+	ProofVersion  string              `json:"proof_version"` // ProofVersion or LoginProofVersion, "-poseidon2" / "-plonk" suffixed
 }
 
 // EnforcePolicy checks vk_id and params_version against the server bundle.
@@ -3,21 +3,28 @@ package auth
 import "github.com/ghdehrl12345/identify_sdk/v2/common"
 
 // ProofVersion is the semantic version for authentication proofs.
-const ProofVersion = "auth-proof-v1"
+const ProofVersion = "auth-proof-v4"
 
-// ProofResult contains proof bytes and metadata for wire transfer.
+// LoginProofVersion is the semantic version for login-only proofs (CircuitLogin).
+const LoginProofVersion = "auth-login-proof-v2"
+
+// ChangeSecretProofVersion is the semantic version for change-secret proofs (CircuitChangeSecret).
+const ChangeSecretProofVersion = "auth-change-secret-proof-v1"
+
+// ProofResult contains proof bytes and metadata for wire transfer. Envelope
+// converts it into the canonical proof envelope.
 type ProofResult struct {
-	Proof         []byte
-	Commitment    string
-	Salt          string
-	ProofVersion  string
-	VKID          string
-	ParamsVersion string
+	Proof         []byte `json:"proof"`
+	Commitment    string `json:"commitment"`
+	Salt          string `json:"salt"`
+	ProofVersion  string `json:"proof_version"`
+	VKID          string `json:"vk_id"`
+	ParamsVersion string `json:"params_version"`
 }
 
 // GenerateProofResult creates a proof and attaches metadata for integration flows.
-func (u *UserProver) GenerateProofResult(secret string, birthYear int, currentYear int, limitAge int, challenge int, saltHex string) (ProofResult, error) {
-	proof, commitment, _, err := u.GenerateProof(secret, birthYear, currentYear, limitAge, challenge, saltHex)
+func (u *UserProver) GenerateProofResult(secret string, birthDate int, currentDate int, limitAge int, challenge string, saltHex string) (ProofResult, error) {
+	proof, commitment, _, err := u.GenerateProof(secret, birthDate, currentDate, limitAge, challenge, saltHex)
 	if err != nil {
 		return ProofResult{}, err
 	}
@@ -25,8 +32,8 @@ func (u *UserProver) GenerateProofResult(secret string, birthYear int, currentYe
 		Proof:         proof,
 		Commitment:    commitment,
 		Salt:          saltHex,
-		ProofVersion:  ProofVersion,
-		VKID:          ProvingKeyID(),
+		ProofVersion:  ProofVersionFor(u.circuit, u.Backend(), u.scheme),
+		VKID:          u.VerifyingKeyID(),
 		ParamsVersion: common.ParamsVersion(u.config),
 	}, nil
 }
@@ -1,48 +1,161 @@
 package auth
 
 import (
-	"bytes"
+	"context"
 	"crypto/rand"
 	_ "embed"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync"
 
-	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
 	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
-	"golang.org/x/crypto/blake2b"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
 //go:embed user.pk
 var provingKeyData []byte
 
+//go:embed login.pk
+var loginProvingKeyData []byte
+
+//go:embed user_plonk.pk
+var plonkProvingKeyData []byte
+
+//go:embed login_plonk.pk
+var loginPlonkProvingKeyData []byte
+
+//go:embed user_poseidon2.pk
+var poseidon2ProvingKeyData []byte
+
+//go:embed login_poseidon2.pk
+var loginPoseidon2ProvingKeyData []byte
+
+//go:embed user_poseidon2_plonk.pk
+var poseidon2PlonkProvingKeyData []byte
+
+//go:embed login_poseidon2_plonk.pk
+var loginPoseidon2PlonkProvingKeyData []byte
+
+//go:embed change_secret.pk
+var changeSecretProvingKeyData []byte
+
+//go:embed change_secret_poseidon2.pk
+var changeSecretPoseidon2ProvingKeyData []byte
+
+//go:embed user.ccs
+var userCCSData []byte
+
+//go:embed login.ccs
+var loginCCSData []byte
+
+//go:embed user_plonk.ccs
+var userPlonkCCSData []byte
+
+//go:embed login_plonk.ccs
+var loginPlonkCCSData []byte
+
+//go:embed user_poseidon2.ccs
+var userPoseidon2CCSData []byte
+
+//go:embed login_poseidon2.ccs
+var loginPoseidon2CCSData []byte
+
+//go:embed user_poseidon2_plonk.ccs
+var userPoseidon2PlonkCCSData []byte
+
+//go:embed login_poseidon2_plonk.ccs
+var loginPoseidon2PlonkCCSData []byte
+
+//go:embed change_secret.ccs
+var changeSecretCCSData []byte
+
+//go:embed change_secret_poseidon2.ccs
+var changeSecretPoseidon2CCSData []byte
+
+// constraintSystems maps key file names to the serialized constraint systems
+// cmd/setup writes next to the proving keys, so provers skip compiling.
+var constraintSystems = map[string][]byte{
+	"user":                  userCCSData,
+	"login":                 loginCCSData,
+	"user_plonk":            userPlonkCCSData,
+	"login_plonk":           loginPlonkCCSData,
+	"user_poseidon2":        userPoseidon2CCSData,
+	"login_poseidon2":       loginPoseidon2CCSData,
+	"user_poseidon2_plonk":  userPoseidon2PlonkCCSData,
+	"login_poseidon2_plonk": loginPoseidon2PlonkCCSData,
+
+	"change_secret":           changeSecretCCSData,
+	"change_secret_poseidon2": changeSecretPoseidon2CCSData,
+}
+
+// provingKeys maps key file names (see keyName) to the embedded proving keys.
+var provingKeys = map[string][]byte{
+	"user":                  provingKeyData,
+	"login":                 loginProvingKeyData,
+	"user_plonk":            plonkProvingKeyData,
+	"login_plonk":           loginPlonkProvingKeyData,
+	"user_poseidon2":        poseidon2ProvingKeyData,
+	"login_poseidon2":       loginPoseidon2ProvingKeyData,
+	"user_poseidon2_plonk":  poseidon2PlonkProvingKeyData,
+	"login_poseidon2_plonk": loginPoseidon2PlonkProvingKeyData,
+
+	"change_secret":           changeSecretProvingKeyData,
+	"change_secret_poseidon2": changeSecretPoseidon2ProvingKeyData,
+}
+
+var provingKeyIDs = keyIDs(provingKeys, backend.KeyID)
+
 // EmbeddedProvingKeyID is the blake2b-256 fingerprint of the embedded proving key.
-var EmbeddedProvingKeyID = blake2bSumHex(provingKeyData)
+var EmbeddedProvingKeyID = backend.KeyID(provingKeyData)
+
+// EmbeddedLoginProvingKeyID is the blake2b-256 fingerprint of the embedded login-only proving key.
+var EmbeddedLoginProvingKeyID = backend.KeyID(loginProvingKeyData)
+
+// EmbeddedPlonkProvingKeyID is the blake2b-256 fingerprint of the embedded PLONK proving key.
+var EmbeddedPlonkProvingKeyID = backend.KeyID(plonkProvingKeyData)
+
+// EmbeddedPlonkLoginProvingKeyID is the blake2b-256 fingerprint of the embedded PLONK login-only proving key.
+var EmbeddedPlonkLoginProvingKeyID = backend.KeyID(loginPlonkProvingKeyData)
 
 // Policy defines client-side policy parameters.
 type Policy struct {
 	MinimumAge      int
 	ChallengeWindow int
 	Timezone        string
+	Circuit         string // CircuitAgeLogin (default) or CircuitLogin
+	Backend         string // backend.Groth16 (default) or backend.PLONK
+	Scheme          int    // commitment.SchemeV2 (default, MiMC) or commitment.SchemeV3 (Poseidon2)
+	ExpectedPK      string // optional: expected proving key fingerprint
+	VKID            string // optional: verifying key fingerprint for an external proving key (envelope vk_id)
 }
 
 // DefaultPolicy returns the default policy.
 func DefaultPolicy() Policy {
-	return Policy{MinimumAge: 20, ChallengeWindow: 0, Timezone: "UTC"}
+	return Policy{MinimumAge: 20, ChallengeWindow: 0, Timezone: "UTC", Circuit: CircuitAgeLogin, Backend: backend.Groth16}
 }
 
 // UserProver implements the Prover interface for generating authentication proofs.
 type UserProver struct {
-	provingKey groth16.ProvingKey
+	provingKey *backend.ProvingKey
 	ccs        constraint.ConstraintSystem
 	policy     Policy
 	config     common.SharedConfig
+	circuit    string
+	scheme     int
+	pkID       string
+	vkID       string
+
+	changeOnce   sync.Once
+	changePKData []byte // nil selects the embedded change-secret key
+	changePK     *backend.ProvingKey
+	changeCCS    constraint.ConstraintSystem
+	changeErr    error
 }
 
 // NewUserProver creates a prover with default policy and config.
@@ -51,24 +164,21 @@ func NewUserProver() (*UserProver, error) {
 }
 
 // NewUserProverWithPolicy creates a prover with custom policy and config.
+// policy.Circuit, policy.Backend and policy.Scheme select the embedded proving key.
 func NewUserProverWithPolicy(policy Policy, cfg common.SharedConfig) (*UserProver, error) {
-	var circuit UserCircuit
-	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	circuitName, err := normalizeCircuit(policy.Circuit)
 	if err != nil {
-		return nil, fmt.Errorf("circuit compile failed: %w", err)
+		return nil, err
 	}
-
-	pk := groth16.NewProvingKey(ecc.BN254)
-	if _, err := pk.ReadFrom(bytes.NewReader(provingKeyData)); err != nil {
-		return nil, fmt.Errorf("proving key parse failed: %w", err)
+	backendName, err := backend.Normalize(policy.Backend)
+	if err != nil {
+		return nil, err
 	}
-
-	return &UserProver{
-		provingKey: pk,
-		ccs:        ccs,
-		policy:     policy,
-		config:     cfg,
-	}, nil
+	scheme, err := commitment.NormalizeScheme(policy.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProverFromPKWithPolicy(embeddedProvingKey(circuitName, backendName, scheme), policy, cfg)
 }
 
 // NewUserProverFromPK creates a prover from external proving key bytes.
@@ -77,16 +187,44 @@ func NewUserProverFromPK(pkBytes []byte) (*UserProver, error) {
 }
 
 // NewUserProverFromPKWithPolicy creates a prover from external proving key with custom policy.
+// pkBytes must belong to the circuit, backend and scheme selected by policy.
 func NewUserProverFromPKWithPolicy(pkBytes []byte, policy Policy, cfg common.SharedConfig) (*UserProver, error) {
-	var circuit UserCircuit
-	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	circuitName, err := normalizeCircuit(policy.Circuit)
+	if err != nil {
+		return nil, err
+	}
+	backendName, err := backend.Normalize(policy.Backend)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := commitment.NormalizeScheme(policy.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkBytes) == 0 {
+		return nil, sdkerrors.New(sdkerrors.ErrKeyNotFound.Code, "proving key is empty (run setup)")
+	}
+	pkID := backend.KeyID(pkBytes)
+	if policy.ExpectedPK != "" && policy.ExpectedPK != pkID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "proving key fingerprint mismatch", fmt.Errorf("expected %s got %s", policy.ExpectedPK, pkID))
+	}
+	ccs, err := loadConstraintSystem(circuitName, backendName, scheme)
 	if err != nil {
-		return nil, fmt.Errorf("circuit compile failed: %w", err)
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCircuitCompile.Code, "circuit load failed", err)
 	}
 
-	pk := groth16.NewProvingKey(ecc.BN254)
-	if _, err := pk.ReadFrom(bytes.NewReader(pkBytes)); err != nil {
-		return nil, fmt.Errorf("proving key parse failed: %w", err)
+	pk, err := backend.LoadProvingKey(backendName, pkBytes)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "proving key parse failed", err)
+	}
+	policy.Circuit = circuitName
+	policy.Backend = backendName
+	policy.Scheme = scheme
+
+	// The embedded verifying key matches only the embedded proving key.
+	vkID := policy.VKID
+	if vkID == "" && pkID == ProvingKeyIDForScheme(circuitName, backendName, scheme) {
+		vkID = VerifyingKeyIDForScheme(circuitName, backendName, scheme)
 	}
 
 	return &UserProver{
@@ -94,29 +232,126 @@ func NewUserProverFromPKWithPolicy(pkBytes []byte, policy Policy, cfg common.Sha
 		ccs:        ccs,
 		policy:     policy,
 		config:     cfg,
+		circuit:    circuitName,
+		scheme:     scheme,
+		pkID:       pkID,
+		vkID:       vkID,
 	}, nil
 }
 
-// CalculateCommitment derives a commitment with a random salt.
-func (u *UserProver) CalculateCommitment(secret string) (string, string, error) {
+// CalculateCommitment derives a commitment over secret and birth date (YYYYMMDD) with a random salt.
+func (u *UserProver) CalculateCommitment(secret string, birthDate int) (string, string, error) {
 	salt, err := GenerateSalt()
 	if err != nil {
 		return "", "", err
 	}
-	commit, _, _, err := commitment.ComputeCommitment(secret, salt, u.config)
+	commit, _, _, err := commitment.ComputeCommitmentWithScheme(secret, salt, birthDate, u.scheme, u.config)
 	return commit, salt, err
 }
 
-// GenerateProof creates a Groth16 proof for authentication.
-func (u *UserProver) GenerateProof(secret string, birthYear int, currentYear int, limitAge int, challenge int, saltHex string) ([]byte, string, string, error) {
+// CalculateCommitmentRecord is CalculateCommitment returning a self-describing
+// commitment.Record (scheme, Argon2 parameters, salt) for storage.
+func (u *UserProver) CalculateCommitmentRecord(secret string, birthDate int) (commitment.Record, error) {
+	salt, err := GenerateSalt()
+	if err != nil {
+		return commitment.Record{}, err
+	}
+	return commitment.NewRecord(secret, salt, birthDate, u.scheme, u.config)
+}
+
+// GenerateProof creates a proof for authentication with the configured backend.
+// birthDate (YYYYMMDD) must be the value committed at registration; currentDate is YYYYMMDD.
+// challenge is the server challenge as a decimal or 0x-prefixed hex field element.
+// With CircuitLogin, currentDate and limitAge are ignored and birthDate may be commitment.NoBirthDate.
+func (u *UserProver) GenerateProof(secret string, birthDate int, currentDate int, limitAge int, challenge string, saltHex string) ([]byte, string, string, error) {
+	return u.GenerateProofContext(context.Background(), secret, birthDate, currentDate, limitAge, challenge, saltHex)
+}
+
+// GenerateProofContext is GenerateProof that gives up once ctx is canceled or
+// its deadline passes, returning an E1019 error.
+func (u *UserProver) GenerateProofContext(ctx context.Context, secret string, birthDate int, currentDate int, limitAge int, challenge string, saltHex string) ([]byte, string, string, error) {
+	return u.GenerateProofWithChannelContext(ctx, secret, birthDate, currentDate, limitAge, challenge, "", saltHex)
+}
+
+// GenerateProofWithChannel is GenerateProof with a channel binding (see
+// commitment.ChannelBindingFromBytes) folded into the binding, so the proof is
+// only accepted on the channel that requested it. An empty channel disables it.
+//
+// This only stops relays when the verifier authenticates the channel value:
+// it must come from something the server observes (a TLS exporter, a session
+// the server issued) or from a session key whose possession the client proves.
+// A value the client merely sends can be forwarded by a relay as well.
+func (u *UserProver) GenerateProofWithChannel(secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, saltHex string) ([]byte, string, string, error) {
+	return u.GenerateProofWithChannelContext(context.Background(), secret, birthDate, currentDate, limitAge, challenge, channel, saltHex)
+}
+
+// GenerateProofWithChannelContext is GenerateProofWithChannel with a context (see GenerateProofContext).
+func (u *UserProver) GenerateProofWithChannelContext(ctx context.Context, secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, saltHex string) ([]byte, string, string, error) {
+	return u.generateProof(ctx, secret, birthDate, currentDate, limitAge, challenge, channel, commitment.RecordFor(u.scheme, saltHex, u.config))
+}
+
+// GenerateProofWithRecord is GenerateProofWithChannel for a stored record (see
+// commitment.ParseRecord): the salt and Argon2 parameters come from the record,
+// so records created under older parameters keep proving. The record's scheme
+// must match the prover's, and secret and birthDate must reproduce its commitment.
+func (u *UserProver) GenerateProofWithRecord(secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, record string) ([]byte, string, string, error) {
+	return u.GenerateProofWithRecordContext(context.Background(), secret, birthDate, currentDate, limitAge, challenge, channel, record)
+}
+
+// GenerateProofWithRecordContext is GenerateProofWithRecord with a context (see GenerateProofContext).
+func (u *UserProver) GenerateProofWithRecordContext(ctx context.Context, secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, record string) ([]byte, string, string, error) {
+	r, err := commitment.ParseRecord(record)
+	if err != nil {
+		return nil, "", "", sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "record parse failed", err)
+	}
+	if r.Scheme != u.scheme {
+		return nil, "", "", sdkerrors.Wrap(sdkerrors.ErrPolicyMismatch.Code, "record scheme mismatch", fmt.Errorf("record v%d, prover v%d", r.Scheme, u.scheme))
+	}
+	return u.generateProof(ctx, secret, birthDate, currentDate, limitAge, challenge, channel, r)
+}
+
+// generateProof proves with the salt and Argon2 parameters of record; a non-empty
+// record.Commitment must match the derived commitment. ctx is checked before
+// and after the Argon2 derivation and bounds the proving.
+func (u *UserProver) generateProof(ctx context.Context, secret string, birthDate int, currentDate int, limitAge int, challenge string, channel string, record commitment.Record) ([]byte, string, string, error) {
+	if u.circuit != CircuitLogin {
+		if err := common.ValidateDate(birthDate); err != nil {
+			return nil, "", "", err
+		}
+	}
 	if limitAge == 0 {
 		limitAge = u.policy.MinimumAge
 	}
-	if currentYear == 0 {
-		currentYear = u.config.TargetYear
+	if currentDate == 0 {
+		currentDate = u.config.CurrentDate()
+	}
+	mode, err := common.AgeModeCode(u.config.AgeMode)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if err := sdkerrors.FromContext(ctx); err != nil {
+		return nil, "", "", err
 	}
 
-	commitmentStr, binding, derived, saltInt, err := commitment.ComputeCommitmentAndBinding(secret, saltHex, challenge, u.config)
+	commitmentStr, saltInt, derived, err := commitment.ComputeRecordCommitment(secret, birthDate, record)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if err := sdkerrors.FromContext(ctx); err != nil {
+		return nil, "", "", err
+	}
+	if record.Commitment != "" && commitmentStr != record.Commitment {
+		return nil, "", "", fmt.Errorf("secret or birth date does not match the record")
+	}
+	binding, err := commitment.ComputeChannelBindingWithScheme(commitmentStr, challenge, channel, u.scheme)
+	if err != nil {
+		return nil, "", "", err
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, "", "", err
+	}
+	channelInt, err := commitment.ParseChannelBinding(channel)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -126,31 +361,57 @@ func (u *UserProver) GenerateProof(secret string, birthYear int, currentYear int
 	var bindingInt big.Int
 	bindingInt.SetString(binding, 10)
 
-	assignment := UserCircuit{
-		PublicHash:  publicHashInt,
-		Binding:     bindingInt,
-		Salt:        saltInt,
-		CurrentYear: currentYear,
-		LimitAge:    limitAge,
-		Challenge:   challenge,
-		SecretKey:   frToBigInt(derived),
-		BirthYear:   birthYear,
+	var assignment frontend.Circuit
+	if u.circuit == CircuitLogin {
+		assignment = &LoginCircuit{
+			PublicHash: publicHashInt,
+			Binding:    bindingInt,
+			Salt:       saltInt,
+			Challenge:  challengeInt,
+			Channel:    channelInt,
+			SecretKey:  frToBigInt(derived),
+			BirthDate:  birthDate,
+		}
+	} else {
+		assignment = &UserCircuit{
+			PublicHash:  publicHashInt,
+			Binding:     bindingInt,
+			Salt:        saltInt,
+			CurrentDate: currentDate,
+			LimitAge:    limitAge,
+			Mode:        mode,
+			Challenge:   challengeInt,
+			Channel:     channelInt,
+			SecretKey:   frToBigInt(derived),
+			BirthDate:   birthDate,
+		}
 	}
 
-	witness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	proof, err := backend.ProveContext(ctx, u.ccs, u.provingKey, assignment)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("witness creation failed: %w", err)
+		return nil, "", "", err
 	}
+	return proof, commitmentStr, binding, nil
+}
 
-	proof, err := groth16.Prove(u.ccs, u.provingKey, witness)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("proof generation failed: %w", err)
-	}
+// Circuit returns the auth circuit this prover generates proofs for.
+func (u *UserProver) Circuit() string {
+	return u.circuit
+}
+
+// Backend returns the proving backend this prover uses.
+func (u *UserProver) Backend() string {
+	return u.provingKey.Backend
+}
 
-	var buf bytes.Buffer
-	proof.WriteTo(&buf)
+// Scheme returns the commitment scheme this prover commits and proves with.
+func (u *UserProver) Scheme() int {
+	return u.scheme
+}
 
-	return buf.Bytes(), commitmentStr, binding, nil
+// ProvingKeyID returns the fingerprint of the proving key this prover was built from.
+func (u *UserProver) ProvingKeyID() string {
+	return u.pkID
 }
 
 // SaltBytes is the number of bytes for salt generation.
@@ -171,12 +432,9 @@ func ProvingKeyID() string {
 	return EmbeddedProvingKeyID
 }
 
-func blake2bSumHex(data []byte) string {
-	if len(data) == 0 {
-		return ""
-	}
-	sum := blake2b.Sum256(data)
-	return hex.EncodeToString(sum[:])
+// LoginProvingKeyID returns the fingerprint of the embedded login-only proving key.
+func LoginProvingKeyID() string {
+	return EmbeddedLoginProvingKeyID
 }
 
 func frToBigInt(e fr.Element) big.Int {
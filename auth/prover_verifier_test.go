@@ -1,9 +1,16 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
@@ -20,9 +27,9 @@ func TestAuthProofVerification(t *testing.T) {
 
 	secret := "test-secret"
 	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
-	challenge := 4242
+	challenge := "4242"
 
-	proof, commitment, _, err := prover.GenerateProof(secret, 2000, cfg.TargetYear, cfg.LimitAge, challenge, salt)
+	proof, commitment, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
 	if err != nil {
 		t.Fatalf("proof generation failed: %v", err)
 	}
@@ -32,7 +39,7 @@ func TestAuthProofVerification(t *testing.T) {
 		t.Fatalf("verification failed: %v", err)
 	}
 
-	if _, err := verifier.VerifyLogin(proof, commitment, salt, challenge+1); err == nil {
+	if _, err := verifier.VerifyLogin(proof, commitment, salt, "4243"); err == nil {
 		t.Fatalf("expected verification failure for mismatched challenge")
 	}
 }
@@ -50,9 +57,9 @@ func TestAuthVerifyWithMeta(t *testing.T) {
 
 	secret := "test-secret"
 	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
-	challenge := 101
+	challenge := "101"
 
-	proof, commitment, _, err := prover.GenerateProof(secret, 2000, cfg.TargetYear, cfg.LimitAge, challenge, salt)
+	proof, commitment, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
 	if err != nil {
 		t.Fatalf("proof generation failed: %v", err)
 	}
@@ -62,3 +69,480 @@ func TestAuthVerifyWithMeta(t *testing.T) {
 		t.Fatalf("expected key mismatch error, got %v", err)
 	}
 }
+
+func TestAuthBirthDateBoundToCommitment(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	secret := "test-secret"
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "7"
+
+	// Registered as a minor; claiming an older birth date must not match the stored commitment.
+	registered, _, _, err := commitment.ComputeCommitment(secret, salt, (cfg.TargetYear-10)*10000+101, cfg)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	proof, claimed, _, err := prover.GenerateProof(secret, 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if claimed == registered {
+		t.Fatalf("commitment should depend on birth date")
+	}
+	if _, err := verifier.VerifyLogin(proof, registered, salt, challenge); err == nil {
+		t.Fatalf("expected verification failure for altered birth date")
+	}
+}
+
+func TestLoginOnlyCircuit(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	policy := DefaultPolicy()
+	policy.Circuit = CircuitLogin
+	prover, err := NewUserProverWithPolicy(policy, cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: CircuitLogin})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "77"
+
+	// A minor and a user without a registered birth date can both log in.
+	for _, birthDate := range []int{20200101, commitment.NoBirthDate} {
+		res, err := prover.GenerateProofResult("test-secret", birthDate, 0, 0, challenge, salt)
+		if err != nil {
+			t.Fatalf("proof generation failed: %v", err)
+		}
+		if res.ProofVersion != LoginProofVersion || res.VKID != LoginVerifyingKeyID() {
+			t.Fatalf("unexpected proof metadata: %s %s", res.ProofVersion, res.VKID)
+		}
+		ok, err := verifier.VerifyLogin(res.Proof, res.Commitment, salt, challenge)
+		if err != nil || !ok {
+			t.Fatalf("verification failed: %v", err)
+		}
+	}
+
+	bundle := verifier.PolicyBundle()
+	if bundle.Circuit != CircuitLogin || bundle.ProofVersion != LoginProofVersion || bundle.VKID != LoginVerifyingKeyID() {
+		t.Fatalf("policy bundle does not signal login circuit: %+v", bundle)
+	}
+
+	ageVerifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	proof, commit, _, err := prover.GenerateProof("test-secret", 20000101, 0, 0, challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if _, err := ageVerifier.VerifyLogin(proof, commit, salt, challenge); err == nil {
+		t.Fatal("expected age-login verifier to reject a login-only proof")
+	}
+
+	if _, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: "bogus"}); err == nil {
+		t.Fatal("expected unknown circuit to be rejected")
+	}
+}
+
+func TestAuthChannelBinding(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	tokenKey := []byte("token-key")
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	channel := commitment.ChannelBindingFromBytes([]byte("session-public-key"))
+	other := commitment.ChannelBindingFromBytes([]byte("proxy-session-key"))
+	token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+		UserID:         "user-123",
+		ExpiresAt:      time.Now().Add(time.Minute).Unix(),
+		VKID:           VerifyingKeyID(),
+		ParamsVersion:  common.ParamsVersion(cfg),
+		ChannelBinding: channel,
+	})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, err := ParseChallengeToken(token, tokenKey)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+
+	proof, commit, _, err := prover.GenerateProofWithChannel("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, claims.Challenge, channel, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+
+	ok, err := verifier.VerifyLoginWithTokenAndChannel(proof, commit, salt, token, channel)
+	if err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if _, err := verifier.VerifyLoginWithTokenAndChannel(proof, commit, salt, token, other); err != sdkerrors.ErrChannelMismatch {
+		t.Fatalf("expected channel mismatch, got %v", err)
+	}
+	if _, err := verifier.VerifyLoginWithChannel(proof, commit, salt, claims.Challenge, other); err == nil {
+		t.Fatal("expected proof relayed to another channel to fail")
+	}
+	if _, err := verifier.VerifyLogin(proof, commit, salt, claims.Challenge); err == nil {
+		t.Fatal("expected channel-bound proof to fail without its channel")
+	}
+}
+
+func TestPlonkBackend(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "31337"
+
+	for _, circuit := range []string{CircuitAgeLogin, CircuitLogin} {
+		policy := DefaultPolicy()
+		policy.Circuit = circuit
+		policy.Backend = backend.PLONK
+		prover, err := NewUserProverWithPolicy(policy, cfg)
+		if err != nil {
+			t.Fatalf("%s: prover init failed: %v", circuit, err)
+		}
+		verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: circuit, Backend: backend.PLONK})
+		if err != nil {
+			t.Fatalf("%s: verifier init failed: %v", circuit, err)
+		}
+
+		res, err := prover.GenerateProofResult("test-secret", 20000101, 0, 0, challenge, salt)
+		if err != nil {
+			t.Fatalf("%s: proof generation failed: %v", circuit, err)
+		}
+		if res.ProofVersion != ProofVersionFor(circuit, backend.PLONK, commitment.SchemeV2) || res.VKID != VerifyingKeyIDFor(circuit, backend.PLONK) {
+			t.Fatalf("%s: unexpected proof metadata: %s %s", circuit, res.ProofVersion, res.VKID)
+		}
+		ok, err := verifier.VerifyLogin(res.Proof, res.Commitment, salt, challenge)
+		if err != nil || !ok {
+			t.Fatalf("%s: verification failed: %v", circuit, err)
+		}
+		if _, err := verifier.VerifyLogin(res.Proof, res.Commitment, salt, "31338"); err == nil {
+			t.Fatalf("%s: expected verification failure for mismatched challenge", circuit)
+		}
+
+		bundle := verifier.PolicyBundle()
+		if bundle.Backend != backend.PLONK || bundle.VKID != VerifyingKeyIDFor(circuit, backend.PLONK) {
+			t.Fatalf("%s: policy bundle does not signal plonk: %+v", circuit, bundle)
+		}
+
+		// Both backends coexist: a PLONK proof is not a Groth16 proof.
+		groth, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: circuit})
+		if err != nil {
+			t.Fatalf("%s: verifier init failed: %v", circuit, err)
+		}
+		if _, err := groth.VerifyLogin(res.Proof, res.Commitment, salt, challenge); err == nil {
+			t.Fatalf("%s: groth16 verifier accepted a plonk proof", circuit)
+		}
+
+		// The embedded PLONK keys come from the test-only SRS.
+		if _, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: circuit, Backend: backend.PLONK, Production: true}); sdkerrors.CodeOf(err) != sdkerrors.ErrInvalidConfig.Code {
+			t.Fatalf("%s: expected production mode to refuse test SRS keys, got %v", circuit, err)
+		}
+		if _, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: circuit, Production: true}); err != nil {
+			t.Fatalf("%s: production mode must accept groth16 keys: %v", circuit, err)
+		}
+	}
+}
+
+func TestPoseidon2Scheme(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "2024"
+
+	for _, tc := range []struct{ circuit, backend string }{
+		{CircuitAgeLogin, backend.Groth16},
+		{CircuitLogin, backend.Groth16},
+		{CircuitLogin, backend.PLONK},
+	} {
+		name := tc.circuit + "/" + tc.backend
+		policy := DefaultPolicy()
+		policy.Circuit = tc.circuit
+		policy.Backend = tc.backend
+		policy.Scheme = commitment.SchemeV3
+		prover, err := NewUserProverWithPolicy(policy, cfg)
+		if err != nil {
+			t.Fatalf("%s: prover init failed: %v", name, err)
+		}
+		verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: tc.circuit, Backend: tc.backend, Scheme: commitment.SchemeV3})
+		if err != nil {
+			t.Fatalf("%s: verifier init failed: %v", name, err)
+		}
+
+		res, err := prover.GenerateProofResult("test-secret", 20000101, 0, 0, challenge, salt)
+		if err != nil {
+			t.Fatalf("%s: proof generation failed: %v", name, err)
+		}
+		want, _, _, err := commitment.ComputeCommitmentWithScheme("test-secret", salt, 20000101, commitment.SchemeV3, cfg)
+		if err != nil || res.Commitment != want {
+			t.Fatalf("%s: commitment is not the scheme v3 commitment: %v", name, err)
+		}
+		if res.ProofVersion != ProofVersionFor(tc.circuit, tc.backend, commitment.SchemeV3) || res.VKID != VerifyingKeyIDForScheme(tc.circuit, tc.backend, commitment.SchemeV3) {
+			t.Fatalf("%s: unexpected proof metadata: %s %s", name, res.ProofVersion, res.VKID)
+		}
+		ok, err := verifier.VerifyLogin(res.Proof, res.Commitment, salt, challenge)
+		if err != nil || !ok {
+			t.Fatalf("%s: verification failed: %v", name, err)
+		}
+		if bundle := verifier.PolicyBundle(); bundle.Scheme != commitment.SchemeV3 || bundle.VKID != verifier.VerifyingKeyID() {
+			t.Fatalf("%s: policy bundle does not signal scheme v3: %+v", name, bundle)
+		}
+
+		// MiMC commitments keep working with the default scheme and do not cross over.
+		mimc, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: tc.circuit, Backend: tc.backend})
+		if err != nil {
+			t.Fatalf("%s: verifier init failed: %v", name, err)
+		}
+		if _, err := mimc.VerifyLogin(res.Proof, res.Commitment, salt, challenge); err == nil {
+			t.Fatalf("%s: scheme v2 verifier accepted a scheme v3 proof", name)
+		}
+	}
+
+	if _, err := NewVerifierWithConfig(VerifierConfig{Scheme: commitment.SchemeV1}); err == nil {
+		t.Fatal("expected scheme v1 to be rejected")
+	}
+}
+
+func TestCommitmentRecordLogin(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	// A record registered under older Argon2 parameters still proves and verifies.
+	oldCfg := cfg
+	oldCfg.ArgonIterations = 1
+	rec, err := commitment.NewRecord("test-secret", "deadbeefdeadbeefdeadbeefdeadbeef", 20000101, commitment.SchemeV2, oldCfg)
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	challenge := "77"
+	proof, commit, _, err := prover.GenerateProofWithRecord("test-secret", 20000101, 0, 0, challenge, "", rec.String())
+	if err != nil || commit != rec.Commitment {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	ok, err := verifier.VerifyLoginWithRecord(proof, rec.String(), challenge, "")
+	if err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+
+	if _, _, _, err := prover.GenerateProofWithRecord("wrong-secret", 20000101, 0, 0, challenge, "", rec.String()); err == nil {
+		t.Fatal("expected a secret that does not match the record to be rejected")
+	}
+	if _, err := verifier.VerifyLoginWithRecord(proof, "not-a-record", challenge, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrCommitmentParse.Code {
+		t.Fatalf("expected E1002, got %v", err)
+	}
+	rec.Scheme = commitment.SchemeV3
+	if _, err := verifier.VerifyLoginWithRecord(proof, rec.String(), challenge, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrPolicyMismatch.Code {
+		t.Fatalf("expected E4002 for a scheme v3 record, got %v", err)
+	}
+
+	created, err := verifier.CreateCommitmentRecord("test-secret", 20000101)
+	if err != nil || !created.Matches(cfg) || created.Scheme != commitment.SchemeV2 {
+		t.Fatalf("unexpected created record %s: %v", created, err)
+	}
+}
+
+func TestVerifyLoginBatch(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	policy := DefaultPolicy()
+	policy.Circuit = CircuitLogin
+	prover, err := NewUserProverWithPolicy(policy, cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, Circuit: CircuitLogin})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	var requests []LoginRequest
+	for i, challenge := range []string{"11", "12", "13", "14", "15"} {
+		res, err := prover.GenerateProofResult("batch-secret", commitment.NoBirthDate, 0, 0, challenge, salt)
+		if err != nil {
+			t.Fatalf("proof %d generation failed: %v", i, err)
+		}
+		requests = append(requests, LoginRequest{Proof: res.Proof, Commitment: res.Commitment, Salt: salt, Challenge: challenge})
+	}
+
+	for i, res := range verifier.VerifyLoginBatch(requests) {
+		if !res.Valid || res.Err != nil {
+			t.Fatalf("item %d: expected valid, got %s %v", i, res.Code, res.Err)
+		}
+	}
+
+	requests[1].Challenge = "99"
+	requests[2].Proof = []byte{1, 2, 3}
+	requests[3].Salt = "not-hex"
+	want := []string{"", sdkerrors.ErrVerificationFail.Code, sdkerrors.ErrProofFormat.Code, sdkerrors.ErrSaltParse.Code, ""}
+	for i, res := range verifier.VerifyLoginBatch(requests) {
+		if res.Valid != (want[i] == "") || res.Code != want[i] {
+			t.Fatalf("item %d: expected code %q, got valid=%v code=%q err=%v", i, want[i], res.Valid, res.Code, res.Err)
+		}
+	}
+}
+
+func TestAuthEnvelope(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "777"
+	env, err := prover.GenerateEnvelope("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, "", salt)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	if env.VKID != verifier.VerifyingKeyID() || env.ProofVersion != verifier.PolicyBundle().ProofVersion {
+		t.Fatalf("unexpected envelope metadata: %s %s", env.VKID, env.ProofVersion)
+	}
+
+	cborBytes, err := env.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("cbor marshal failed: %v", err)
+	}
+	decoded, err := envelope.Decode(cborBytes)
+	if err != nil {
+		t.Fatalf("cbor decode failed: %v", err)
+	}
+	ok, err := verifier.VerifyEnvelope(decoded, challenge, "")
+	if err != nil || !ok {
+		t.Fatalf("envelope verification failed: %v", err)
+	}
+
+	stale := decoded
+	stale.ParamsVersion = strings.Repeat("0", 64)
+	if _, err := verifier.VerifyEnvelope(stale, challenge, ""); err != sdkerrors.ErrPolicyMismatch {
+		t.Fatalf("expected policy mismatch, got %v", err)
+	}
+	plonk := decoded
+	plonk.ProofVersion = ProofVersionFor(CircuitAgeLogin, backend.PLONK, commitment.SchemeV2)
+	if _, err := verifier.VerifyEnvelope(plonk, challenge, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrPolicyMismatch.Code {
+		t.Fatalf("expected proof version mismatch, got %v", err)
+	}
+	extra := decoded
+	extra.PublicInputs = map[string]string{envelope.InputCommitment: "1", envelope.InputSalt: salt, "binding": "2"}
+	if _, err := verifier.VerifyEnvelope(extra, challenge, ""); sdkerrors.CodeOf(err) != sdkerrors.ErrProofFormat.Code {
+		t.Fatalf("expected unexpected input rejection, got %v", err)
+	}
+}
+
+func TestAuthYearOnlyConfigParamsVersion(t *testing.T) {
+	cfg := common.DefaultSharedConfigWithYear(2026)
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	if got := verifier.PolicyBundle().ParamsVersion; got != common.ParamsVersion(cfg) {
+		t.Fatalf("verifier params_version %s differs from the config's %s", got, common.ParamsVersion(cfg))
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "777"
+	env, err := prover.GenerateEnvelope("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, "", salt)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyEnvelope(env, challenge, ""); err != nil || !ok {
+		t.Fatalf("envelope verification failed: %v", err)
+	}
+	res, err := prover.GenerateProofResult("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	if ok, err := verifier.VerifyLoginWithMeta(res.Proof, res.Commitment, salt, challenge, "", res.ParamsVersion); err != nil || !ok {
+		t.Fatalf("verification with metadata failed: %v", err)
+	}
+}
+
+func TestAuthContextCanceled(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	challenge := "4242"
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, _, err = prover.GenerateProofContext(canceled, "test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if sdkerrors.CodeOf(err) != sdkerrors.ErrCanceled.Code || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled proof generation, got %v", err)
+	}
+
+	proof, commitment, _, err := prover.GenerateProofContext(context.Background(), "test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		t.Fatalf("proof generation failed: %v", err)
+	}
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	_, err = verifier.VerifyLoginContext(expired, proof, commitment, salt, challenge)
+	if sdkerrors.CodeOf(err) != sdkerrors.ErrCanceled.Code || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected expired verification, got %v", err)
+	}
+	ok, err := verifier.VerifyLoginContext(context.Background(), proof, commitment, salt, challenge)
+	if err != nil || !ok {
+		t.Fatalf("verification failed: %v", err)
+	}
+}
+
+func TestDynamicPolicyDate(t *testing.T) {
+	// A verifier built on an earlier day keeps the fingerprint of current
+	// clients and checks proofs against today's date.
+	built := common.DefaultSharedConfig()
+	built.TargetYear--
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: built})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	bundle := verifier.PolicyBundle()
+	if bundle.ParamsVersion != common.ParamsVersion(common.DefaultSharedConfig()) {
+		t.Fatal("params_version of the default config must not depend on the date")
+	}
+	if bundle.Config.CurrentDate() != common.DateOf(time.Now().UTC()) {
+		t.Fatalf("expected today's policy date, got %d", bundle.Config.CurrentDate())
+	}
+
+	pinned := common.DefaultSharedConfigWithDate(20240101)
+	if common.ParamsVersion(pinned) == bundle.ParamsVersion {
+		t.Fatal("an explicit policy date must be part of params_version")
+	}
+}
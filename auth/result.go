@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+)
+
+// VerifyLoginResult is VerifyLoginWithChannelContext reporting a
+// common.VerificationResult: the error code and stage of a rejected proof, or
+// the verifier's vk_id, params_version and proof_version for a valid one.
+func (v *Verifier) VerifyLoginResult(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challenge string, channel string) common.VerificationResult {
+	start := time.Now()
+	_, err := v.VerifyLoginWithChannelContext(ctx, proofBytes, publicCommitment, salt, challenge, channel)
+	return common.NewVerificationResult(start, v.resultClaims(), err)
+}
+
+// VerifyLoginWithTokenResult is VerifyLoginWithTokenAndChannelContext
+// reporting a common.VerificationResult. A valid result also carries the
+// token's user_id, jti and exp.
+func (v *Verifier) VerifyLoginWithTokenResult(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string) common.VerificationResult {
+	start := time.Now()
+	claims, err := v.verifyWithToken(ctx, proofBytes, publicCommitment, salt, challengeToken, channel)
+	return common.NewVerificationResult(start, v.tokenResultClaims(claims), err)
+}
+
+// VerifyEnvelopeResult is VerifyEnvelopeContext reporting a common.VerificationResult.
+func (v *Verifier) VerifyEnvelopeResult(ctx context.Context, env envelope.Envelope, challenge string, channel string) common.VerificationResult {
+	start := time.Now()
+	_, err := v.VerifyEnvelopeContext(ctx, env, challenge, channel)
+	return common.NewVerificationResult(start, v.resultClaims(), err)
+}
+
+// VerifyEnvelopeWithTokenResult is VerifyEnvelopeWithTokenContext reporting a
+// common.VerificationResult (see VerifyLoginWithTokenResult).
+func (v *Verifier) VerifyEnvelopeWithTokenResult(ctx context.Context, env envelope.Envelope, challengeToken string, channel string) common.VerificationResult {
+	start := time.Now()
+	res, err := v.envelopeResult(env)
+	if err != nil {
+		return common.NewVerificationResult(start, nil, err)
+	}
+	claims, err := v.verifyWithToken(ctx, res.Proof, res.Commitment, res.Salt, challengeToken, channel)
+	return common.NewVerificationResult(start, v.tokenResultClaims(claims), err)
+}
+
+// verifyWithToken validates challengeToken and verifies the proof for its
+// challenge, returning the token claims. With a TokenStore the token's JTI is
+// consumed once the proof verifies, so a replayed token fails with E1013.
+func (v *Verifier) verifyWithToken(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string) (ChallengeTokenClaims, error) {
+	claims, err := v.tokenClaims(challengeToken, channel)
+	if err != nil {
+		return ChallengeTokenClaims{}, err
+	}
+	if _, err := v.VerifyLoginWithChannelContext(ctx, proofBytes, publicCommitment, salt, claims.Challenge, channel); err != nil {
+		return ChallengeTokenClaims{}, err
+	}
+	if err := v.consumeToken(claims); err != nil {
+		return ChallengeTokenClaims{}, err
+	}
+	return claims, nil
+}
+
+// resultClaims returns the verifier metadata reported for a valid proof.
+func (v *Verifier) resultClaims() map[string]string {
+	return map[string]string{
+		common.ClaimVKID:          v.VerifyingKeyID(),
+		common.ClaimParamsVersion: common.ParamsVersion(v.config),
+		common.ClaimProofVersion:  ProofVersionFor(v.circuit, v.verifyingKey.Backend, v.scheme),
+	}
+}
+
+// tokenResultClaims is resultClaims with the identity claims of a challenge token.
+func (v *Verifier) tokenResultClaims(token ChallengeTokenClaims) map[string]string {
+	claims := v.resultClaims()
+	claims[common.ClaimUserID] = token.UserID
+	claims[common.ClaimJTI] = token.JTI
+	claims[common.ClaimExpiresAt] = strconv.FormatInt(token.ExpiresAt, 10)
+	return claims
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
+)
+
+func TestVerificationResult(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	tokenKey := []byte("token-key")
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: tokenKey})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+
+	token, err := IssueChallengeToken(tokenKey, ChallengeTokenClaims{
+		UserID:        "user-123",
+		ExpiresAt:     time.Now().Add(time.Minute).Unix(),
+		VKID:          verifier.VerifyingKeyID(),
+		ParamsVersion: common.ParamsVersion(cfg),
+	})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, err := ParseChallengeToken(token, tokenKey)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
+	env, err := prover.GenerateEnvelope("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, claims.Challenge, "", salt)
+	if err != nil {
+		t.Fatalf("envelope generation failed: %v", err)
+	}
+	ctx := context.Background()
+
+	res := verifier.VerifyEnvelopeWithTokenResult(ctx, env, token, "")
+	if !res.Valid || res.Err != nil || res.Code != "" || res.Stage != "" {
+		t.Fatalf("expected valid result, got %+v", res)
+	}
+	if res.Claims[common.ClaimUserID] != "user-123" || res.Claims[common.ClaimJTI] != claims.JTI || res.Claims[common.ClaimVKID] != verifier.VerifyingKeyID() {
+		t.Fatalf("unexpected claims %v", res.Claims)
+	}
+	if res.VerifiedAt.IsZero() || res.Duration <= 0 {
+		t.Fatalf("missing timing: %+v", res)
+	}
+
+	stale := env
+	stale.ParamsVersion = strings.Repeat("0", 64)
+	cases := []struct {
+		name  string
+		res   common.VerificationResult
+		code  string
+		stage string
+	}{
+		{"bad proof", verifier.VerifyLoginWithTokenResult(ctx, []byte{1, 2, 3}, env.PublicInputs[envelope.InputCommitment], salt, token, ""), "E1001", common.StageParse},
+		{"wrong challenge", verifier.VerifyEnvelopeResult(ctx, env, "4242", ""), "E1003", common.StagePairing},
+		{"bad token", verifier.VerifyEnvelopeWithTokenResult(ctx, env, token+"x", ""), "E1012", common.StageToken},
+		{"stale params", verifier.VerifyEnvelopeWithTokenResult(ctx, stale, token, ""), "E4002", common.StagePolicy},
+	}
+	for _, tc := range cases {
+		if tc.res.Valid || tc.res.Code != tc.code || tc.res.Stage != tc.stage || tc.res.Claims != nil {
+			t.Fatalf("%s: expected %s at %s stage, got %+v", tc.name, tc.code, tc.stage, tc.res)
+		}
+	}
+}
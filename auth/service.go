@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/audit"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// DefaultChallengeTTL is how long an issued challenge stays valid unless
+// AuthServiceConfig.ChallengeTTL or ChallengeStoreConfig.TTL says otherwise.
+const DefaultChallengeTTL = 2 * time.Minute
+
+// CommitmentStore returns the commitment registered for a user.
+type CommitmentStore interface {
+	// Commitment returns the commitment and salt stored for userID at registration.
+	Commitment(userID string) (commitment string, salt string, err error)
+}
+
+// AuthServiceConfig holds configuration for an AuthService.
+type AuthServiceConfig struct {
+	Verifier    *Verifier       // required; its token keys sign and check challenge tokens
+	Commitments CommitmentStore // required
+	TokenKeyID  string          // key of VerifierConfig.TokenKeys that signs new tokens; required if the verifier uses a key set
+	TokenStore  TokenStore      // optional: consumed JTIs; defaults to VerifierConfig.TokenStore or a memory store
+	// ChallengeStore optionally holds stateful challenges; defaults to
+	// VerifierConfig.ChallengeStore or a memory store with ChallengeTTL.
+	ChallengeStore ChallengeStore
+	RateLimiter    RateLimiter  // optional: defaults to NewMemoryRateLimiter(DefaultRateLimitConfig())
+	AuditLogger    audit.Logger // optional: defaults to audit.NewNoOpLogger()
+	// ChallengeTTL bounds the lifetime of challenge tokens and of challenges in
+	// the default challenge store (default DefaultChallengeTTL).
+	ChallengeTTL time.Duration
+}
+
+// LoginAttempt is one login request handled by AuthService.
+type LoginAttempt struct {
+	UserID  string // required for VerifyLogin; checked against the token's user_id by VerifyLoginWithToken if set
+	IP      string // client address for rate limiting
+	Proof   []byte
+	Channel string // optional channel binding
+}
+
+// AuthService runs the server side of a login: it issues challenges, applies
+// rate limits, consumes each challenge once, verifies the proof against the
+// registered commitment, writes an audit event and updates the rate limiter.
+//
+// Stateful mode pairs GenerateChallenge with VerifyLogin and keeps outstanding
+// challenges in the ChallengeStore. Stateless mode pairs IssueChallengeToken with VerifyLoginWithToken; only
+// consumed token JTIs are stored, in the TokenStore. It is safe for concurrent
+// use.
+type AuthService struct {
+	verifier    *Verifier
+	commitments CommitmentStore
+	tokenKey    []byte
+	tokenKeyID  string
+	tokenStore  TokenStore
+	challenges  ChallengeStore
+	limiter     RateLimiter
+	audit       audit.Logger
+	ttl         time.Duration
+}
+
+// NewAuthService creates an AuthService from cfg, filling in defaults.
+func NewAuthService(cfg AuthServiceConfig) (*AuthService, error) {
+	if cfg.Verifier == nil {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "auth service requires a verifier")
+	}
+	if cfg.Commitments == nil {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "auth service requires a commitment store")
+	}
+	s := &AuthService{
+		verifier:    cfg.Verifier,
+		commitments: cfg.Commitments,
+		tokenKey:    cfg.Verifier.tokenKey,
+		tokenKeyID:  cfg.TokenKeyID,
+		tokenStore:  cfg.TokenStore,
+		challenges:  cfg.ChallengeStore,
+		limiter:     cfg.RateLimiter,
+		audit:       cfg.AuditLogger,
+		ttl:         cfg.ChallengeTTL,
+	}
+	if len(cfg.Verifier.tokenKeys) > 0 {
+		if cfg.TokenKeyID == "" {
+			return nil, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "auth service requires a token key id for a verifier key set")
+		}
+		s.tokenKey = cfg.Verifier.tokenKeys[cfg.TokenKeyID]
+	}
+	if cfg.TokenKeyID != "" && len(s.tokenKey) == 0 {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "token key id not in verifier key set")
+	}
+	if s.tokenStore == nil {
+		s.tokenStore = cfg.Verifier.tokenStore
+	}
+	if s.tokenStore == nil {
+		s.tokenStore = NewMemoryTokenStore()
+	}
+	if s.ttl <= 0 {
+		s.ttl = DefaultChallengeTTL
+	}
+	if s.challenges == nil {
+		s.challenges = cfg.Verifier.challenges
+	}
+	if s.challenges == nil {
+		s.challenges = NewMemoryChallengeStore(ChallengeStoreConfig{TTL: s.ttl})
+	}
+	if s.limiter == nil {
+		s.limiter = NewMemoryRateLimiter(DefaultRateLimitConfig())
+	}
+	if s.audit == nil {
+		s.audit = audit.NewNoOpLogger()
+	}
+	return s, nil
+}
+
+// GenerateChallenge issues a challenge for userID from the challenge store
+// (stateful mode). The client proves for its Challenge and returns its ID,
+// which VerifyLogin accepts once within the challenge TTL. It is not rate
+// limited: callers must limit the endpoint by client, since anyone can use up
+// a user's outstanding challenges (see ChallengeStore).
+func (s *AuthService) GenerateChallenge(userID string) (Challenge, error) {
+	return s.challenges.Issue(userID)
+}
+
+// IssueChallengeToken issues a signed challenge token for userID (stateless
+// mode), bound to the verifier's vk_id and params_version. ttl 0 selects the
+// challenge TTL.
+func (s *AuthService) IssueChallengeToken(userID string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+	return IssueChallengeTokenWithKey(s.tokenKey, s.tokenKeyID, ChallengeTokenClaims{
+		UserID:        userID,
+		ExpiresAt:     time.Now().Add(ttl).Unix(),
+		VKID:          s.verifier.VerifyingKeyID(),
+		ParamsVersion: common.ParamsVersion(s.verifier.config),
+	})
+}
+
+// VerifyLogin verifies a login proof for the challenge challengeID that
+// GenerateChallenge issued to attempt.UserID (stateful mode). The challenge is
+// consumed even if the proof fails, so every attempt needs a new challenge.
+func (s *AuthService) VerifyLogin(ctx context.Context, attempt LoginAttempt, challengeID string) common.VerificationResult {
+	start := time.Now()
+	if attempt.UserID == "" {
+		return s.finish(start, attempt, "stateful", nil, sdkerrors.ErrMissingArguments)
+	}
+	if !s.limiter.AllowLogin(attempt.UserID, attempt.IP) {
+		return s.finish(start, attempt, "stateful", nil, sdkerrors.ErrRateLimited)
+	}
+	challenge, err := consumeChallenge(s.challenges, attempt.UserID, challengeID)
+	if err != nil {
+		return s.finish(start, attempt, "stateful", nil, err)
+	}
+	claims, err := s.verify(ctx, attempt, challenge.Challenge)
+	return s.finish(start, attempt, "stateful", claims, err)
+}
+
+// VerifyLoginWithToken verifies a login proof for a challenge token issued by
+// IssueChallengeToken (stateless mode). The user is taken from the token, and
+// its JTI is consumed even if the proof fails; a reused token fails with E1013.
+func (s *AuthService) VerifyLoginWithToken(ctx context.Context, attempt LoginAttempt, challengeToken string) common.VerificationResult {
+	start := time.Now()
+	token, err := s.verifier.tokenClaims(challengeToken, attempt.Channel)
+	if err == nil && attempt.UserID != "" && attempt.UserID != token.UserID {
+		err = sdkerrors.New(sdkerrors.ErrChallengeInvalid.Code, "challenge token issued to another user")
+	}
+	if err != nil {
+		return s.finish(start, attempt, "stateless", nil, err)
+	}
+	attempt.UserID = token.UserID
+	if !s.limiter.AllowLogin(attempt.UserID, attempt.IP) {
+		return s.finish(start, attempt, "stateless", nil, sdkerrors.ErrRateLimited)
+	}
+	if err := s.tokenStore.Store(token.JTI, time.Unix(token.ExpiresAt, 0)); err != nil {
+		return s.finish(start, attempt, "stateless", nil, sdkerrors.Wrap(ErrJTIAlreadyUsed.Code, "challenge token already used", err))
+	}
+	claims, err := s.verify(ctx, attempt, token.Challenge)
+	if err == nil {
+		claims[common.ClaimJTI] = token.JTI
+		claims[common.ClaimExpiresAt] = strconv.FormatInt(token.ExpiresAt, 10)
+	}
+	return s.finish(start, attempt, "stateless", claims, err)
+}
+
+// verify checks the proof against the commitment registered for attempt.UserID.
+// Unknown users fail like a bad proof (E1003) so logins do not reveal which
+// users exist.
+func (s *AuthService) verify(ctx context.Context, attempt LoginAttempt, challenge string) (map[string]string, error) {
+	stored, salt, err := s.commitments.Commitment(attempt.UserID)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrVerificationFail.Code, "no commitment registered", err)
+	}
+	if _, err := s.verifier.VerifyLoginWithChannelContext(ctx, attempt.Proof, stored, salt, challenge, attempt.Channel); err != nil {
+		return nil, err
+	}
+	claims := s.verifier.resultClaims()
+	claims[common.ClaimUserID] = attempt.UserID
+	return claims, nil
+}
+
+// finish audits the attempt, updates the rate limiter and returns the result.
+// Rate-limited attempts do not extend the block.
+func (s *AuthService) finish(start time.Time, attempt LoginAttempt, mode string, claims map[string]string, err error) common.VerificationResult {
+	res := common.NewVerificationResult(start, claims, err)
+	switch {
+	case res.Valid:
+		s.limiter.Reset(attempt.UserID, attempt.IP)
+	case res.Code != sdkerrors.ErrRateLimited.Code && attempt.UserID != "":
+		s.limiter.RecordFailure(attempt.UserID, attempt.IP)
+	}
+	metadata := map[string]string{
+		"mode":        mode,
+		"ip":          attempt.IP,
+		"vk_id":       s.verifier.VerifyingKeyID(),
+		"duration_ms": strconv.FormatInt(res.Duration.Milliseconds(), 10),
+	}
+	if !res.Valid {
+		metadata["code"] = res.Code
+		metadata["stage"] = res.Stage
+	}
+	s.audit.LogAuthAttempt(attempt.UserID, res.Valid, metadata)
+	return res
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/audit"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+type memoryCommitments map[string][2]string
+
+func (m memoryCommitments) Commitment(userID string) (string, string, error) {
+	c, ok := m[userID]
+	if !ok {
+		return "", "", fmt.Errorf("unknown user %q", userID)
+	}
+	return c[0], c[1], nil
+}
+
+func TestAuthService(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	prover, err := NewUserProverWithPolicy(DefaultPolicy(), cfg)
+	if err != nil {
+		t.Fatalf("prover init failed: %v", err)
+	}
+	verifier, err := NewVerifierWithConfig(VerifierConfig{Config: cfg, TokenKey: []byte("token-key")})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	stored, salt, err := prover.CalculateCommitment("test-secret", 20000101)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	var log bytes.Buffer
+	svc, err := NewAuthService(AuthServiceConfig{
+		Verifier:    verifier,
+		Commitments: memoryCommitments{"alice": {stored, salt}},
+		RateLimiter: NewMemoryRateLimiter(RateLimitConfig{MaxAttempts: 3, Window: time.Minute, BlockTime: time.Minute}),
+		AuditLogger: audit.NewJSONLogger(&log),
+	})
+	if err != nil {
+		t.Fatalf("service init failed: %v", err)
+	}
+	ctx := context.Background()
+	prove := func(challenge string) []byte {
+		proof, _, _, err := prover.GenerateProof("test-secret", 20000101, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+		if err != nil {
+			t.Fatalf("proof generation failed: %v", err)
+		}
+		return proof
+	}
+
+	// Stateful: the challenge is accepted once.
+	challenge, err := svc.GenerateChallenge("alice")
+	if err != nil {
+		t.Fatalf("challenge failed: %v", err)
+	}
+	attempt := LoginAttempt{UserID: "alice", IP: "10.0.0.1", Proof: prove(challenge.Challenge)}
+	if res := svc.VerifyLogin(ctx, attempt, challenge.ID); !res.Valid || res.Claims[common.ClaimUserID] != "alice" {
+		t.Fatalf("stateful login failed: %+v", res)
+	}
+	if res := svc.VerifyLogin(ctx, attempt, challenge.ID); res.Code != ErrJTIAlreadyUsed.Code || res.Stage != common.StageReplay {
+		t.Fatalf("expected a consumed challenge to be rejected, got %+v", res)
+	}
+
+	// Stateless: the user comes from the token and its JTI is consumed.
+	token, err := svc.IssueChallengeToken("alice", 0)
+	if err != nil {
+		t.Fatalf("token issue failed: %v", err)
+	}
+	claims, err := ParseChallengeToken(token, []byte("token-key"))
+	if err != nil {
+		t.Fatalf("token parse failed: %v", err)
+	}
+	attempt = LoginAttempt{IP: "10.0.0.1", Proof: prove(claims.Challenge)}
+	if res := svc.VerifyLoginWithToken(ctx, attempt, token); !res.Valid || res.Claims[common.ClaimJTI] != claims.JTI {
+		t.Fatalf("stateless login failed: %+v", res)
+	}
+	if res := svc.VerifyLoginWithToken(ctx, attempt, token); res.Code != ErrJTIAlreadyUsed.Code || res.Stage != common.StageReplay {
+		t.Fatalf("expected token replay to be rejected, got %+v", res)
+	}
+	attempt.UserID = "bob"
+	if res := svc.VerifyLoginWithToken(ctx, attempt, token); res.Code != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected another user's token to be rejected, got %+v", res)
+	}
+
+	// Failures count toward the rate limit; the fourth attempt is blocked.
+	for i := 0; i < 2; i++ {
+		challenge, _ := svc.GenerateChallenge("alice")
+		res := svc.VerifyLogin(ctx, LoginAttempt{UserID: "alice", IP: "10.0.0.2", Proof: prove("4242")}, challenge.ID)
+		if res.Code != sdkerrors.ErrVerificationFail.Code || res.Stage != common.StagePairing {
+			t.Fatalf("expected a proof for another challenge to fail, got %+v", res)
+		}
+	}
+	challenge, _ = svc.GenerateChallenge("alice")
+	if res := svc.VerifyLogin(ctx, LoginAttempt{UserID: "alice", IP: "10.0.0.2", Proof: prove(challenge.Challenge)}, "1"); res.Code != sdkerrors.ErrChallengeInvalid.Code {
+		t.Fatalf("expected an unknown challenge id to fail, got %+v", res)
+	}
+	challenge, _ = svc.GenerateChallenge("alice")
+	if res := svc.VerifyLogin(ctx, LoginAttempt{UserID: "alice", IP: "10.0.0.2", Proof: prove(challenge.Challenge)}, challenge.ID); res.Code != sdkerrors.ErrRateLimited.Code {
+		t.Fatalf("expected rate limiting, got %+v", res)
+	}
+
+	var events, successes int
+	dec := json.NewDecoder(&log)
+	for dec.More() {
+		var ev audit.Event
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("audit decode failed: %v", err)
+		}
+		if ev.EventType != "auth_attempt" || ev.UserID == "" {
+			t.Fatalf("unexpected audit event %+v", ev)
+		}
+		events++
+		if ev.Success {
+			successes++
+		}
+	}
+	if events != 9 || successes != 2 {
+		t.Fatalf("expected 9 audited attempts with 2 successes, got %d and %d", events, successes)
+	}
+}
+
+func TestAuthServiceTokenKeyID(t *testing.T) {
+	verifier, err := NewVerifierWithConfig(VerifierConfig{TokenKeys: map[string][]byte{"k1": []byte("token-key")}})
+	if err != nil {
+		t.Fatalf("verifier init failed: %v", err)
+	}
+	commitments := memoryCommitments{}
+	for _, keyID := range []string{"", "k2"} {
+		if _, err := NewAuthService(AuthServiceConfig{Verifier: verifier, Commitments: commitments, TokenKeyID: keyID}); sdkerrors.CodeOf(err) != sdkerrors.ErrInvalidConfig.Code {
+			t.Fatalf("token key id %q: expected a config error, got %v", keyID, err)
+		}
+	}
+	svc, err := NewAuthService(AuthServiceConfig{Verifier: verifier, Commitments: commitments, TokenKeyID: "k1"})
+	if err != nil {
+		t.Fatalf("service init failed: %v", err)
+	}
+	if _, err := svc.IssueChallengeToken("alice", 0); err != nil {
+		t.Fatalf("issue token failed: %v", err)
+	}
+}
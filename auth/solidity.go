@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+)
+
+// ExportSolidity writes a Solidity verifier contract for this verifier's
+// circuit and (Groth16) verifying key.
+func (v *Verifier) ExportSolidity(w io.Writer) error {
+	return backend.ExportSolidity(v.verifyingKey, w)
+}
+
+// SolidityCalldata converts a login proof and its public inputs into the
+// calldata layout of the contract written by ExportSolidity. challenge and
+// channel are the values the proof was bound to (channel "" when unbound).
+func (v *Verifier) SolidityCalldata(res ProofResult, challenge string, channel string) (*backend.Calldata, error) {
+	assignment, err := v.loginAssignment(res.Commitment, res.Salt, challenge, channel)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := backend.SolidityCalldata(v.verifyingKey, res.Proof, assignment)
+	if err != nil {
+		var berr *backend.Error
+		if errors.As(err, &berr) {
+			return nil, backend.VerifyError(err, "proof")
+		}
+		return nil, err
+	}
+	return calldata, nil
+}
@@ -1,34 +1,97 @@
 package auth
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
 	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
-	"golang.org/x/crypto/blake2b"
 )
 
 //go:embed user.vk
 var verifyingKeyData []byte
 
+//go:embed login.vk
+var loginVerifyingKeyData []byte
+
+//go:embed user_plonk.vk
+var plonkVerifyingKeyData []byte
+
+//go:embed login_plonk.vk
+var loginPlonkVerifyingKeyData []byte
+
+//go:embed user_poseidon2.vk
+var poseidon2VerifyingKeyData []byte
+
+//go:embed login_poseidon2.vk
+var loginPoseidon2VerifyingKeyData []byte
+
+//go:embed user_poseidon2_plonk.vk
+var poseidon2PlonkVerifyingKeyData []byte
+
+//go:embed login_poseidon2_plonk.vk
+var loginPoseidon2PlonkVerifyingKeyData []byte
+
+//go:embed change_secret.vk
+var changeSecretVerifyingKeyData []byte
+
+//go:embed change_secret_poseidon2.vk
+var changeSecretPoseidon2VerifyingKeyData []byte
+
+// verifyingKeys maps key file names (see keyName) to the embedded verifying keys.
+var verifyingKeys = map[string][]byte{
+	"user":                  verifyingKeyData,
+	"login":                 loginVerifyingKeyData,
+	"user_plonk":            plonkVerifyingKeyData,
+	"login_plonk":           loginPlonkVerifyingKeyData,
+	"user_poseidon2":        poseidon2VerifyingKeyData,
+	"login_poseidon2":       loginPoseidon2VerifyingKeyData,
+	"user_poseidon2_plonk":  poseidon2PlonkVerifyingKeyData,
+	"login_poseidon2_plonk": loginPoseidon2PlonkVerifyingKeyData,
+
+	"change_secret":           changeSecretVerifyingKeyData,
+	"change_secret_poseidon2": changeSecretPoseidon2VerifyingKeyData,
+}
+
+var verifyingKeyIDs = keyIDs(verifyingKeys, backend.KeyID)
+
 // EmbeddedVerifyingKeyID is the blake2b-256 fingerprint of the embedded verifying key.
-var EmbeddedVerifyingKeyID = blake2bVerifierSumHex(verifyingKeyData)
+var EmbeddedVerifyingKeyID = backend.KeyID(verifyingKeyData)
+
+// EmbeddedLoginVerifyingKeyID is the blake2b-256 fingerprint of the embedded login-only verifying key.
+var EmbeddedLoginVerifyingKeyID = backend.KeyID(loginVerifyingKeyData)
+
+// EmbeddedPlonkVerifyingKeyID is the blake2b-256 fingerprint of the embedded PLONK verifying key.
+var EmbeddedPlonkVerifyingKeyID = backend.KeyID(plonkVerifyingKeyData)
+
+// EmbeddedPlonkLoginVerifyingKeyID is the blake2b-256 fingerprint of the embedded PLONK login-only verifying key.
+var EmbeddedPlonkLoginVerifyingKeyID = backend.KeyID(loginPlonkVerifyingKeyData)
 
 // Verifier implements the Authenticator interface for verifying authentication proofs.
 type Verifier struct {
-	verifyingKey groth16.VerifyingKey
+	verifyingKey *backend.VerifyingKey
+	vkID         string
 	config       common.SharedConfig
+	circuit      string
+	scheme       int
 	tokenKey     []byte
 	tokenKeys    map[string][]byte
+	tokenStore   TokenStore
+	challenges   ChallengeStore
+	rejectV1     bool
+
+	changeOnce   sync.Once
+	changeVKData []byte // nil: VerifySecretChange fails with E1004
+	changeVK     *backend.VerifyingKey
+	changeErr    error
 }
 
 // VerifierConfig holds configuration for the verifier.
@@ -37,6 +100,29 @@ type VerifierConfig struct {
 	ExpectedVK string // optional: expected verifying key fingerprint
 	TokenKey   []byte // optional: HMAC key for stateless challenge tokens
 	TokenKeys  map[string][]byte
+	Circuit    string // optional: CircuitAgeLogin (default) or CircuitLogin
+	Backend    string // optional: backend.Groth16 (default) or backend.PLONK
+	Scheme     int    // optional: commitment.SchemeV2 (default, MiMC) or commitment.SchemeV3 (Poseidon2)
+	// RejectLegacyTokens refuses ct-v1 challenge tokens once all clients send ct-v2.
+	RejectLegacyTokens bool
+	// TokenStore optionally records consumed token JTIs. Token logins, token
+	// envelope checks, batch entries and VerifySecretChange consume the JTI once
+	// the proof verifies and reject a token whose JTI was already used (E1013).
+	TokenStore TokenStore
+	// ChallengeStore optionally issues server-side one-time challenges for
+	// VerifyLoginWithChallengeID.
+	ChallengeStore ChallengeStore
+	// ChangeSecretVK is the change-secret verifying key used by
+	// VerifySecretChange. Verifiers with embedded keys default to the embedded
+	// one and NewVerifierFromDir reads change_secret.vk; verifiers built from
+	// other keys have none and fail VerifySecretChange with E1004.
+	ChangeSecretVK []byte
+	// ExpectedChangeSecretVK optionally pins the change-secret verifying key
+	// fingerprint (E2004).
+	ExpectedChangeSecretVK string
+	// Production refuses PLONK verifying keys built from the shipped test SRS
+	// (backend.TestSRSID), including the embedded PLONK keys, with E4003.
+	Production bool
 }
 
 // NewVerifier creates a verifier with default config.
@@ -45,86 +131,259 @@ func NewVerifier() (*Verifier, error) {
 }
 
 // NewVerifierWithConfig creates a verifier with custom config.
+// cfg.Circuit, cfg.Backend and cfg.Scheme select the embedded verifying key.
 func NewVerifierWithConfig(cfg VerifierConfig) (*Verifier, error) {
-	vk := groth16.NewVerifyingKey(ecc.BN254)
-	if len(verifyingKeyData) == 0 {
-		return nil, fmt.Errorf("embedded verifying key is empty (run setup)")
+	circuitName, backendName, scheme, err := verifierSelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifier(embeddedVerifyingKey(circuitName, backendName, scheme), embeddedVerifyingKey(CircuitChangeSecret, backend.Groth16, scheme), cfg)
+}
+
+// verifierSelection validates and normalizes the circuit, backend and scheme of cfg.
+func verifierSelection(cfg VerifierConfig) (string, string, int, error) {
+	circuitName, err := normalizeCircuit(cfg.Circuit)
+	if err != nil {
+		return "", "", 0, err
 	}
-	if _, err := vk.ReadFrom(bytes.NewReader(verifyingKeyData)); err != nil {
-		return nil, fmt.Errorf("verifying key parse failed: %w", err)
+	backendName, err := backend.Normalize(cfg.Backend)
+	if err != nil {
+		return "", "", 0, err
 	}
-	if cfg.ExpectedVK != "" && cfg.ExpectedVK != EmbeddedVerifyingKeyID {
-		return nil, fmt.Errorf("verifying key fingerprint mismatch: expected %s got %s", cfg.ExpectedVK, EmbeddedVerifyingKeyID)
+	scheme, err := commitment.NormalizeScheme(cfg.Scheme)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return circuitName, backendName, scheme, nil
+}
+
+// newVerifier creates a verifier from verifying key bytes for the circuit,
+// backend and scheme of cfg. changeVKData is the change-secret verifying key
+// found with it; cfg.ChangeSecretVK takes precedence.
+func newVerifier(vkData []byte, changeVKData []byte, cfg VerifierConfig) (*Verifier, error) {
+	circuitName, backendName, scheme, err := verifierSelection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(vkData) == 0 {
+		return nil, sdkerrors.New(sdkerrors.ErrKeyNotFound.Code, "verifying key is empty (run setup)")
+	}
+	vk, err := backend.LoadVerifyingKey(backendName, vkData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "verifying key parse failed", err)
+	}
+	vkID := backend.KeyID(vkData)
+	if cfg.ExpectedVK != "" && cfg.ExpectedVK != vkID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "verifying key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedVK, vkID))
+	}
+	if cfg.Production {
+		if err := backend.CheckProductionKey(vk); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidConfig.Code, "verifying key not fit for production", err)
+		}
+	}
+	if len(cfg.ChangeSecretVK) > 0 {
+		changeVKData = cfg.ChangeSecretVK
+	}
+	if changeID := backend.KeyID(changeVKData); changeID != "" && cfg.ExpectedChangeSecretVK != "" && cfg.ExpectedChangeSecretVK != changeID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "change-secret verifying key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedChangeSecretVK, changeID))
 	}
 
 	return &Verifier{
 		verifyingKey: vk,
+		vkID:         vkID,
 		config:       pickSharedConfig(cfg.Config),
+		circuit:      circuitName,
+		scheme:       scheme,
 		tokenKey:     cfg.TokenKey,
 		tokenKeys:    cfg.TokenKeys,
+		tokenStore:   cfg.TokenStore,
+		challenges:   cfg.ChallengeStore,
+		rejectV1:     cfg.RejectLegacyTokens,
+		changeVKData: changeVKData,
 	}, nil
 }
 
-// CreateCommitment derives a salted commitment from a user-secret for storage.
-func (v *Verifier) CreateCommitment(secret string) (string, string, error) {
+// CreateCommitment derives a salted commitment from a user-secret and birth date (YYYYMMDD) for storage.
+func (v *Verifier) CreateCommitment(secret string, birthDate int) (string, string, error) {
 	salt, err := GenerateSalt()
 	if err != nil {
 		return "", "", err
 	}
-	commit, _, _, err := commitment.ComputeCommitment(secret, salt, v.config)
+	commit, _, _, err := commitment.ComputeCommitmentWithScheme(secret, salt, birthDate, v.scheme, v.config)
 	return commit, salt, err
 }
 
-// VerifyLogin checks whether a Groth16 proof matches the stored commitment/salt and challenge.
-func (v *Verifier) VerifyLogin(proofBytes []byte, publicCommitment string, salt string, challenge int) (bool, error) {
-	proof := groth16.NewProof(ecc.BN254)
-	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
-		return false, fmt.Errorf("proof format error: %w", err)
+// CreateCommitmentRecord is CreateCommitment returning a self-describing
+// commitment.Record; store its String form instead of commitment and salt.
+func (v *Verifier) CreateCommitmentRecord(secret string, birthDate int) (commitment.Record, error) {
+	salt, err := GenerateSalt()
+	if err != nil {
+		return commitment.Record{}, err
+	}
+	return commitment.NewRecord(secret, salt, birthDate, v.scheme, v.config)
+}
+
+// VerifyLogin checks whether a proof matches the stored commitment/salt and challenge.
+// Proofs made with a channel binding are rejected; use VerifyLoginWithChannel.
+func (v *Verifier) VerifyLogin(proofBytes []byte, publicCommitment string, salt string, challenge string) (bool, error) {
+	return v.VerifyLoginContext(context.Background(), proofBytes, publicCommitment, salt, challenge)
+}
+
+// VerifyLoginContext is VerifyLogin that gives up once ctx is canceled or its
+// deadline passes, returning an E1019 error.
+func (v *Verifier) VerifyLoginContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challenge string) (bool, error) {
+	return v.VerifyLoginWithChannelContext(ctx, proofBytes, publicCommitment, salt, challenge, "")
+}
+
+// VerifyLoginWithChannel is VerifyLogin for proofs bound to a channel. channel is
+// the binding the server observes for the current connection or session (see
+// commitment.ChannelBindingFromBytes); a proof relayed from another channel fails.
+func (v *Verifier) VerifyLoginWithChannel(proofBytes []byte, publicCommitment string, salt string, challenge string, channel string) (bool, error) {
+	return v.VerifyLoginWithChannelContext(context.Background(), proofBytes, publicCommitment, salt, challenge, channel)
+}
+
+// VerifyLoginWithChannelContext is VerifyLoginWithChannel with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyLoginWithChannelContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challenge string, channel string) (bool, error) {
+	assignment, err := v.loginAssignment(publicCommitment, salt, challenge, channel)
+	if err != nil {
+		return false, err
+	}
+	if err := backend.VerifyContext(ctx, v.verifyingKey, proofBytes, assignment); err != nil {
+		return false, backend.VerifyError(err, "proof")
+	}
+	return true, nil
+}
+
+// VerifyLoginWithRecord is VerifyLoginWithChannel for a stored record (see
+// commitment.ParseRecord). Records of another scheme, including SchemeV1, are
+// rejected with E4002 and must be migrated first.
+func (v *Verifier) VerifyLoginWithRecord(proofBytes []byte, record string, challenge string, channel string) (bool, error) {
+	return v.VerifyLoginWithRecordContext(context.Background(), proofBytes, record, challenge, channel)
+}
+
+// VerifyLoginWithRecordContext is VerifyLoginWithRecord with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyLoginWithRecordContext(ctx context.Context, proofBytes []byte, record string, challenge string, channel string) (bool, error) {
+	r, err := commitment.ParseRecord(record)
+	if err != nil {
+		return false, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "record parse failed", err)
 	}
+	if r.Scheme != v.scheme {
+		return false, sdkerrors.Wrap(sdkerrors.ErrPolicyMismatch.Code, "record scheme mismatch", fmt.Errorf("record v%d, verifier v%d", r.Scheme, v.scheme))
+	}
+	return v.VerifyLoginWithChannelContext(ctx, proofBytes, r.Commitment, r.Salt, challenge, channel)
+}
 
+// loginAssignment builds the public circuit assignment a login proof is checked against.
+func (v *Verifier) loginAssignment(publicCommitment string, salt string, challenge string, channel string) (frontend.Circuit, error) {
 	var publicHashInt big.Int
 	if _, ok := publicHashInt.SetString(publicCommitment, 10); !ok {
-		return false, fmt.Errorf("public commitment parse failed: %s", publicCommitment)
+		return nil, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "public commitment parse failed", fmt.Errorf("%q", publicCommitment))
 	}
 
 	saltInt, err := saltStringToInt(salt)
 	if err != nil {
-		return false, fmt.Errorf("salt parse failed: %w", err)
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSaltParse.Code, "salt parse failed", err)
 	}
 
-	bindingStr, err := commitment.ComputeBinding(publicCommitment, challenge)
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	channelInt, err := commitment.ParseChannelBinding(channel)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "channel binding invalid", err)
+	}
+	bindingStr, err := commitment.ComputeChannelBindingWithScheme(publicCommitment, challenge, channel, v.scheme)
 	if err != nil {
-		return false, fmt.Errorf("binding compute failed: %w", err)
+		return nil, sdkerrors.Wrap(sdkerrors.ErrBindingCompute.Code, "binding compute failed", err)
 	}
 	var bindingInt big.Int
 	if _, ok := bindingInt.SetString(bindingStr, 10); !ok {
-		return false, fmt.Errorf("binding parse failed: %s", bindingStr)
+		return nil, sdkerrors.Wrap(sdkerrors.ErrBindingCompute.Code, "binding parse failed", fmt.Errorf("%q", bindingStr))
 	}
 
-	assignment := UserCircuit{
+	if v.circuit == CircuitLogin {
+		return &LoginCircuit{
+			PublicHash: publicHashInt,
+			Binding:    bindingInt,
+			Salt:       saltInt,
+			Challenge:  challengeInt,
+			Channel:    channelInt,
+		}, nil
+	}
+	mode, err := common.AgeModeCode(v.config.AgeMode)
+	if err != nil {
+		return nil, err
+	}
+	return &UserCircuit{
 		PublicHash:  publicHashInt,
 		Binding:     bindingInt,
 		Salt:        saltInt,
-		CurrentYear: v.config.TargetYear,
+		CurrentDate: v.config.CurrentDate(),
 		LimitAge:    v.config.LimitAge,
-		Challenge:   challenge,
+		Mode:        mode,
+		Challenge:   challengeInt,
+		Channel:     channelInt,
+	}, nil
+}
+
+// VerifyLoginWithToken validates a stateless challenge token and verifies the proof.
+func (v *Verifier) VerifyLoginWithToken(proofBytes []byte, publicCommitment string, salt string, challengeToken string) (bool, error) {
+	return v.VerifyLoginWithTokenContext(context.Background(), proofBytes, publicCommitment, salt, challengeToken)
+}
+
+// VerifyLoginWithTokenContext is VerifyLoginWithToken with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyLoginWithTokenContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string) (bool, error) {
+	return v.VerifyLoginWithTokenAndChannelContext(ctx, proofBytes, publicCommitment, salt, challengeToken, "")
+}
+
+// VerifyLoginWithTokenAndChannel validates a stateless challenge token and verifies a
+// channel-bound proof. If the token names a channel binding it must equal channel.
+func (v *Verifier) VerifyLoginWithTokenAndChannel(proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string) (bool, error) {
+	return v.VerifyLoginWithTokenAndChannelContext(context.Background(), proofBytes, publicCommitment, salt, challengeToken, channel)
+}
+
+// VerifyLoginWithTokenAndChannelContext is VerifyLoginWithTokenAndChannel with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyLoginWithTokenAndChannelContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, challengeToken string, channel string) (bool, error) {
+	if _, err := v.verifyWithToken(ctx, proofBytes, publicCommitment, salt, challengeToken, channel); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	publicWitness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+// VerifyLoginWithChallengeID consumes the challenge challengeID from the
+// configured ChallengeStore and verifies a proof for it. The challenge must
+// have been issued to userID; it is consumed even if the proof fails.
+func (v *Verifier) VerifyLoginWithChallengeID(proofBytes []byte, publicCommitment string, salt string, userID string, challengeID string, channel string) (bool, error) {
+	return v.VerifyLoginWithChallengeIDContext(context.Background(), proofBytes, publicCommitment, salt, userID, challengeID, channel)
+}
+
+// VerifyLoginWithChallengeIDContext is VerifyLoginWithChallengeID with a context (see VerifyLoginContext).
+func (v *Verifier) VerifyLoginWithChallengeIDContext(ctx context.Context, proofBytes []byte, publicCommitment string, salt string, userID string, challengeID string, channel string) (bool, error) {
+	if v.challenges == nil {
+		return false, sdkerrors.New(sdkerrors.ErrInvalidConfig.Code, "challenge store not configured")
+	}
+	challenge, err := consumeChallenge(v.challenges, userID, challengeID)
 	if err != nil {
-		return false, fmt.Errorf("public witness creation failed: %w", err)
+		return false, err
 	}
+	return v.VerifyLoginWithChannelContext(ctx, proofBytes, publicCommitment, salt, challenge.Challenge, channel)
+}
 
-	if err := groth16.Verify(proof, v.verifyingKey, publicWitness); err != nil {
-		return false, fmt.Errorf("proof verification failed: %w", err)
+// consumeToken records the JTI of claims in the configured TokenStore, if any.
+func (v *Verifier) consumeToken(claims ChallengeTokenClaims) error {
+	if v.tokenStore == nil {
+		return nil
 	}
-
-	return true, nil
+	if err := v.tokenStore.Store(claims.JTI, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return sdkerrors.Wrap(ErrJTIAlreadyUsed.Code, "challenge token already used", err)
+	}
+	return nil
 }
 
-// VerifyLoginWithToken validates a stateless challenge token and verifies the proof.
-func (v *Verifier) VerifyLoginWithToken(proofBytes []byte, publicCommitment string, salt string, challengeToken string) (bool, error) {
-	expectedVK := VerifyingKeyID()
+// tokenClaims validates a stateless challenge token for channel and returns its claims.
+func (v *Verifier) tokenClaims(challengeToken string, channel string) (ChallengeTokenClaims, error) {
+	expectedVK := v.VerifyingKeyID()
 	expectedParams := common.ParamsVersion(v.config)
 	var claims ChallengeTokenClaims
 	var err error
@@ -132,19 +391,25 @@ func (v *Verifier) VerifyLoginWithToken(proofBytes []byte, publicCommitment stri
 		claims, err = ValidateChallengeTokenWithKeySet(challengeToken, v.tokenKeys, time.Now(), expectedVK, expectedParams)
 	} else {
 		if len(v.tokenKey) == 0 {
-			return false, sdkerrors.ErrTokenKeyMissing
+			return ChallengeTokenClaims{}, sdkerrors.ErrTokenKeyMissing
 		}
 		claims, err = ValidateChallengeToken(challengeToken, v.tokenKey, time.Now(), expectedVK, expectedParams)
 	}
 	if err != nil {
-		return false, err
+		return ChallengeTokenClaims{}, err
+	}
+	if v.rejectV1 && claims.Version != ChallengeTokenVersionV2 {
+		return ChallengeTokenClaims{}, sdkerrors.ErrChallengeInvalid
 	}
-	return v.VerifyLogin(proofBytes, publicCommitment, salt, claims.Challenge)
+	if claims.ChannelBinding != "" && claims.ChannelBinding != channel {
+		return ChallengeTokenClaims{}, sdkerrors.ErrChannelMismatch
+	}
+	return claims, nil
 }
 
 // VerifyLoginWithMeta verifies proof and enforces vk_id/params_version metadata match.
-func (v *Verifier) VerifyLoginWithMeta(proofBytes []byte, publicCommitment string, salt string, challenge int, vkID string, paramsVersion string) (bool, error) {
-	if vkID != "" && vkID != VerifyingKeyID() {
+func (v *Verifier) VerifyLoginWithMeta(proofBytes []byte, publicCommitment string, salt string, challenge string, vkID string, paramsVersion string) (bool, error) {
+	if vkID != "" && vkID != v.VerifyingKeyID() {
 		return false, sdkerrors.ErrKeyMismatch
 	}
 	expectedParams := common.ParamsVersion(v.config)
@@ -164,15 +429,45 @@ func (v *Verifier) PolicyBundle() PolicyBundle {
 	return PolicyBundle{
 		Config:        v.config,
 		ParamsVersion: common.ParamsVersion(v.config),
-		VKID:          VerifyingKeyID(),
+		VKID:          v.VerifyingKeyID(),
+		AgeMode:       v.config.AgeMode,
+		Circuit:       v.circuit,
+		Backend:       v.verifyingKey.Backend,
+		Scheme:        v.scheme,
+		ProofVersion:  ProofVersionFor(v.circuit, v.verifyingKey.Backend, v.scheme),
 	}
 }
 
+// Circuit returns the auth circuit this verifier accepts.
+func (v *Verifier) Circuit() string {
+	return v.circuit
+}
+
+// Backend returns the proving backend this verifier accepts.
+func (v *Verifier) Backend() string {
+	return v.verifyingKey.Backend
+}
+
+// VerifyingKeyID returns the fingerprint of the verifying key this verifier was built from.
+func (v *Verifier) VerifyingKeyID() string {
+	return v.vkID
+}
+
+// Scheme returns the commitment scheme this verifier accepts.
+func (v *Verifier) Scheme() int {
+	return v.scheme
+}
+
 // VerifyingKeyID returns the fingerprint of the embedded verifying key.
 func VerifyingKeyID() string {
 	return EmbeddedVerifyingKeyID
 }
 
+// LoginVerifyingKeyID returns the fingerprint of the embedded login-only verifying key.
+func LoginVerifyingKeyID() string {
+	return EmbeddedLoginVerifyingKeyID
+}
+
 func saltStringToInt(salt string) (big.Int, error) {
 	var out big.Int
 	saltBytes, err := hex.DecodeString(salt)
@@ -185,8 +480,12 @@ func saltStringToInt(salt string) (big.Int, error) {
 
 func pickSharedConfig(cfg common.SharedConfig) common.SharedConfig {
 	def := common.DefaultSharedConfig()
-	if cfg.TargetYear == 0 {
+	if cfg.TargetYear == 0 && cfg.TargetDate == 0 && !cfg.DynamicDate {
 		cfg.TargetYear = def.TargetYear
+		cfg.DynamicDate = def.DynamicDate
+	}
+	if cfg.TargetYear == 0 {
+		cfg.TargetYear = cfg.TargetDate / 10000
 	}
 	if cfg.LimitAge == 0 {
 		cfg.LimitAge = def.LimitAge
@@ -197,13 +496,8 @@ func pickSharedConfig(cfg common.SharedConfig) common.SharedConfig {
 	if cfg.ArgonIterations == 0 {
 		cfg.ArgonIterations = def.ArgonIterations
 	}
-	return cfg
-}
-
-func blake2bVerifierSumHex(data []byte) string {
-	if len(data) == 0 {
-		return ""
+	if cfg.AgeMode == "" {
+		cfg.AgeMode = common.AgeModeInternational
 	}
-	sum := blake2b.Sum256(data)
-	return hex.EncodeToString(sum[:])
+	return cfg
 }
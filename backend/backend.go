@@ -0,0 +1,344 @@
+// Package backend abstracts the proving system used by the auth and age circuits.
+// Groth16 needs a circuit-specific trusted setup; PLONK reuses one universal KZG SRS
+// for every circuit, so circuit changes only need a new (deterministic) preprocessing.
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	plonkbn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Proving backends.
+const (
+	// Groth16 is the default backend: small proofs, circuit-specific setup.
+	Groth16 = "groth16"
+	// PLONK uses a universal KZG SRS shared by all circuits.
+	PLONK = "plonk"
+)
+
+// Normalize validates a backend name; empty selects Groth16.
+func Normalize(name string) (string, error) {
+	switch name {
+	case "", Groth16:
+		return Groth16, nil
+	case PLONK:
+		return PLONK, nil
+	default:
+		return "", fmt.Errorf("unknown proving backend %q", name)
+	}
+}
+
+// ProofVersion tags a circuit proof version with the backend. Groth16 keeps the
+// untagged version so existing clients are unaffected.
+func ProofVersion(base string, name string) string {
+	if name == PLONK {
+		return base + "-plonk"
+	}
+	return base
+}
+
+// Compile compiles circuit for the backend: R1CS for Groth16, sparse R1CS for PLONK.
+func Compile(name string, circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	if name == PLONK {
+		return frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	}
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+}
+
+// ProvingKey is a proving key tagged with its backend.
+type ProvingKey struct {
+	Backend string
+	groth16 groth16.ProvingKey
+	plonk   plonk.ProvingKey
+}
+
+// VerifyingKey is a verifying key tagged with its backend.
+type VerifyingKey struct {
+	Backend string
+	groth16 groth16.VerifyingKey
+	plonk   plonk.VerifyingKey
+}
+
+// ReadProvingKey parses a serialized proving key for the backend.
+func ReadProvingKey(name string, data []byte) (*ProvingKey, error) {
+	pk := &ProvingKey{Backend: name}
+	var r io.ReaderFrom
+	switch name {
+	case Groth16:
+		pk.groth16 = groth16.NewProvingKey(ecc.BN254)
+		r = pk.groth16
+	case PLONK:
+		pk.plonk = plonk.NewProvingKey(ecc.BN254)
+		r = pk.plonk
+	default:
+		return nil, fmt.Errorf("unknown proving backend %q", name)
+	}
+	if _, err := r.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// ReadVerifyingKey parses a serialized verifying key for the backend.
+func ReadVerifyingKey(name string, data []byte) (*VerifyingKey, error) {
+	vk := &VerifyingKey{Backend: name}
+	var r io.ReaderFrom
+	switch name {
+	case Groth16:
+		vk.groth16 = groth16.NewVerifyingKey(ecc.BN254)
+		r = vk.groth16
+	case PLONK:
+		vk.plonk = plonk.NewVerifyingKey(ecc.BN254)
+		r = vk.plonk
+	default:
+		return nil, fmt.Errorf("unknown proving backend %q", name)
+	}
+	if _, err := r.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return vk, nil
+}
+
+// WriteTo serializes the proving key.
+func (pk *ProvingKey) WriteTo(w io.Writer) (int64, error) {
+	if pk.Backend == PLONK {
+		return pk.plonk.WriteTo(w)
+	}
+	return pk.groth16.WriteTo(w)
+}
+
+// WriteTo serializes the verifying key.
+func (vk *VerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	if vk.Backend == PLONK {
+		return vk.plonk.WriteTo(w)
+	}
+	return vk.groth16.WriteTo(w)
+}
+
+// WriteRawTo serializes the proving key without point compression. Raw keys
+// are about twice the size but parse several times faster; ReadProvingKey
+// accepts both forms. The fingerprint is that of the bytes written, so a raw
+// key has a different ID than its compressed form.
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (int64, error) {
+	if pk.Backend == PLONK {
+		return pk.plonk.WriteRawTo(w)
+	}
+	return pk.groth16.WriteRawTo(w)
+}
+
+// WriteRawTo serializes the verifying key without point compression.
+func (vk *VerifyingKey) WriteRawTo(w io.Writer) (int64, error) {
+	if vk.Backend == PLONK {
+		return vk.plonk.WriteRawTo(w)
+	}
+	return vk.groth16.WriteRawTo(w)
+}
+
+// KeyID returns the blake2b-256 fingerprint (hex) of serialized key bytes, the
+// vk_id and manifest key ID. It is empty for empty data.
+func KeyID(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := blake2b.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SRSID returns the fingerprint of the KZG SRS a PLONK verifying key was built
+// from (see SRS.ID), or "" for Groth16.
+func (vk *VerifyingKey) SRSID() string {
+	if key, ok := vk.plonk.(*plonkbn254.VerifyingKey); ok {
+		return srsID(&key.Kzg)
+	}
+	return ""
+}
+
+// CheckProductionKey rejects a PLONK verifying key built from the shipped
+// test SRS (TestSRSID). Groth16 keys always pass.
+func CheckProductionKey(vk *VerifyingKey) error {
+	if vk.Backend == PLONK && vk.SRSID() == TestSRSID {
+		return fmt.Errorf("plonk verifying key was built from the test-only SRS %s", TestSRSID)
+	}
+	return nil
+}
+
+// Groth16 returns the underlying Groth16 verifying key, or nil for other backends.
+func (vk *VerifyingKey) Groth16() groth16.VerifyingKey {
+	return vk.groth16
+}
+
+// Setup runs the backend setup for ccs. srs is required for PLONK and ignored
+// for Groth16, whose setup samples fresh toxic waste on this machine.
+func Setup(name string, ccs constraint.ConstraintSystem, srs *SRS) (*ProvingKey, *VerifyingKey, error) {
+	switch name {
+	case Groth16:
+		pk, vk, err := groth16.Setup(ccs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &ProvingKey{Backend: name, groth16: pk}, &VerifyingKey{Backend: name, groth16: vk}, nil
+	case PLONK:
+		if srs == nil {
+			return nil, nil, fmt.Errorf("plonk setup requires a KZG SRS")
+		}
+		canonical, lagrange, err := srs.forCircuit(ccs)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := plonk.Setup(ccs, canonical, lagrange)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &ProvingKey{Backend: name, plonk: pk}, &VerifyingKey{Backend: name, plonk: vk}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown proving backend %q", name)
+	}
+}
+
+// Prove builds the full witness from assignment and returns the serialized proof.
+func Prove(ccs constraint.ConstraintSystem, pk *ProvingKey, assignment frontend.Circuit) ([]byte, error) {
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("witness creation failed: %w", err)
+	}
+
+	var proof io.WriterTo
+	if pk.Backend == PLONK {
+		proof, err = plonk.Prove(ccs, pk.plonk, witness)
+	} else {
+		proof, err = groth16.Prove(ccs, pk.groth16, witness)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("proof generation failed: %w", err)
+	}
+
+	var buf bytes.Buffer
+	proof.WriteTo(&buf)
+	return buf.Bytes(), nil
+}
+
+// Verify checks a serialized proof against the public part of assignment.
+// Errors are returned as ErrProofFormat, ErrWitness or ErrVerification causes.
+func Verify(vk *VerifyingKey, proofBytes []byte, publicAssignment frontend.Circuit) error {
+	publicWitness, err := frontend.NewWitness(publicAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return &Error{Stage: StageWitness, Err: err}
+	}
+
+	if vk.Backend == PLONK {
+		proof := plonk.NewProof(ecc.BN254)
+		if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+			return &Error{Stage: StageFormat, Err: err}
+		}
+		if err := plonk.Verify(proof, vk.plonk, publicWitness); err != nil {
+			return &Error{Stage: StageVerify, Err: err}
+		}
+		return nil
+	}
+
+	proof := groth16.NewProof(ecc.BN254)
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return &Error{Stage: StageFormat, Err: err}
+	}
+	if err := groth16.Verify(proof, vk.groth16, publicWitness); err != nil {
+		return &Error{Stage: StageVerify, Err: err}
+	}
+	return nil
+}
+
+// ProveContext is Prove that returns as soon as ctx is done, with a
+// StageCanceled Error wrapping ctx.Err(). gnark cannot interrupt a running
+// prover, so an abandoned proof finishes in the background and is discarded;
+// it keeps its work slot until then (see SetMaxConcurrentWork).
+func ProveContext(ctx context.Context, ccs constraint.ConstraintSystem, pk *ProvingKey, assignment frontend.Circuit) ([]byte, error) {
+	return runContext(ctx, func() ([]byte, error) {
+		return Prove(ccs, pk, assignment)
+	})
+}
+
+// VerifyContext is Verify that returns as soon as ctx is done (see ProveContext).
+func VerifyContext(ctx context.Context, vk *VerifyingKey, proofBytes []byte, publicAssignment frontend.Circuit) error {
+	_, err := runContext(ctx, func() (struct{}, error) {
+		return struct{}{}, Verify(vk, proofBytes, publicAssignment)
+	})
+	return err
+}
+
+// work bounds the prove and verify jobs run by runContext.
+var work = struct {
+	mu    sync.RWMutex
+	slots chan struct{}
+}{slots: make(chan struct{}, runtime.GOMAXPROCS(0))}
+
+// SetMaxConcurrentWork caps how many proofs ProveContext and VerifyContext
+// generate or check at once across the process; n <= 0 selects
+// runtime.GOMAXPROCS(0), the default. Callers wait for a free slot or for their
+// context. A canceled call returns at once, but its job keeps the slot until
+// gnark finishes, so cancellations free the caller, not the CPU, and cannot
+// pile up unbounded background work. Jobs already running keep the slots of
+// the previous limit.
+func SetMaxConcurrentWork(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	work.mu.Lock()
+	defer work.mu.Unlock()
+	work.slots = make(chan struct{}, n)
+}
+
+// runContext takes a work slot and runs fn unless ctx is done first, then
+// waits for fn or for ctx. fn releases the slot when it returns, even if the
+// caller has given up on it.
+func runContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, &Error{Stage: StageCanceled, Err: err}
+	}
+	work.mu.RLock()
+	slots := work.slots
+	work.mu.RUnlock()
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return zero, &Error{Stage: StageCanceled, Err: ctx.Err()}
+	}
+	if err := ctx.Err(); err != nil {
+		<-slots
+		return zero, &Error{Stage: StageCanceled, Err: err}
+	}
+	if ctx.Done() == nil {
+		defer func() { <-slots }()
+		return fn()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-slots }()
+		v, err := fn()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, &Error{Stage: StageCanceled, Err: ctx.Err()}
+	}
+}
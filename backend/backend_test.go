@@ -0,0 +1,392 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestProveVerify(t *testing.T) {
+	srs, err := NewSRS(16)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := srs.WriteTo(&buf); err != nil {
+		t.Fatalf("srs write: %v", err)
+	}
+	reread, err := ReadSRS(&buf)
+	if err != nil || reread.ID() != srs.ID() {
+		t.Fatalf("srs round trip failed: %v", err)
+	}
+
+	for _, name := range []string{Groth16, PLONK} {
+		ccs, err := Compile(name, &squareCircuit{})
+		if err != nil {
+			t.Fatalf("%s compile: %v", name, err)
+		}
+		pk, vk, err := Setup(name, ccs, reread)
+		if err != nil {
+			t.Fatalf("%s setup: %v", name, err)
+		}
+
+		var pkBuf, vkBuf bytes.Buffer
+		pk.WriteTo(&pkBuf)
+		vk.WriteTo(&vkBuf)
+		if pk, err = ReadProvingKey(name, pkBuf.Bytes()); err != nil {
+			t.Fatalf("%s pk read: %v", name, err)
+		}
+		if vk, err = ReadVerifyingKey(name, vkBuf.Bytes()); err != nil {
+			t.Fatalf("%s vk read: %v", name, err)
+		}
+
+		proof, err := Prove(ccs, pk, &squareCircuit{X: 3, Y: 9})
+		if err != nil {
+			t.Fatalf("%s prove: %v", name, err)
+		}
+		if err := Verify(vk, proof, &squareCircuit{Y: 9}); err != nil {
+			t.Fatalf("%s verify: %v", name, err)
+		}
+		err = Verify(vk, proof, &squareCircuit{Y: 10})
+		if e, ok := err.(*Error); !ok || e.Stage != StageVerify {
+			t.Fatalf("%s: expected verify-stage error, got %v", name, err)
+		}
+		err = Verify(vk, []byte{1, 2, 3}, &squareCircuit{Y: 9})
+		if e, ok := err.(*Error); !ok || e.Stage != StageFormat {
+			t.Fatalf("%s: expected format-stage error, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	srs, err := NewSRS(16)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	for _, name := range []string{Groth16, PLONK} {
+		ccs, err := Compile(name, &squareCircuit{})
+		if err != nil {
+			t.Fatalf("%s compile: %v", name, err)
+		}
+		pk, vk, err := Setup(name, ccs, srs)
+		if err != nil {
+			t.Fatalf("%s setup: %v", name, err)
+		}
+
+		var items []BatchItem
+		for x := 2; x <= 5; x++ {
+			proof, err := Prove(ccs, pk, &squareCircuit{X: x, Y: x * x})
+			if err != nil {
+				t.Fatalf("%s prove: %v", name, err)
+			}
+			items = append(items, BatchItem{Proof: proof, Public: &squareCircuit{Y: x * x}})
+		}
+		for i, err := range VerifyBatch(vk, items) {
+			if err != nil {
+				t.Fatalf("%s: item %d: %v", name, i, err)
+			}
+		}
+
+		items[1].Public = &squareCircuit{Y: 10}
+		items[3].Proof = []byte{1, 2, 3}
+		errs := VerifyBatch(vk, items)
+		want := []string{"", StageVerify, "", StageFormat}
+		for i, err := range errs {
+			if want[i] == "" {
+				if err != nil {
+					t.Fatalf("%s: item %d: %v", name, i, err)
+				}
+				continue
+			}
+			if e, ok := err.(*Error); !ok || e.Stage != want[i] {
+				t.Fatalf("%s: item %d: expected %s-stage error, got %v", name, i, want[i], err)
+			}
+		}
+	}
+}
+
+func TestSolidityCalldata(t *testing.T) {
+	ccs, err := Compile(Groth16, &squareCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	pk, vk, err := Setup(Groth16, ccs, nil)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	var contract bytes.Buffer
+	if err := ExportSolidity(vk, &contract); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(contract.String(), "function verifyProof(") {
+		t.Fatalf("exported contract has no verifyProof")
+	}
+
+	proofBytes, err := Prove(ccs, pk, &squareCircuit{X: 3, Y: 9})
+	if err != nil {
+		t.Fatalf("prove: %v", err)
+	}
+	calldata, err := SolidityCalldata(vk, proofBytes, &squareCircuit{Y: 9})
+	if err != nil {
+		t.Fatalf("calldata: %v", err)
+	}
+	if len(calldata.Input) != 1 || calldata.Input[0].Int64() != 9 {
+		t.Fatalf("unexpected public input: %v", calldata.Input)
+	}
+
+	// Rebuild the proof from the calldata words to check the layout.
+	var proof groth16bn254.Proof
+	w := calldata.Proof
+	proof.Ar.X.SetBigInt(w[0])
+	proof.Ar.Y.SetBigInt(w[1])
+	proof.Bs.X.A1.SetBigInt(w[2])
+	proof.Bs.X.A0.SetBigInt(w[3])
+	proof.Bs.Y.A1.SetBigInt(w[4])
+	proof.Bs.Y.A0.SetBigInt(w[5])
+	proof.Krs.X.SetBigInt(w[6])
+	proof.Krs.Y.SetBigInt(w[7])
+	var rebuilt bytes.Buffer
+	proof.WriteTo(&rebuilt)
+	if err := Verify(vk, rebuilt.Bytes(), &squareCircuit{Y: 9}); err != nil {
+		t.Fatalf("rebuilt proof does not verify: %v", err)
+	}
+
+	encoded := calldata.Encode()
+	// keccak256("verifyProof(uint256[8],uint256[1])")[:4]
+	if len(encoded) != 4+9*32 || hex.EncodeToString(encoded[:4]) != "1b81f829" {
+		t.Fatalf("unexpected encoding %x", encoded[:4])
+	}
+
+	data, err := json.Marshal(calldata)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded Calldata
+	if err := json.Unmarshal(data, &decoded); err != nil || !bytes.Equal(decoded.Encode(), encoded) {
+		t.Fatalf("json round trip failed: %v", err)
+	}
+	modulus := ecc.BN254.ScalarField().String()
+	for _, input := range []string{"-9", modulus, "1" + modulus} {
+		bad := strings.Replace(string(data), `"input":["9"]`, `"input":["`+input+`"]`, 1)
+		if bad == string(data) {
+			t.Fatalf("unexpected calldata json %s", data)
+		}
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Fatalf("expected input word %s to be rejected", input)
+		}
+	}
+	badProof := strings.Replace(string(data), `"proof":["`, `"proof":["-`, 1)
+	if err := json.Unmarshal([]byte(badProof), &decoded); err == nil {
+		t.Fatal("expected a negative proof word to be rejected")
+	}
+
+	_, plonkVK, err := Setup(PLONK, mustCompile(t, PLONK), mustSRS(t))
+	if err != nil {
+		t.Fatalf("plonk setup: %v", err)
+	}
+	if err := ExportSolidity(plonkVK, &contract); err == nil {
+		t.Fatalf("expected plonk export to be rejected")
+	}
+}
+
+func mustCompile(t *testing.T, name string) constraint.ConstraintSystem {
+	ccs, err := Compile(name, &squareCircuit{})
+	if err != nil {
+		t.Fatalf("%s compile: %v", name, err)
+	}
+	return ccs
+}
+
+func mustSRS(t *testing.T) *SRS {
+	srs, err := NewSRS(16)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	return srs
+}
+
+func TestSRSTooSmall(t *testing.T) {
+	srs, err := NewSRS(1)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	ccs, err := Compile(PLONK, &squareCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, _, err := Setup(PLONK, ccs, srs); err == nil {
+		t.Fatalf("expected srs size error")
+	}
+	if _, _, err := Setup(PLONK, ccs, nil); err == nil {
+		t.Fatalf("expected missing srs error")
+	}
+}
+
+func TestManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	m := &KeyManifest{}
+	m.Put(KeyEntry{Circuit: "login", Backend: Groth16, VKID: "a"})
+	m.Put(KeyEntry{Circuit: "login", Backend: PLONK, VKID: "b"})
+	m.Put(KeyEntry{Circuit: "login", Backend: Groth16, VKID: "c"})
+	if err := m.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Keys) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(loaded.Keys))
+	}
+	if e, ok := loaded.Find("login", Groth16); !ok || e.VKID != "c" {
+		t.Fatalf("find failed: %+v", e)
+	}
+	if e, ok := loaded.FindVK("b"); !ok || e.Backend != PLONK {
+		t.Fatalf("find vk failed: %+v", e)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if n, err := Normalize(""); err != nil || n != Groth16 {
+		t.Fatalf("empty backend should select groth16")
+	}
+	if _, err := Normalize("marlin"); err == nil {
+		t.Fatalf("expected unknown backend error")
+	}
+	if ProofVersion("auth-proof-v4", PLONK) != "auth-proof-v4-plonk" || ProofVersion("auth-proof-v4", Groth16) != "auth-proof-v4" {
+		t.Fatalf("unexpected proof version tagging")
+	}
+}
+
+func TestProveVerifyContext(t *testing.T) {
+	ccs, err := Compile(Groth16, &squareCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	srs, err := NewSRS(16)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	pk, vk, err := Setup(Groth16, ccs, srs)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	proof, err := ProveContext(ctx, ccs, pk, &squareCircuit{X: 3, Y: 9})
+	if err != nil {
+		t.Fatalf("prove: %v", err)
+	}
+	if err := VerifyContext(ctx, vk, proof, &squareCircuit{Y: 9}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	canceled, stop := context.WithCancel(context.Background())
+	stop()
+	if _, err := ProveContext(canceled, ccs, pk, &squareCircuit{X: 3, Y: 9}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled prove, got %v", err)
+	}
+	err = VerifyContext(canceled, vk, proof, &squareCircuit{Y: 9})
+	if e, ok := err.(*Error); !ok || e.Stage != StageCanceled || e.ErrorCode() != "E1019" {
+		t.Fatalf("expected canceled-stage error, got %v", err)
+	}
+}
+
+func TestAbandonedWorkHoldsSlot(t *testing.T) {
+	SetMaxConcurrentWork(1)
+	defer SetMaxConcurrentWork(0)
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := runContext(ctx, func() (struct{}, error) {
+		defer close(finished)
+		<-release
+		return struct{}{}, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller to be freed, got %v", err)
+	}
+
+	waiting, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	if _, err := runContext(waiting, func() (struct{}, error) { return struct{}{}, nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the abandoned job to hold the only slot, got %v", err)
+	}
+
+	close(release)
+	<-finished
+	if _, err := runContext(context.Background(), func() (struct{}, error) { return struct{}{}, nil }); err != nil {
+		t.Fatalf("expected the slot to be free once the job finished: %v", err)
+	}
+}
+
+func TestTestOnlySRS(t *testing.T) {
+	srs := mustSRS(t)
+	if !srs.TestOnly() {
+		t.Fatal("a sampled srs must be test-only")
+	}
+	var buf bytes.Buffer
+	srs.WriteTo(&buf)
+	imported, err := ReadSRS(&buf)
+	if err != nil || imported.TestOnly() {
+		t.Fatalf("an imported srs is only test-only if it is the shipped one: %v", err)
+	}
+	f, err := os.Open("../keys/kzg_bn254.srs")
+	if err != nil {
+		t.Fatalf("open shipped srs: %v", err)
+	}
+	defer f.Close()
+	shipped, err := ReadSRS(f)
+	if err != nil || !shipped.TestOnly() {
+		t.Fatalf("the shipped srs must be test-only: %v", err)
+	}
+
+	ccs := mustCompile(t, PLONK)
+	for _, tc := range []struct {
+		srs *SRS
+		ok  bool
+	}{{imported, true}, {shipped, false}} {
+		_, vk, err := Setup(PLONK, ccs, tc.srs)
+		if err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if vk.SRSID() != tc.srs.ID() {
+			t.Fatalf("vk srs id %s, want %s", vk.SRSID(), tc.srs.ID())
+		}
+		if err := CheckProductionKey(vk); (err == nil) != tc.ok {
+			t.Fatalf("srs %s: unexpected production check result %v", tc.srs.ID(), err)
+		}
+	}
+	_, vk, err := Setup(Groth16, mustCompile(t, Groth16), nil)
+	if err != nil || vk.SRSID() != "" || CheckProductionKey(vk) != nil {
+		t.Fatalf("groth16 keys have no srs: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+)
+
+// BatchItem is one proof and the public part of its assignment.
+type BatchItem struct {
+	Proof  []byte
+	Public frontend.Circuit
+}
+
+// errSubgroup is reported for proof points outside the prime-order subgroups.
+var errSubgroup = errors.New("proof point not in subgroup")
+
+// VerifyBatch checks items against one verifying key and returns one error per
+// item (nil when the proof is valid), using the same *Error stages as Verify.
+//
+// Groth16 proofs are folded into a single randomized pairing check
+// (∏ e(rᵢAᵢ, Bᵢ) · e(-ΣrᵢLᵢ, γ) · e(-ΣrᵢCᵢ, δ) · e(-Σrᵢα, β) = 1 with random rᵢ),
+// so a forged proof passes only with negligible probability. If that check
+// fails, the items are verified individually to find the bad ones. PLONK proofs
+// and keys with commitments are verified individually in parallel.
+func VerifyBatch(vk *VerifyingKey, items []BatchItem) []error {
+	errs := make([]error, len(items))
+	if len(items) == 0 {
+		return errs
+	}
+
+	gvk, ok := vk.groth16.(*groth16bn254.VerifyingKey)
+	if vk.Backend == PLONK || !ok || len(gvk.CommitmentKeys) > 0 {
+		parallel(len(items), func(i int) {
+			errs[i] = Verify(vk, items[i].Proof, items[i].Public)
+		})
+		return errs
+	}
+
+	proofs := make([]*groth16bn254.Proof, len(items))
+	publics := make([]fr.Vector, len(items))
+	parallel(len(items), func(i int) {
+		proofs[i], publics[i], errs[i] = parseGroth16Item(gvk, items[i])
+	})
+
+	var pending []int
+	for i, err := range errs {
+		if err == nil {
+			pending = append(pending, i)
+		}
+	}
+	switch len(pending) {
+	case 0:
+		return errs
+	case 1:
+		// Nothing to amortize; fall through to the individual check below.
+	default:
+		ok, err := batchPairing(gvk, proofs, publics, pending)
+		if err == nil && ok {
+			return errs
+		}
+	}
+
+	parallel(len(pending), func(j int) {
+		i := pending[j]
+		if err := groth16bn254.Verify(proofs[i], gvk, publics[i]); err != nil {
+			errs[i] = &Error{Stage: StageVerify, Err: err}
+		}
+	})
+	return errs
+}
+
+// parseGroth16Item decodes a proof and its public witness and checks subgroup membership.
+func parseGroth16Item(vk *groth16bn254.VerifyingKey, item BatchItem) (*groth16bn254.Proof, fr.Vector, error) {
+	publicWitness, err := frontend.NewWitness(item.Public, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return nil, nil, &Error{Stage: StageWitness, Err: err}
+	}
+	public, ok := publicWitness.Vector().(fr.Vector)
+	if !ok || len(public)+1 != len(vk.G1.K) {
+		return nil, nil, &Error{Stage: StageWitness, Err: fmt.Errorf("invalid witness size, got %d, expected %d", len(public), len(vk.G1.K)-1)}
+	}
+
+	proof := groth16.NewProof(ecc.BN254).(*groth16bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(item.Proof)); err != nil {
+		return nil, nil, &Error{Stage: StageFormat, Err: err}
+	}
+	if !proof.Ar.IsInSubGroup() || !proof.Krs.IsInSubGroup() || !proof.Bs.IsInSubGroup() {
+		return nil, nil, &Error{Stage: StageVerify, Err: errSubgroup}
+	}
+	return proof, public, nil
+}
+
+// batchPairing runs the randomized Groth16 check over proofs[i] for i in pending.
+func batchPairing(vk *groth16bn254.VerifyingKey, proofs []*groth16bn254.Proof, publics []fr.Vector, pending []int) (bool, error) {
+	n := len(pending)
+	g1 := make([]curve.G1Affine, 0, n+3)
+	g2 := make([]curve.G2Affine, 0, n+3)
+
+	// kScalars[0] accumulates Σrᵢ (the K₀ and α coefficient), kScalars[j] Σrᵢxᵢⱼ.
+	kScalars := make([]fr.Element, len(vk.G1.K))
+	rs := make([]fr.Element, n)
+	krs := make([]curve.G1Affine, n)
+	for j, i := range pending {
+		if _, err := rs[j].SetRandom(); err != nil {
+			return false, err
+		}
+		kScalars[0].Add(&kScalars[0], &rs[j])
+		var t fr.Element
+		for k := range publics[i] {
+			t.Mul(&rs[j], &publics[i][k])
+			kScalars[k+1].Add(&kScalars[k+1], &t)
+		}
+
+		var r big.Int
+		var a curve.G1Affine
+		a.ScalarMultiplication(&proofs[i].Ar, rs[j].BigInt(&r))
+		g1 = append(g1, a)
+		g2 = append(g2, proofs[i].Bs)
+		krs[j] = proofs[i].Krs
+	}
+
+	var sumL, sumC, sumAlpha curve.G1Affine
+	if _, err := sumL.MultiExp(vk.G1.K, kScalars, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+	if _, err := sumC.MultiExp(krs, rs, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+	var rSum big.Int
+	sumAlpha.ScalarMultiplication(&vk.G1.Alpha, kScalars[0].BigInt(&rSum))
+	sumL.Neg(&sumL)
+	sumC.Neg(&sumC)
+	sumAlpha.Neg(&sumAlpha)
+
+	g1 = append(g1, sumL, sumC, sumAlpha)
+	g2 = append(g2, vk.G2.Gamma, vk.G2.Delta, vk.G2.Beta)
+	return curve.PairingCheck(g1, g2)
+}
+
+// parallel runs fn(0..n-1) on up to GOMAXPROCS goroutines.
+func parallel(n int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
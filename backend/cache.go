@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"golang.org/x/crypto/blake2b"
+)
+
+// cache holds parsed keys and constraint systems for the whole process, keyed
+// by kind, backend and the blake2b-256 fingerprint of the serialized bytes.
+// Parsing a proving key dominates prover startup, so provers and verifiers
+// created for the same key share one parsed copy. Proving and verifying only
+// read keys and constraint systems, so sharing them is safe.
+var cache sync.Map // string -> *cacheEntry
+
+type cacheEntry struct {
+	once  sync.Once
+	value any
+	err   error
+}
+
+// ReadConstraintSystem parses a constraint system serialized with its WriteTo
+// method: R1CS for Groth16, sparse R1CS for PLONK.
+func ReadConstraintSystem(name string, data []byte) (constraint.ConstraintSystem, error) {
+	var ccs constraint.ConstraintSystem
+	switch name {
+	case Groth16:
+		ccs = groth16.NewCS(ecc.BN254)
+	case PLONK:
+		ccs = plonk.NewCS(ecc.BN254)
+	default:
+		return nil, fmt.Errorf("unknown proving backend %q", name)
+	}
+	if _, err := ccs.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ccs, nil
+}
+
+// LoadConstraintSystem returns the constraint system of circuit for the
+// backend. A non-empty data is a serialized constraint system shipped next to
+// the keys (see ReadConstraintSystem) and is parsed once per process; with
+// empty data the circuit is compiled, uncached.
+func LoadConstraintSystem(name string, data []byte, circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	if len(data) == 0 {
+		return Compile(name, circuit)
+	}
+	v, err := cached("ccs", name, data, func() (any, error) {
+		return ReadConstraintSystem(name, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(constraint.ConstraintSystem), nil
+}
+
+// LoadProvingKey is ReadProvingKey backed by the process-wide cache. Callers
+// must not modify the returned key.
+func LoadProvingKey(name string, data []byte) (*ProvingKey, error) {
+	v, err := cached("pk", name, data, func() (any, error) {
+		return ReadProvingKey(name, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ProvingKey), nil
+}
+
+// LoadVerifyingKey is ReadVerifyingKey backed by the process-wide cache.
+func LoadVerifyingKey(name string, data []byte) (*VerifyingKey, error) {
+	v, err := cached("vk", name, data, func() (any, error) {
+		return ReadVerifyingKey(name, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*VerifyingKey), nil
+}
+
+// ResetCache drops every cached key and constraint system, e.g. after key
+// rotation retired the keys they were loaded from. Provers and verifiers that
+// already hold them keep working.
+func ResetCache() {
+	cache.Range(func(k, _ any) bool {
+		cache.Delete(k)
+		return true
+	})
+}
+
+// cached returns the value loaded for data, calling load at most once per
+// process while it succeeds. Failed loads are not cached.
+func cached(kind string, name string, data []byte, load func() (any, error)) (any, error) {
+	sum := blake2b.Sum256(data)
+	key := kind + "/" + name + "/" + hex.EncodeToString(sum[:])
+	e, _ := cache.LoadOrStore(key, &cacheEntry{})
+	entry := e.(*cacheEntry)
+	entry.once.Do(func() {
+		entry.value, entry.err = load()
+	})
+	if entry.err != nil {
+		cache.CompareAndDelete(key, entry)
+		return nil, entry.err
+	}
+	return entry.value, nil
+}
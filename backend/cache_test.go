@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeyCache(t *testing.T) {
+	srs, err := NewSRS(16)
+	if err != nil {
+		t.Fatalf("srs: %v", err)
+	}
+	for _, name := range []string{Groth16, PLONK} {
+		ccs, err := Compile(name, &squareCircuit{})
+		if err != nil {
+			t.Fatalf("%s compile: %v", name, err)
+		}
+		pk, vk, err := Setup(name, ccs, srs)
+		if err != nil {
+			t.Fatalf("%s setup: %v", name, err)
+		}
+
+		var ccsBuf, pkBuf, rawPKBuf, vkBuf bytes.Buffer
+		ccs.WriteTo(&ccsBuf)
+		pk.WriteTo(&pkBuf)
+		pk.WriteRawTo(&rawPKBuf)
+		vk.WriteRawTo(&vkBuf)
+		if rawPKBuf.Len() <= pkBuf.Len() {
+			t.Fatalf("%s: raw proving key should be larger than compressed (%d <= %d)", name, rawPKBuf.Len(), pkBuf.Len())
+		}
+
+		loadedCCS, err := LoadConstraintSystem(name, ccsBuf.Bytes(), &squareCircuit{})
+		if err != nil {
+			t.Fatalf("%s ccs load: %v", name, err)
+		}
+		again, _ := LoadConstraintSystem(name, ccsBuf.Bytes(), &squareCircuit{})
+		if again != loadedCCS {
+			t.Fatalf("%s: constraint system not shared", name)
+		}
+		loadedPK, err := LoadProvingKey(name, rawPKBuf.Bytes())
+		if err != nil {
+			t.Fatalf("%s raw pk load: %v", name, err)
+		}
+		if again, _ := LoadProvingKey(name, rawPKBuf.Bytes()); again != loadedPK {
+			t.Fatalf("%s: proving key not shared", name)
+		}
+		loadedVK, err := LoadVerifyingKey(name, vkBuf.Bytes())
+		if err != nil {
+			t.Fatalf("%s raw vk load: %v", name, err)
+		}
+
+		proof, err := Prove(loadedCCS, loadedPK, &squareCircuit{X: 3, Y: 9})
+		if err != nil {
+			t.Fatalf("%s prove: %v", name, err)
+		}
+		if err := Verify(loadedVK, proof, &squareCircuit{Y: 9}); err != nil {
+			t.Fatalf("%s verify: %v", name, err)
+		}
+
+		ResetCache()
+		if reloaded, _ := LoadProvingKey(name, rawPKBuf.Bytes()); reloaded == loadedPK {
+			t.Fatalf("%s: proving key still cached after reset", name)
+		}
+	}
+
+	if _, err := LoadProvingKey(Groth16, []byte{1, 2, 3}); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if _, err := LoadProvingKey(Groth16, []byte{1, 2, 3}); err == nil {
+		t.Fatalf("failed load must not be cached as success")
+	}
+}
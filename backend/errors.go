@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// Verification stages reported by Error.
+const (
+	StageFormat   = "format"
+	StageWitness  = "witness"
+	StageVerify   = "verify"
+	StageCanceled = "canceled" // ProveContext or VerifyContext returned early
+)
+
+// Error reports which step of Verify failed so callers can keep their own messages.
+type Error struct {
+	Stage string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode maps the stage to the SDK error code (E1001, E1007, E1019 or E1003).
+func (e *Error) ErrorCode() string {
+	switch e.Stage {
+	case StageFormat:
+		return "E1001"
+	case StageWitness:
+		return "E1007"
+	case StageCanceled:
+		return "E1019"
+	default:
+		return "E1003"
+	}
+}
+
+// VerifyError converts an error of Verify or VerifyContext into an SDK error
+// carrying the stage's code. what names the proof in messages, e.g. "age".
+func VerifyError(err error, what string) error {
+	var berr *Error
+	if !errors.As(err, &berr) {
+		return sdkerrors.Wrap(sdkerrors.ErrVerificationFail.Code, what+" verification failed", err)
+	}
+	switch berr.Stage {
+	case StageFormat:
+		return sdkerrors.Wrap(berr.ErrorCode(), "proof format error", berr.Err)
+	case StageWitness:
+		return sdkerrors.Wrap(berr.ErrorCode(), "public witness creation failed", berr.Err)
+	case StageCanceled:
+		return sdkerrors.Wrap(berr.ErrorCode(), what+" verification canceled", berr.Err)
+	}
+	return sdkerrors.Wrap(berr.ErrorCode(), what+" verification failed", berr.Err)
+}
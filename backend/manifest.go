@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// KeyEntry describes one proving/verifying key pair produced by setup.
+type KeyEntry struct {
+	Circuit      string `json:"circuit"`
+	Backend      string `json:"backend"`
+	Curve        string `json:"curve"`
+	ProvingKey   string `json:"proving_key"`
+	VerifyingKey string `json:"verifying_key"`
+	PKID         string `json:"pk_id"`
+	VKID         string `json:"vk_id"`
+	SRSID        string `json:"srs_id,omitempty"` // PLONK only
+
+	ConstraintSystem string `json:"constraint_system,omitempty"` // serialized constraint system shipped with the keys
+	CCSID            string `json:"ccs_id,omitempty"`
+}
+
+// KeyManifest lists the keys shipped with the SDK so deployments can check
+// which circuit and backend a fingerprint belongs to.
+type KeyManifest struct {
+	Keys []KeyEntry `json:"keys"`
+}
+
+// LoadManifest reads a manifest written by Save.
+func LoadManifest(path string) (*KeyManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m KeyManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("key manifest parse failed: %w", err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest as indented JSON.
+func (m *KeyManifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Find returns the entry for a circuit and backend.
+func (m *KeyManifest) Find(circuit string, backend string) (KeyEntry, bool) {
+	for _, e := range m.Keys {
+		if e.Circuit == circuit && e.Backend == backend {
+			return e, true
+		}
+	}
+	return KeyEntry{}, false
+}
+
+// FindVK returns the entry whose verifying key fingerprint is vkID.
+func (m *KeyManifest) FindVK(vkID string) (KeyEntry, bool) {
+	for _, e := range m.Keys {
+		if e.VKID == vkID {
+			return e, true
+		}
+	}
+	return KeyEntry{}, false
+}
+
+// Put inserts or replaces the entry for e.Circuit and e.Backend.
+func (m *KeyManifest) Put(e KeyEntry) {
+	for i := range m.Keys {
+		if m.Keys[i].Circuit == e.Circuit && m.Keys[i].Backend == e.Backend {
+			m.Keys[i] = e
+			return
+		}
+	}
+	m.Keys = append(m.Keys, e)
+}
@@ -0,0 +1,148 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"golang.org/x/crypto/sha3"
+)
+
+// errSolidityBackend is returned for PLONK keys: our PLONK proofs use a
+// SHA-256 transcript, which the exported PLONK contract cannot replay.
+var errSolidityBackend = errors.New("solidity export supports groth16 keys only")
+
+// ExportSolidity writes a Solidity verifier contract (gnark's template) for vk.
+// The contract exposes verifyProof(uint256[8] proof, uint256[N] input), which
+// reverts on an invalid proof.
+func ExportSolidity(vk *VerifyingKey, w io.Writer) error {
+	if vk.Backend == PLONK {
+		return errSolidityBackend
+	}
+	// Our keys have no commitments, so the hash only silences gnark's default notice.
+	return vk.groth16.ExportSolidity(w, solidity.WithHashToFieldFunction(sha3.NewLegacyKeccak256()))
+}
+
+// Calldata holds the arguments of the exported contract's verifyProof call.
+// Proof is Ar.X, Ar.Y, Bs.X.A1, Bs.X.A0, Bs.Y.A1, Bs.Y.A0, Krs.X, Krs.Y and
+// Input the public witness in circuit field order.
+type Calldata struct {
+	Proof [8]*big.Int
+	Input []*big.Int
+}
+
+// SolidityCalldata converts a serialized Groth16 proof and the public part of
+// its assignment into the contract's calldata layout. Errors carry the same
+// stages as Verify; the proof itself is not checked.
+func SolidityCalldata(vk *VerifyingKey, proofBytes []byte, publicAssignment frontend.Circuit) (*Calldata, error) {
+	if vk.Backend == PLONK {
+		return nil, errSolidityBackend
+	}
+	publicWitness, err := frontend.NewWitness(publicAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return nil, &Error{Stage: StageWitness, Err: err}
+	}
+	public, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, &Error{Stage: StageWitness, Err: fmt.Errorf("unexpected witness type %T", publicWitness.Vector())}
+	}
+
+	proof := groth16.NewProof(ecc.BN254).(*groth16bn254.Proof)
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return nil, &Error{Stage: StageFormat, Err: err}
+	}
+	if len(proof.Commitments) > 0 {
+		return nil, &Error{Stage: StageFormat, Err: errors.New("proofs with commitments are not supported")}
+	}
+
+	raw := proof.MarshalSolidity()
+	out := &Calldata{Input: make([]*big.Int, len(public))}
+	for i := range out.Proof {
+		out.Proof[i] = new(big.Int).SetBytes(raw[i*fr.Bytes : (i+1)*fr.Bytes])
+	}
+	for i := range public {
+		out.Input[i] = public[i].BigInt(new(big.Int))
+	}
+	return out, nil
+}
+
+// Signature returns the Solidity signature of the verifyProof function.
+func (c *Calldata) Signature() string {
+	return fmt.Sprintf("verifyProof(uint256[8],uint256[%d])", len(c.Input))
+}
+
+// Encode returns the ABI-encoded transaction data (selector followed by the arguments).
+func (c *Calldata) Encode() []byte {
+	selector := sha3.NewLegacyKeccak256()
+	selector.Write([]byte(c.Signature()))
+	out := selector.Sum(nil)[:4]
+
+	var word [32]byte
+	for _, v := range append(c.Proof[:], c.Input...) {
+		out = append(out, v.FillBytes(word[:])...)
+	}
+	return out
+}
+
+type calldataJSON struct {
+	Proof []string `json:"proof"`
+	Input []string `json:"input"`
+}
+
+// MarshalJSON encodes the words as decimal strings.
+func (c Calldata) MarshalJSON() ([]byte, error) {
+	var out calldataJSON
+	for _, v := range c.Proof {
+		out.Proof = append(out.Proof, v.String())
+	}
+	out.Input = make([]string, 0, len(c.Input))
+	for _, v := range c.Input {
+		out.Input = append(out.Input, v.String())
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON. Proof words must be
+// base field elements and input words scalar field elements, so Encode never
+// sees a negative or oversized word.
+func (c *Calldata) UnmarshalJSON(data []byte) error {
+	var in calldataJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if len(in.Proof) != len(c.Proof) {
+		return fmt.Errorf("calldata proof has %d words, expected %d", len(in.Proof), len(c.Proof))
+	}
+	parse := func(s string, modulus *big.Int) (*big.Int, error) {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("calldata word parse failed: %q", s)
+		}
+		if v.Sign() < 0 || v.Cmp(modulus) >= 0 {
+			return nil, fmt.Errorf("calldata word out of range: %q", s)
+		}
+		return v, nil
+	}
+	var err error
+	for i, s := range in.Proof {
+		if c.Proof[i], err = parse(s, ecc.BN254.BaseField()); err != nil {
+			return err
+		}
+	}
+	c.Input = make([]*big.Int, len(in.Input))
+	for i, s := range in.Input {
+		if c.Input[i], err = parse(s, ecc.BN254.ScalarField()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
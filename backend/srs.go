@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/constraint"
+	"golang.org/x/crypto/blake2b"
+)
+
+// TestSRSID is the fingerprint of keys/kzg_bn254.srs, the SRS the embedded
+// PLONK keys are built from. It was sampled by NewSRS on a single machine, so
+// it and those keys are for tests and development only: whoever ran the setup
+// could have kept tau and forged PLONK proofs. Production deployments build
+// their PLONK keys from a public-ceremony SRS and set the verifier's
+// Production option, which refuses keys made from this SRS.
+const TestSRSID = "3b51028f8016971082c018d2e174a38ae606e30c84972d3fc9f8daca62bfab83"
+
+// SRS is a universal KZG structured reference string for PLONK on BN254.
+// One SRS serves every circuit whose domain fits in it.
+type SRS struct {
+	kzg      kzg.SRS
+	testOnly bool
+}
+
+// NewSRS samples a test-only SRS supporting circuits with up to size
+// constraints (public inputs included). The toxic value is drawn from
+// crypto/rand and discarded, but nothing proves that it was; production
+// deployments must import an SRS from a public ceremony via ReadSRS instead.
+func NewSRS(size uint64) (*SRS, error) {
+	tau, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("sample toxic value: %w", err)
+	}
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(size)+3, tau)
+	if err != nil {
+		return nil, err
+	}
+	return &SRS{kzg: *srs, testOnly: true}, nil
+}
+
+// ReadSRS parses an SRS written by WriteTo.
+func ReadSRS(r io.Reader) (*SRS, error) {
+	srs := &SRS{}
+	if _, err := srs.kzg.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("srs parse failed: %w", err)
+	}
+	return srs, nil
+}
+
+// WriteTo serializes the SRS.
+func (s *SRS) WriteTo(w io.Writer) (int64, error) {
+	return s.kzg.WriteTo(w)
+}
+
+// Size returns the number of G1 powers in the SRS.
+func (s *SRS) Size() int {
+	return len(s.kzg.Pk.G1)
+}
+
+// ID returns the blake2b-256 fingerprint of the SRS verifying part, which is
+// what PLONK verifying keys commit to.
+func (s *SRS) ID() string {
+	return srsID(&s.kzg.Vk)
+}
+
+func srsID(vk *kzg.VerifyingKey) string {
+	h, _ := blake2b.New256(nil)
+	vk.WriteTo(h)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TestOnly reports whether the SRS was sampled by NewSRS or is the shipped
+// test SRS (TestSRSID), and so must not back production keys.
+func (s *SRS) TestOnly() bool {
+	return s.testOnly || s.ID() == TestSRSID
+}
+
+// forCircuit returns the canonical SRS and its Lagrange form sized for ccs.
+func (s *SRS) forCircuit(ccs constraint.ConstraintSystem) (*kzg.SRS, *kzg.SRS, error) {
+	sizeLagrange := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints() + ccs.GetNbPublicVariables()))
+	if uint64(len(s.kzg.Pk.G1)) < sizeLagrange+3 {
+		return nil, nil, fmt.Errorf("srs too small: circuit needs %d points, srs has %d", sizeLagrange+3, len(s.kzg.Pk.G1))
+	}
+	lagrangeG1, err := kzg.ToLagrangeG1(s.kzg.Pk.G1[:sizeLagrange])
+	if err != nil {
+		return nil, nil, fmt.Errorf("srs lagrange conversion failed: %w", err)
+	}
+	lagrange := &kzg.SRS{Pk: kzg.ProvingKey{G1: lagrangeG1}, Vk: s.kzg.Vk}
+	return &s.kzg, lagrange, nil
+}
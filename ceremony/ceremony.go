@@ -0,0 +1,419 @@
+// Package ceremony runs a multi-party Groth16 trusted setup (gnark MPC phase 1
+// "powers of tau" and phase 2 circuit-specific) with a file-based transcript.
+//
+// A ceremony directory holds transcript.json plus one file per contribution.
+// Every contributor adds randomness with Contribute; the coordinator closes
+// each phase with Finalize and a public random beacon. As long as one
+// contributor discarded their randomness, nobody knows the toxic waste.
+// Anyone can re-check the whole directory with Verify before the resulting
+// keys are embedded.
+package ceremony
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	"github.com/consensys/gnark/constraint"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"golang.org/x/crypto/blake2b"
+)
+
+// TranscriptFile is the name of the transcript inside a ceremony directory.
+const TranscriptFile = "transcript.json"
+
+const commonsFile = "phase1_commons.bin"
+
+// Ceremony phases recorded in Transcript.Phase.
+const (
+	PhasePowersOfTau = 1
+	PhaseCircuit     = 2
+	PhaseDone        = 3
+)
+
+// Contribution is one entry of the transcript.
+type Contribution struct {
+	Index     int       `json:"index"`
+	Name      string    `json:"name"`
+	File      string    `json:"file"`
+	Hash      string    `json:"hash"` // blake2b-256 of File
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Transcript records the state of a ceremony.
+type Transcript struct {
+	Circuit      string         `json:"circuit"`
+	CircuitHash  string         `json:"circuit_hash"` // blake2b-256 of the serialized R1CS
+	DomainSize   uint64         `json:"domain_size"`
+	Phase        int            `json:"phase"`
+	Phase1       []Contribution `json:"phase1"`
+	Phase1Beacon string         `json:"phase1_beacon,omitempty"` // hex
+	CommonsHash  string         `json:"commons_hash,omitempty"`
+	Phase2       []Contribution `json:"phase2"`
+	Phase2Beacon string         `json:"phase2_beacon,omitempty"` // hex
+	ProvingKey   string         `json:"proving_key,omitempty"`
+	VerifyingKey string         `json:"verifying_key,omitempty"`
+	PKID         string         `json:"pk_id,omitempty"`
+	VKID         string         `json:"vk_id,omitempty"`
+}
+
+// Init starts a ceremony for ccs in dir, which must not already hold a transcript.
+func Init(dir string, circuit string, ccs constraint.ConstraintSystem) (*Transcript, error) {
+	r1cs, err := asR1CS(ccs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, TranscriptFile)); err == nil {
+		return nil, fmt.Errorf("ceremony already initialized in %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	t := &Transcript{
+		Circuit:     circuit,
+		CircuitHash: circuitHash(r1cs),
+		DomainSize:  ecc.NextPowerOfTwo(uint64(r1cs.GetNbConstraints())),
+		Phase:       PhasePowersOfTau,
+	}
+	return t, t.save(dir)
+}
+
+// Load reads the transcript in dir.
+func Load(dir string) (*Transcript, error) {
+	data, err := os.ReadFile(filepath.Join(dir, TranscriptFile))
+	if err != nil {
+		return nil, err
+	}
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("transcript parse failed: %w", err)
+	}
+	return &t, nil
+}
+
+func (t *Transcript) save(dir string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, TranscriptFile), append(data, '\n'), 0644)
+}
+
+// Contribute verifies the current phase, adds fresh randomness on top of the
+// latest contribution and records it under name. The randomness never leaves
+// this call.
+func Contribute(dir string, name string, ccs constraint.ConstraintSystem) (Contribution, error) {
+	t, err := Load(dir)
+	if err != nil {
+		return Contribution{}, err
+	}
+	r1cs, err := t.checkCircuit(ccs)
+	if err != nil {
+		return Contribution{}, err
+	}
+
+	var (
+		obj  io.WriterTo
+		list *[]Contribution
+		file string
+	)
+	switch t.Phase {
+	case PhasePowersOfTau:
+		p1, err := t.verifyPhase1Chain(dir)
+		if err != nil {
+			return Contribution{}, err
+		}
+		p1.Contribute()
+		obj, list = p1, &t.Phase1
+		file = fmt.Sprintf("phase1_%04d.bin", len(t.Phase1)+1)
+	case PhaseCircuit:
+		p2, err := t.verifyPhase2Chain(dir, r1cs)
+		if err != nil {
+			return Contribution{}, err
+		}
+		p2.Contribute()
+		obj, list = p2, &t.Phase2
+		file = fmt.Sprintf("phase2_%04d.bin", len(t.Phase2)+1)
+	default:
+		return Contribution{}, errors.New("ceremony is finalized")
+	}
+
+	hash, err := writeFile(filepath.Join(dir, file), obj)
+	if err != nil {
+		return Contribution{}, err
+	}
+	c := Contribution{Index: len(*list) + 1, Name: name, File: file, Hash: hash, CreatedAt: time.Now().UTC()}
+	*list = append(*list, c)
+	return c, t.save(dir)
+}
+
+// Finalize closes the current phase with a public random beacon (e.g. a drand
+// round) published after the last contribution. Closing phase 1 derives the
+// circuit-independent parameters and prepares phase 2; closing phase 2 writes
+// the proving and verifying keys to pkPath and vkPath.
+func Finalize(dir string, ccs constraint.ConstraintSystem, beacon []byte, pkPath string, vkPath string) (*Transcript, error) {
+	if len(beacon) == 0 {
+		return nil, errors.New("beacon is required")
+	}
+	t, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	r1cs, err := t.checkCircuit(ccs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Phase {
+	case PhasePowersOfTau:
+		if len(t.Phase1) == 0 {
+			return nil, errors.New("phase 1 has no contributions")
+		}
+		contribs, err := t.readPhase1(dir)
+		if err != nil {
+			return nil, err
+		}
+		commons, err := mpcsetup.VerifyPhase1(t.DomainSize, beacon, contribs...)
+		if err != nil {
+			return nil, fmt.Errorf("phase 1 verification failed: %w", err)
+		}
+		if t.CommonsHash, err = writeFile(filepath.Join(dir, commonsFile), &commons); err != nil {
+			return nil, err
+		}
+		t.Phase1Beacon = hex.EncodeToString(beacon)
+		t.Phase = PhaseCircuit
+	case PhaseCircuit:
+		if len(t.Phase2) == 0 {
+			return nil, errors.New("phase 2 has no contributions")
+		}
+		pk, vk, err := t.sealPhase2(dir, r1cs, beacon)
+		if err != nil {
+			return nil, err
+		}
+		if t.PKID, err = writeFile(pkPath, pk); err != nil {
+			return nil, err
+		}
+		if t.VKID, err = writeFile(vkPath, vk); err != nil {
+			return nil, err
+		}
+		t.ProvingKey, t.VerifyingKey = pkPath, vkPath
+		t.Phase2Beacon = hex.EncodeToString(beacon)
+		t.Phase = PhaseDone
+	default:
+		return nil, errors.New("ceremony is already finalized")
+	}
+	return t, t.save(dir)
+}
+
+// Verify re-checks every contribution and beacon in dir against ccs. For a
+// finished ceremony it recomputes the keys and compares their fingerprints
+// with the transcript, so the embedded user.pk/user.vk can be traced back to
+// the published contributions.
+func Verify(dir string, ccs constraint.ConstraintSystem) (*Transcript, error) {
+	t, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	r1cs, err := t.checkCircuit(ccs)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Phase == PhasePowersOfTau {
+		_, err := t.verifyPhase1Chain(dir)
+		return t, err
+	}
+
+	if t.Phase == PhaseCircuit {
+		_, err := t.verifyPhase2Chain(dir, r1cs)
+		return t, err
+	}
+
+	beacon, err := hex.DecodeString(t.Phase2Beacon)
+	if err != nil {
+		return nil, fmt.Errorf("phase 2 beacon: %w", err)
+	}
+	pk, vk, err := t.sealPhase2(dir, r1cs, beacon)
+	if err != nil {
+		return nil, err
+	}
+	if id := sumWriter(pk); id != t.PKID {
+		return nil, fmt.Errorf("proving key fingerprint mismatch: transcript %s, recomputed %s", t.PKID, id)
+	}
+	if id := sumWriter(vk); id != t.VKID {
+		return nil, fmt.Errorf("verifying key fingerprint mismatch: transcript %s, recomputed %s", t.VKID, id)
+	}
+	return t, nil
+}
+
+// verifyPhase1Chain checks every phase 1 contribution and returns the latest
+// state (the initial state when nobody contributed yet).
+func (t *Transcript) verifyPhase1Chain(dir string) (*mpcsetup.Phase1, error) {
+	contribs, err := t.readPhase1(dir)
+	if err != nil {
+		return nil, err
+	}
+	prev := mpcsetup.NewPhase1(t.DomainSize)
+	for i, c := range contribs {
+		if err := prev.Verify(c); err != nil {
+			return nil, fmt.Errorf("phase 1 contribution %d (%s): %w", i+1, t.Phase1[i].Name, err)
+		}
+		prev = c
+	}
+	return prev, nil
+}
+
+// verifyPhase2Chain checks every phase 2 contribution and returns the latest state.
+// The initial state is deterministic, so it is recomputed rather than stored.
+func (t *Transcript) verifyPhase2Chain(dir string, r1cs *cs.R1CS) (*mpcsetup.Phase2, error) {
+	commons, err := t.loadCommons(dir)
+	if err != nil {
+		return nil, err
+	}
+	contribs, err := t.readPhase2(dir)
+	if err != nil {
+		return nil, err
+	}
+	prev := new(mpcsetup.Phase2)
+	prev.Initialize(r1cs, commons)
+	for i, c := range contribs {
+		if err := prev.Verify(c); err != nil {
+			return nil, fmt.Errorf("phase 2 contribution %d (%s): %w", i+1, t.Phase2[i].Name, err)
+		}
+		prev = c
+	}
+	return prev, nil
+}
+
+func (t *Transcript) sealPhase2(dir string, r1cs *cs.R1CS, beacon []byte) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	commons, err := t.loadCommons(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	contribs, err := t.readPhase2(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, vk, err := mpcsetup.VerifyPhase2(r1cs, commons, beacon, contribs...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("phase 2 verification failed: %w", err)
+	}
+	return pk, vk, nil
+}
+
+// loadCommons recomputes the phase 1 output from the contributions and beacon
+// and checks it against the stored file.
+func (t *Transcript) loadCommons(dir string) (*mpcsetup.SrsCommons, error) {
+	beacon, err := hex.DecodeString(t.Phase1Beacon)
+	if err != nil {
+		return nil, fmt.Errorf("phase 1 beacon: %w", err)
+	}
+	contribs, err := t.readPhase1(dir)
+	if err != nil {
+		return nil, err
+	}
+	commons, err := mpcsetup.VerifyPhase1(t.DomainSize, beacon, contribs...)
+	if err != nil {
+		return nil, fmt.Errorf("phase 1 verification failed: %w", err)
+	}
+	if id := sumWriter(&commons); id != t.CommonsHash {
+		return nil, fmt.Errorf("phase 1 output mismatch: transcript %s, recomputed %s", t.CommonsHash, id)
+	}
+	return &commons, nil
+}
+
+func (t *Transcript) readPhase1(dir string) ([]*mpcsetup.Phase1, error) {
+	out := make([]*mpcsetup.Phase1, len(t.Phase1))
+	for i, c := range t.Phase1 {
+		out[i] = new(mpcsetup.Phase1)
+		if err := readFile(filepath.Join(dir, c.File), c.Hash, out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (t *Transcript) readPhase2(dir string) ([]*mpcsetup.Phase2, error) {
+	out := make([]*mpcsetup.Phase2, len(t.Phase2))
+	for i, c := range t.Phase2 {
+		out[i] = new(mpcsetup.Phase2)
+		if err := readFile(filepath.Join(dir, c.File), c.Hash, out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (t *Transcript) checkCircuit(ccs constraint.ConstraintSystem) (*cs.R1CS, error) {
+	r1cs, err := asR1CS(ccs)
+	if err != nil {
+		return nil, err
+	}
+	if h := circuitHash(r1cs); h != t.CircuitHash {
+		return nil, fmt.Errorf("circuit mismatch: transcript is for %s (%s), got %s", t.Circuit, t.CircuitHash, h)
+	}
+	return r1cs, nil
+}
+
+func asR1CS(ccs constraint.ConstraintSystem) (*cs.R1CS, error) {
+	r1cs, ok := ccs.(*cs.R1CS)
+	if !ok {
+		return nil, errors.New("ceremony requires a BN254 R1CS (Groth16) constraint system")
+	}
+	return r1cs, nil
+}
+
+func circuitHash(r1cs *cs.R1CS) string {
+	return sumWriter(r1cs)
+}
+
+func sumWriter(w io.WriterTo) string {
+	h, _ := blake2b.New256(nil)
+	w.WriteTo(h)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFile serializes obj to path and returns its blake2b-256 fingerprint.
+func writeFile(path string, obj io.WriterTo) (string, error) {
+	var buf bytes.Buffer
+	if _, err := obj.WriteTo(&buf); err != nil {
+		return "", err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	sum := blake2b.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readFile parses path into obj, checking its fingerprint when want is set.
+func readFile(path string, want string, obj io.ReaderFrom) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if want != "" {
+		sum := blake2b.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("%s: fingerprint mismatch (transcript %s, file %s)", filepath.Base(path), want, got)
+		}
+	}
+	if _, err := obj.ReadFrom(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("%s: parse failed: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package ceremony
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestCeremony(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubeCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := Init(dir, "cube", ccs); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := Init(dir, "cube", ccs); err == nil {
+		t.Fatalf("expected error re-initializing a ceremony")
+	}
+
+	for _, name := range []string{"team-a", "team-b"} {
+		if _, err := Contribute(dir, name, ccs); err != nil {
+			t.Fatalf("phase 1 contribute %s: %v", name, err)
+		}
+	}
+	if _, err := Finalize(dir, ccs, []byte("beacon-1"), "", ""); err != nil {
+		t.Fatalf("finalize phase 1: %v", err)
+	}
+	for _, name := range []string{"team-a", "team-c"} {
+		if _, err := Contribute(dir, name, ccs); err != nil {
+			t.Fatalf("phase 2 contribute %s: %v", name, err)
+		}
+	}
+	pkPath, vkPath := filepath.Join(dir, "cube.pk"), filepath.Join(dir, "cube.vk")
+	tr, err := Finalize(dir, ccs, []byte("beacon-2"), pkPath, vkPath)
+	if err != nil {
+		t.Fatalf("finalize phase 2: %v", err)
+	}
+	if tr.Phase != PhaseDone || len(tr.Phase1) != 2 || len(tr.Phase2) != 2 {
+		t.Fatalf("unexpected transcript: %+v", tr)
+	}
+	if _, err := Verify(dir, ccs); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// The ceremony keys prove and verify like groth16.Setup keys.
+	pk := groth16.NewProvingKey(ecc.BN254)
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	pkData, _ := os.ReadFile(pkPath)
+	vkData, _ := os.ReadFile(vkPath)
+	if _, err := pk.ReadFrom(bytes.NewReader(pkData)); err != nil {
+		t.Fatalf("pk read: %v", err)
+	}
+	if _, err := vk.ReadFrom(bytes.NewReader(vkData)); err != nil {
+		t.Fatalf("vk read: %v", err)
+	}
+	witness, _ := frontend.NewWitness(&cubeCircuit{X: 3, Y: 27}, ecc.BN254.ScalarField())
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		t.Fatalf("prove: %v", err)
+	}
+	public, _ := witness.Public()
+	if err := groth16.Verify(proof, vk, public); err != nil {
+		t.Fatalf("verify proof: %v", err)
+	}
+
+	// A different beacon than the recorded one yields different keys.
+	tr.Phase2Beacon = "00"
+	if err := tr.save(dir); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := Verify(dir, ccs); err == nil {
+		t.Fatalf("expected key fingerprint mismatch for altered beacon")
+	}
+}
+
+func TestCeremonyTamperedContribution(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubeCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := Init(dir, "cube", ccs); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	c, err := Contribute(dir, "team-a", ccs)
+	if err != nil {
+		t.Fatalf("contribute: %v", err)
+	}
+
+	path := filepath.Join(dir, c.File)
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 1
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Verify(dir, ccs); err == nil {
+		t.Fatalf("expected tampered contribution to fail verification")
+	}
+	if _, err := Contribute(dir, "team-b", ccs); err == nil {
+		t.Fatalf("expected contribute to refuse a tampered transcript")
+	}
+
+	other, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, err := Verify(dir, other); err == nil {
+		t.Fatalf("expected circuit mismatch")
+	}
+}
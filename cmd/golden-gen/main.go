@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ghdehrl12345/identify_sdk/v2/age"
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
 )
 
@@ -16,25 +19,32 @@ type authGolden struct {
 	Proof       string `json:"proof"`
 	Commitment  string `json:"commitment"`
 	Salt        string `json:"salt"`
-	Challenge   int    `json:"challenge"`
-	BirthYear   int    `json:"birth_year"`
-	TargetYear  int    `json:"target_year"`
+	Challenge   string `json:"challenge"`
+	BirthDate   int    `json:"birth_date"`
+	TargetDate  int    `json:"target_date"`
+	AgeMode     string `json:"age_mode"`
 	LimitAge    int    `json:"limit_age"`
 	ParamsHash  string `json:"params_version"`
 	VerifyingID string `json:"vk_id"`
+	// Calldata is the input of the contract from identify-cli export-verifier.
+	Calldata *backend.Calldata `json:"solidity_calldata"`
 }
 
 type ageGolden struct {
 	Proof       string `json:"proof"`
-	BirthYear   int    `json:"birth_year"`
-	TargetYear  int    `json:"target_year"`
+	BirthDate   int    `json:"birth_date"`
+	TargetDate  int    `json:"target_date"`
+	AgeMode     string `json:"age_mode"`
 	LimitAge    int    `json:"limit_age"`
 	ParamsHash  string `json:"params_version"`
 	VerifyingID string `json:"vk_id"`
+	// Calldata is the input of the contract from identify-cli export-verifier.
+	Calldata *backend.Calldata `json:"solidity_calldata"`
 }
 
 func main() {
-	cfg := common.DefaultSharedConfig()
+	// Golden vectors pin today's date so the tests can rebuild the same config.
+	cfg := common.DefaultSharedConfigWithDate(common.DateOf(time.Now().UTC()))
 
 	authProver, err := auth.NewUserProverWithPolicy(auth.DefaultPolicy(), cfg)
 	if err != nil {
@@ -44,39 +54,59 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	authVerifier, err := auth.NewVerifierWithConfig(auth.VerifierConfig{Config: cfg})
+	if err != nil {
+		panic(err)
+	}
+	ageVerifier, err := age.NewVerifierWithConfig(age.VerifierConfig{Config: cfg})
+	if err != nil {
+		panic(err)
+	}
 
 	secret := "golden-secret"
 	salt := "deadbeefdeadbeefdeadbeefdeadbeef"
-	challenge := 4242
-	birthYear := 2000
+	challenge := commitment.DeriveChallenge("identify-golden")
+	birthDate := 20000101
 
-	proof, commitment, _, err := authProver.GenerateProof(secret, birthYear, cfg.TargetYear, cfg.LimitAge, challenge, salt)
+	authRes, err := authProver.GenerateProofResult(secret, birthDate, cfg.CurrentDate(), cfg.LimitAge, challenge, salt)
+	if err != nil {
+		panic(err)
+	}
+	authCalldata, err := authVerifier.SolidityCalldata(authRes, challenge, "")
 	if err != nil {
 		panic(err)
 	}
 	authOut := authGolden{
-		Proof:       hex.EncodeToString(proof),
-		Commitment:  commitment,
+		Proof:       hex.EncodeToString(authRes.Proof),
+		Commitment:  authRes.Commitment,
 		Salt:        salt,
 		Challenge:   challenge,
-		BirthYear:   birthYear,
-		TargetYear:  cfg.TargetYear,
+		BirthDate:   birthDate,
+		TargetDate:  cfg.CurrentDate(),
+		AgeMode:     cfg.AgeMode,
 		LimitAge:    cfg.LimitAge,
 		ParamsHash:  common.ParamsVersion(cfg),
 		VerifyingID: auth.VerifyingKeyID(),
+		Calldata:    authCalldata,
 	}
 
-	ageProof, err := ageProver.GenerateAgeProof(birthYear, cfg.TargetYear, cfg.LimitAge)
+	ageRes, err := ageProver.GenerateProofResult(birthDate, cfg.CurrentDate(), cfg.LimitAge)
+	if err != nil {
+		panic(err)
+	}
+	ageCalldata, err := ageVerifier.SolidityCalldata(ageRes)
 	if err != nil {
 		panic(err)
 	}
 	ageOut := ageGolden{
-		Proof:       hex.EncodeToString(ageProof),
-		BirthYear:   birthYear,
-		TargetYear:  cfg.TargetYear,
+		Proof:       hex.EncodeToString(ageRes.Proof),
+		BirthDate:   birthDate,
+		TargetDate:  cfg.CurrentDate(),
+		AgeMode:     cfg.AgeMode,
 		LimitAge:    cfg.LimitAge,
 		ParamsHash:  common.ParamsVersion(cfg),
 		VerifyingID: age.AgeVerifyingKeyID(),
+		Calldata:    ageCalldata,
 	}
 
 	writeJSON("auth/testdata/golden.json", authOut)
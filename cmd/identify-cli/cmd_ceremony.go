@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/age"
+	"github.com/ghdehrl12345/identify_sdk/v2/attribute"
+	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/ceremony"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+	"github.com/ghdehrl12345/identify_sdk/v2/membership"
+)
+
+// ceremonyCircuits maps ceremony circuit names to circuits and the key file
+// names they are embedded under.
+var ceremonyCircuits = map[string]struct {
+	circuit frontend.Circuit
+	file    string
+}{
+	auth.CircuitAgeLogin:  {&auth.UserCircuit{}, "user"},
+	auth.CircuitLogin:     {&auth.LoginCircuit{}, "login"},
+	"age":                 {&age.AgeCircuit{}, "age"},
+	"age-range":           {&age.AgeRangeCircuit{}, "age_range"},
+	"age-credential":      {&age.CredentialAgeCircuit{}, "age_credential"},
+	attribute.CircuitName: {&attribute.PredicateCircuit{}, "attribute"},
+
+	auth.CircuitAgeLogin + "-poseidon2": {&auth.UserCircuit{Hash: hasher.Poseidon2}, "user_poseidon2"},
+	auth.CircuitLogin + "-poseidon2":    {&auth.LoginCircuit{Hash: hasher.Poseidon2}, "login_poseidon2"},
+
+	auth.CircuitChangeSecret:                {&auth.ChangeSecretCircuit{}, "change_secret"},
+	auth.CircuitChangeSecret + "-poseidon2": {&auth.ChangeSecretCircuit{Hash: hasher.Poseidon2}, "change_secret_poseidon2"},
+
+	membership.CircuitName:                {&membership.MembershipCircuit{}, "membership"},
+	membership.CircuitName + "-poseidon2": {&membership.MembershipCircuit{Hash: hasher.Poseidon2}, "membership_poseidon2"},
+
+	membership.NullifierCircuitName:                {&membership.NullifierCircuit{}, "nullifier"},
+	membership.NullifierCircuitName + "-poseidon2": {&membership.NullifierCircuit{Hash: hasher.Poseidon2}, "nullifier_poseidon2"},
+}
+
+func cmdCeremony(args []string) {
+	if len(args) < 1 {
+		printCeremonyUsage()
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "init":
+		cmdCeremonyInit(args[1:])
+	case "contribute":
+		cmdCeremonyContribute(args[1:])
+	case "verify":
+		cmdCeremonyVerify(args[1:])
+	case "finalize":
+		cmdCeremonyFinalize(args[1:])
+	case "help", "-h", "--help":
+		printCeremonyUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown ceremony command '%s'\n\n", args[0])
+		printCeremonyUsage()
+		os.Exit(1)
+	}
+}
+
+func printCeremonyUsage() {
+	fmt.Println(`identify-cli ceremony - Multi-party Groth16 trusted setup
+
+Usage:
+  identify-cli ceremony init       --dir <dir> --circuit <age-login|login|age|age-range|age-credential|attribute|age-login-poseidon2|login-poseidon2|change-secret|change-secret-poseidon2|membership|membership-poseidon2|nullifier|nullifier-poseidon2>
+  identify-cli ceremony contribute --dir <dir> --name <contributor>
+  identify-cli ceremony verify     --dir <dir>
+  identify-cli ceremony finalize   --dir <dir> --beacon <hex> [--output <dir>]
+
+Flow:
+  1. The coordinator runs init and shares the directory.
+  2. Each team runs contribute in turn and passes the directory on.
+  3. The coordinator runs finalize with a public beacon to close phase 1.
+  4. Teams contribute again for phase 2 (circuit-specific).
+  5. finalize with a second beacon writes <name>.pk / <name>.vk.
+  Anyone can run verify at any point; the keys are safe as long as one
+  contributor discarded their randomness.`)
+}
+
+func cmdCeremonyInit(args []string) {
+	fs := flag.NewFlagSet("ceremony init", flag.ExitOnError)
+	dir := fs.String("dir", "ceremony", "Ceremony directory")
+	circuit := fs.String("circuit", auth.CircuitAgeLogin, "Circuit: age-login, login, age, age-range, age-credential, attribute, age-login-poseidon2, login-poseidon2, change-secret, change-secret-poseidon2, membership, membership-poseidon2, nullifier, nullifier-poseidon2")
+	fs.Parse(args)
+
+	ccs := compileCeremonyCircuit(*circuit)
+	t, err := ceremony.Init(*dir, *circuit, ccs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2005: Failed to initialize ceremony: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Ceremony initialized in %s\n", *dir)
+	fmt.Printf("   Circuit:      %s (%d constraints, domain %d)\n", t.Circuit, ccs.GetNbConstraints(), t.DomainSize)
+	fmt.Printf("   Circuit hash: %s\n", t.CircuitHash)
+}
+
+func cmdCeremonyContribute(args []string) {
+	fs := flag.NewFlagSet("ceremony contribute", flag.ExitOnError)
+	dir := fs.String("dir", "ceremony", "Ceremony directory")
+	name := fs.String("name", "", "Contributor name recorded in the transcript")
+	fs.Parse(args)
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "E1010: Missing required arguments")
+		fmt.Fprintln(os.Stderr, "\nUsage: identify-cli ceremony contribute --dir <dir> --name <contributor>")
+		os.Exit(1)
+	}
+	ccs := loadCeremonyCircuit(*dir)
+	fmt.Println(">> Verifying previous contributions and adding randomness...")
+	c, err := ceremony.Contribute(*dir, *name, ccs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2007: Contribution failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Contribution #%d recorded: %s\n", c.Index, c.File)
+	fmt.Printf("   Hash: %s\n", c.Hash)
+	fmt.Println("   Publish this hash so others can check it against the transcript.")
+}
+
+func cmdCeremonyVerify(args []string) {
+	fs := flag.NewFlagSet("ceremony verify", flag.ExitOnError)
+	dir := fs.String("dir", "ceremony", "Ceremony directory")
+	fs.Parse(args)
+
+	ccs := loadCeremonyCircuit(*dir)
+	t, err := ceremony.Verify(*dir, ccs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2007: Transcript verification failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Transcript valid (circuit %s, phase %d)\n", t.Circuit, t.Phase)
+	for _, c := range t.Phase1 {
+		fmt.Printf("   phase 1 #%d %-20s %s\n", c.Index, c.Name, c.Hash)
+	}
+	for _, c := range t.Phase2 {
+		fmt.Printf("   phase 2 #%d %-20s %s\n", c.Index, c.Name, c.Hash)
+	}
+	if t.Phase == ceremony.PhaseDone {
+		fmt.Printf("   Proving Key ID:   %s\n   Verifying Key ID: %s\n", t.PKID, t.VKID)
+	}
+}
+
+func cmdCeremonyFinalize(args []string) {
+	fs := flag.NewFlagSet("ceremony finalize", flag.ExitOnError)
+	dir := fs.String("dir", "ceremony", "Ceremony directory")
+	beaconHex := fs.String("beacon", "", "Public random beacon (hex), published after the last contribution")
+	output := fs.String("output", "", "Output directory for the final keys (default: ceremony directory)")
+	fs.Parse(args)
+
+	beacon, err := hex.DecodeString(*beaconHex)
+	if err != nil || len(beacon) == 0 {
+		fmt.Fprintln(os.Stderr, "E1010: --beacon must be a non-empty hex string")
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = *dir
+	}
+
+	t, err := ceremony.Load(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2003: Failed to read transcript: %v\n", err)
+		os.Exit(1)
+	}
+	ccs := loadCeremonyCircuit(*dir)
+	file := ceremonyCircuits[t.Circuit].file
+	t, err = ceremony.Finalize(*dir, ccs, beacon,
+		filepath.Join(*output, file+".pk"), filepath.Join(*output, file+".vk"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2007: Finalize failed: %v\n", err)
+		os.Exit(1)
+	}
+	if t.Phase == ceremony.PhaseCircuit {
+		fmt.Println("✅ Phase 1 closed; phase 2 contributions can start.")
+		return
+	}
+	fmt.Println("✅ Ceremony complete")
+	fmt.Printf(">> Written: %s\n>> Written: %s\n", t.ProvingKey, t.VerifyingKey)
+	fmt.Printf("   Proving Key ID:   %s\n   Verifying Key ID: %s\n", t.PKID, t.VKID)
+}
+
+func compileCeremonyCircuit(name string) constraint.ConstraintSystem {
+	c, ok := ceremonyCircuits[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "E2001: Unknown circuit %q\n", name)
+		os.Exit(1)
+	}
+	ccs, err := backend.Compile(backend.Groth16, c.circuit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2001: Failed to compile %s circuit: %v\n", name, err)
+		os.Exit(1)
+	}
+	return ccs
+}
+
+func loadCeremonyCircuit(dir string) constraint.ConstraintSystem {
+	t, err := ceremony.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2003: Failed to read transcript: %v\n", err)
+		os.Exit(1)
+	}
+	return compileCeremonyCircuit(t.Circuit)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/age"
+	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+)
+
+func cmdExportVerifier(args []string) {
+	fs := flag.NewFlagSet("export-verifier", flag.ExitOnError)
+	circuit := fs.String("circuit", "", "Circuit to export: age, auth (age-login) or login")
+	format := fs.String("format", "solidity", "Output format (solidity)")
+	vkPath := fs.String("vk", "", "Groth16 verifying key file (default: embedded key)")
+	output := fs.String("output", "", "Output file (default: stdout)")
+	fs.Parse(args)
+
+	if *circuit == "" {
+		fmt.Fprintln(os.Stderr, "E1010: Missing required arguments")
+		fmt.Fprintln(os.Stderr, "\nUsage: identify-cli export-verifier --circuit <age|auth|login> [--format solidity] [--vk <file>] [--output <file>]")
+		os.Exit(1)
+	}
+	if *format != "solidity" {
+		fmt.Fprintf(os.Stderr, "E4003: Unsupported format %q (supported: solidity)\n", *format)
+		os.Exit(1)
+	}
+
+	var export func(io.Writer) error
+	switch {
+	case *vkPath != "":
+		data, err := os.ReadFile(*vkPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2003: Failed to read verifying key: %v\n", err)
+			os.Exit(1)
+		}
+		vk, err := backend.ReadVerifyingKey(backend.Groth16, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2001: Failed to parse verifying key: %v\n", err)
+			os.Exit(1)
+		}
+		export = func(w io.Writer) error { return backend.ExportSolidity(vk, w) }
+	case *circuit == "age":
+		verifier, err := age.NewVerifier()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E1004: Failed to initialize verifier: %v\n", err)
+			os.Exit(1)
+		}
+		export = verifier.ExportSolidity
+	case *circuit == "auth" || *circuit == auth.CircuitAgeLogin || *circuit == auth.CircuitLogin:
+		name := auth.CircuitAgeLogin
+		if *circuit == auth.CircuitLogin {
+			name = auth.CircuitLogin
+		}
+		verifier, err := auth.NewVerifierWithConfig(auth.VerifierConfig{Circuit: name})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E1004: Failed to initialize verifier: %v\n", err)
+			os.Exit(1)
+		}
+		export = verifier.ExportSolidity
+	default:
+		fmt.Fprintf(os.Stderr, "E4003: Unknown circuit %q (expected age, auth or login)\n", *circuit)
+		os.Exit(1)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2002: Failed to create %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := export(w); err != nil {
+		fmt.Fprintf(os.Stderr, "E2002: Failed to export verifier: %v\n", err)
+		os.Exit(1)
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, ">> Written: %s\n", *output)
+	}
+}
@@ -7,52 +7,67 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/ghdehrl12345/identify_sdk/v2/age"
+	"github.com/ghdehrl12345/identify_sdk/v2/attribute"
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+	"github.com/ghdehrl12345/identify_sdk/v2/membership"
 )
 
+type keyTarget struct {
+	name    string
+	backend string
+	circuit frontend.Circuit
+	file    string // key file name without extension
+	code    string // error code for compile/setup failures
+}
+
 func cmdGenerateKeys(args []string) {
 	fs := flag.NewFlagSet("generate-keys", flag.ExitOnError)
 	output := fs.String("output", ".", "Output directory for key files")
+	backendFlag := fs.String("backend", backend.Groth16, "Proving backend: groth16, plonk or all")
+	srsPath := fs.String("srs", "", "KZG SRS file for plonk (generated into the output directory if empty)")
+	raw := fs.Bool("raw", false, "Write keys uncompressed: about twice the size, several times faster to load")
+	production := fs.Bool("production", false, "Require a public-ceremony SRS for plonk: refuse generated and shipped test SRS files")
 	fs.Parse(args)
 
-	fmt.Println("🔨 [generate-keys] Compiling circuits and generating keys...")
-
-	// Compile auth circuit
-	var authCircuit auth.UserCircuit
-	authCCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &authCircuit)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "E2001: Failed to compile auth circuit: %v\n", err)
-		os.Exit(1)
-	}
-	fmt.Printf(">> Auth circuit compiled (constraints: %d)\n", authCCS.GetNbConstraints())
-
-	// Compile age circuit
-	var ageCircuit age.AgeCircuit
-	ageCCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &ageCircuit)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "E2002: Failed to compile age circuit: %v\n", err)
-		os.Exit(1)
-	}
-	fmt.Printf(">> Age circuit compiled (constraints: %d)\n", ageCCS.GetNbConstraints())
-
-	// Setup keys
-	authPK, authVK, err := groth16.Setup(authCCS)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "E2003: Failed to setup auth keys: %v\n", err)
-		os.Exit(1)
+	targets := []keyTarget{
+		{"auth", backend.Groth16, &auth.UserCircuit{}, "user", "E2001"},
+		{"login", backend.Groth16, &auth.LoginCircuit{}, "login", "E2001"},
+		{"age", backend.Groth16, &age.AgeCircuit{}, "age", "E2002"},
+		{"age range", backend.Groth16, &age.AgeRangeCircuit{}, "age_range", "E2002"},
+		{"credential age", backend.Groth16, &age.CredentialAgeCircuit{}, "age_credential", "E2002"},
+		{"attribute", backend.Groth16, &attribute.PredicateCircuit{}, "attribute", "E2002"},
+		{"auth", backend.PLONK, &auth.UserCircuit{}, "user_plonk", "E2001"},
+		{"login", backend.PLONK, &auth.LoginCircuit{}, "login_plonk", "E2001"},
+		{"age", backend.PLONK, &age.AgeCircuit{}, "age_plonk", "E2002"},
+		{"auth (poseidon2)", backend.Groth16, &auth.UserCircuit{Hash: hasher.Poseidon2}, "user_poseidon2", "E2001"},
+		{"login (poseidon2)", backend.Groth16, &auth.LoginCircuit{Hash: hasher.Poseidon2}, "login_poseidon2", "E2001"},
+		{"auth (poseidon2)", backend.PLONK, &auth.UserCircuit{Hash: hasher.Poseidon2}, "user_poseidon2_plonk", "E2001"},
+		{"login (poseidon2)", backend.PLONK, &auth.LoginCircuit{Hash: hasher.Poseidon2}, "login_poseidon2_plonk", "E2001"},
+		{"change-secret", backend.Groth16, &auth.ChangeSecretCircuit{}, "change_secret", "E2001"},
+		{"change-secret (poseidon2)", backend.Groth16, &auth.ChangeSecretCircuit{Hash: hasher.Poseidon2}, "change_secret_poseidon2", "E2001"},
+		{"membership", backend.Groth16, &membership.MembershipCircuit{}, "membership", "E2001"},
+		{"membership (poseidon2)", backend.Groth16, &membership.MembershipCircuit{Hash: hasher.Poseidon2}, "membership_poseidon2", "E2001"},
+		{"nullifier", backend.Groth16, &membership.NullifierCircuit{}, "nullifier", "E2001"},
+		{"nullifier (poseidon2)", backend.Groth16, &membership.NullifierCircuit{Hash: hasher.Poseidon2}, "nullifier_poseidon2", "E2001"},
 	}
-
-	agePK, ageVK, err := groth16.Setup(ageCCS)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "E2004: Failed to setup age keys: %v\n", err)
-		os.Exit(1)
+	if *backendFlag != "all" {
+		name, err := backend.Normalize(*backendFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2001: %v\n", err)
+			os.Exit(1)
+		}
+		var selected []keyTarget
+		for _, t := range targets {
+			if t.backend == name {
+				selected = append(selected, t)
+			}
+		}
+		targets = selected
 	}
-	fmt.Println(">> Keys generated successfully")
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(*output, 0755); err != nil {
@@ -60,6 +75,13 @@ func cmdGenerateKeys(args []string) {
 		os.Exit(1)
 	}
 
+	fmt.Println("🔨 [generate-keys] Compiling circuits and generating keys...")
+
+	var srs *backend.SRS
+	if *backendFlag != backend.Groth16 {
+		srs = loadSRS(*srsPath, *output, *production)
+	}
+
 	// Write key files
 	writeKey := func(path string, writeFn func(io.Writer) (int64, error)) error {
 		f, err := os.Create(path)
@@ -71,20 +93,85 @@ func cmdGenerateKeys(args []string) {
 		return err
 	}
 
-	files := map[string]func(io.Writer) (int64, error){
-		filepath.Join(*output, "user.pk"): authPK.WriteTo,
-		filepath.Join(*output, "user.vk"): authVK.WriteTo,
-		filepath.Join(*output, "age.pk"):  agePK.WriteTo,
-		filepath.Join(*output, "age.vk"):  ageVK.WriteTo,
-	}
+	for _, t := range targets {
+		ccs, err := backend.Compile(t.backend, t.circuit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: Failed to compile %s circuit (%s): %v\n", t.code, t.name, t.backend, err)
+			os.Exit(1)
+		}
+		fmt.Printf(">> %s circuit compiled for %s (constraints: %d)\n", t.name, t.backend, ccs.GetNbConstraints())
 
-	for path, fn := range files {
-		if err := writeKey(path, fn); err != nil {
-			fmt.Fprintf(os.Stderr, "E2006: Failed to write %s: %v\n", path, err)
+		pk, vk, err := backend.Setup(t.backend, ccs, srs)
+		if err != nil {
+			setupCode := "E2003"
+			if t.code == "E2002" {
+				setupCode = "E2004"
+			}
+			fmt.Fprintf(os.Stderr, "%s: Failed to setup %s keys (%s): %v\n", setupCode, t.name, t.backend, err)
 			os.Exit(1)
 		}
-		fmt.Printf(">> Written: %s\n", path)
+
+		writePK, writeVK := pk.WriteTo, vk.WriteTo
+		if *raw {
+			writePK, writeVK = pk.WriteRawTo, vk.WriteRawTo
+		}
+		for path, fn := range map[string]func(io.Writer) (int64, error){
+			filepath.Join(*output, t.file+".pk"): writePK,
+			filepath.Join(*output, t.file+".vk"): writeVK,
+		} {
+			if err := writeKey(path, fn); err != nil {
+				fmt.Fprintf(os.Stderr, "E2006: Failed to write %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf(">> Written: %s\n", path)
+		}
 	}
 
 	fmt.Println("✅ [generate-keys] All keys generated successfully!")
 }
+
+// loadSRS reads the KZG SRS at path, or samples a fresh test-only one into dir
+// when path is empty. With production, test-only SRS files are refused.
+func loadSRS(path string, dir string, production bool) *backend.SRS {
+	if path == "" && production {
+		fmt.Fprintln(os.Stderr, "E2003: -production requires -srs with a public-ceremony SRS")
+		os.Exit(1)
+	}
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2003: Failed to open SRS: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		srs, err := backend.ReadSRS(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E2003: %v\n", err)
+			os.Exit(1)
+		}
+		if production && srs.TestOnly() {
+			fmt.Fprintf(os.Stderr, "E2003: %s is a test-only SRS; use a public-ceremony SRS\n", path)
+			os.Exit(1)
+		}
+		return srs
+	}
+
+	srs, err := backend.NewSRS(1 << 15)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2003: Failed to generate SRS: %v\n", err)
+		os.Exit(1)
+	}
+	out := filepath.Join(dir, "kzg_bn254.srs")
+	f, err := os.Create(out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E2006: Failed to write %s: %v\n", out, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if _, err := srs.WriteTo(f); err != nil {
+		fmt.Fprintf(os.Stderr, "E2006: Failed to write %s: %v\n", out, err)
+		os.Exit(1)
+	}
+	fmt.Printf(">> Written: %s (generated test-only SRS, toxic value discarded)\n", out)
+	return srs
+}
@@ -16,12 +16,15 @@ func cmdMigrate(args []string) {
 	oldCommitment := fs.String("old-commitment", "", "Old v1 commitment for verification (optional)")
 	v1Iter := fs.Uint("v1-iterations", 1, "v1 Argon2 iterations")
 	v2Iter := fs.Uint("v2-iterations", 3, "v2 Argon2 iterations")
+	birthDate := fs.Int("birth-date", 0, "Birth date (YYYYMMDD) to bind into a scheme v2 commitment (optional)")
+	record := fs.String("record", "", "Stored commitment record ($idz-...) to verify and re-create; replaces --salt and --old-commitment")
+	scheme := fs.Int("scheme", commitment.CurrentScheme, "Target commitment scheme for --record (2: MiMC, 3: Poseidon2)")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 
 	fs.Parse(args)
 
-	if *secret == "" || *salt == "" {
-		fmt.Fprintln(os.Stderr, "Error: --secret and --salt are required")
+	if *secret == "" || (*salt == "" && *record == "") {
+		fmt.Fprintln(os.Stderr, "Error: --secret and --salt (or --record) are required")
 		fs.Usage()
 		os.Exit(1)
 	}
@@ -34,7 +37,16 @@ func cmdMigrate(args []string) {
 	}
 
 	var result commitment.MigrationResult
-	if *oldCommitment != "" {
+	if *record != "" {
+		old, err := commitment.ParseRecord(*record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E1002: Invalid record: %v\n", err)
+			os.Exit(1)
+		}
+		result = commitment.MigrateRecord(*secret, *birthDate, old, *scheme, cfg.V2Config())
+	} else if *birthDate != 0 {
+		result = commitment.MigrateToBirthBound(*secret, *salt, *birthDate, *oldCommitment, cfg)
+	} else if *oldCommitment != "" {
 		result = commitment.VerifyAndMigrate(*secret, *salt, *oldCommitment, cfg)
 	} else {
 		result = commitment.MigrateCommitment(*secret, *salt, cfg)
@@ -47,6 +59,9 @@ func cmdMigrate(args []string) {
 			"new_commitment": result.NewCommitment,
 			"salt":           result.Salt,
 		}
+		if result.Success {
+			out["record"] = result.Record.String()
+		}
 		if result.Error != nil {
 			out["error"] = result.Error.Error()
 		}
@@ -61,9 +76,10 @@ func cmdMigrate(args []string) {
 
 	fmt.Println("Migration successful!")
 	fmt.Println()
-	fmt.Println("Old commitment (v1):", result.OldCommitment)
-	fmt.Println("New commitment (v2):", result.NewCommitment)
+	fmt.Println("Old commitment:", result.OldCommitment)
+	fmt.Println("New commitment:", result.NewCommitment)
 	fmt.Println("Salt:", result.Salt)
+	fmt.Println("Record:", result.Record)
 	fmt.Println()
-	fmt.Println("⚠️  Update your database with the new commitment value.")
+	fmt.Println("⚠️  Update your database with the new record (or commitment value).")
 }
@@ -8,33 +8,54 @@ import (
 
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
 )
 
 func cmdVerify(args []string) {
 	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	envelopePath := fs.String("envelope", "", "Proof envelope file (JSON or CBOR); replaces --proof/--commitment/--salt")
 	proofHex := fs.String("proof", "", "Proof bytes in hex format")
 	commitment := fs.String("commitment", "", "Public commitment (decimal string)")
 	salt := fs.String("salt", "", "Salt in hex format")
-	challenge := fs.Int("challenge", 0, "Challenge value")
+	challenge := fs.String("challenge", "", "Challenge value (decimal or 0x-prefixed hex)")
 	targetYear := fs.Int("year", 2025, "Target year for age verification")
+	targetDate := fs.Int("date", 0, "Target date (YYYYMMDD) for age verification (default: Dec 31 of --year)")
+	ageMode := fs.String("age-mode", common.AgeModeInternational, "Age counting mode (international|korean-year)")
 	limitAge := fs.Int("age", 20, "Minimum age requirement")
 	fs.Parse(args)
 
-	if *proofHex == "" || *commitment == "" || *salt == "" {
+	if *challenge == "" || (*envelopePath == "" && (*proofHex == "" || *commitment == "" || *salt == "")) {
 		fmt.Fprintln(os.Stderr, "E1010: Missing required arguments")
-		fmt.Fprintln(os.Stderr, "\nUsage: identify-cli verify --proof <hex> --commitment <decimal> --salt <hex> --challenge <int>")
+		fmt.Fprintln(os.Stderr, "\nUsage: identify-cli verify --proof <hex> --commitment <decimal> --salt <hex> --challenge <decimal|0xhex>")
+		fmt.Fprintln(os.Stderr, "       identify-cli verify --envelope <file> --challenge <decimal|0xhex>")
 		os.Exit(1)
 	}
 
-	proofBytes, err := hex.DecodeString(*proofHex)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "E1001: Invalid proof format: %v\n", err)
-		os.Exit(1)
+	var env envelope.Envelope
+	var proofBytes []byte
+	if *envelopePath != "" {
+		data, err := os.ReadFile(*envelopePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "E1010: Failed to read envelope: %v\n", err)
+			os.Exit(1)
+		}
+		if env, err = envelope.Decode(data); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		var err error
+		if proofBytes, err = hex.DecodeString(*proofHex); err != nil {
+			fmt.Fprintf(os.Stderr, "E1001: Invalid proof format: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	cfg := common.SharedConfig{
 		TargetYear:      *targetYear,
+		TargetDate:      *targetDate,
 		LimitAge:        *limitAge,
+		AgeMode:         *ageMode,
 		ArgonMemory:     common.ArgonMemory,
 		ArgonIterations: common.ArgonIterations,
 	}
@@ -45,7 +66,12 @@ func cmdVerify(args []string) {
 		os.Exit(1)
 	}
 
-	ok, err := verifier.VerifyLogin(proofBytes, *commitment, *salt, *challenge)
+	var ok bool
+	if *envelopePath != "" {
+		ok, err = verifier.VerifyEnvelope(env, *challenge, "")
+	} else {
+		ok, err = verifier.VerifyLogin(proofBytes, *commitment, *salt, *challenge)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "E1003: Verification failed: %v\n", err)
 		os.Exit(1)
@@ -16,6 +16,10 @@ func main() {
 	switch os.Args[1] {
 	case "generate-keys":
 		cmdGenerateKeys(os.Args[2:])
+	case "ceremony":
+		cmdCeremony(os.Args[2:])
+	case "export-verifier":
+		cmdExportVerifier(os.Args[2:])
 	case "verify":
 		cmdVerify(os.Args[2:])
 	case "migrate":
@@ -39,15 +43,22 @@ Usage:
 
 Commands:
   generate-keys   Generate proving and verifying keys
+  ceremony        Multi-party trusted setup (init, contribute, verify, finalize)
+  export-verifier Export an on-chain (Solidity) verifier contract
   verify          Verify a ZKP proof
-  migrate         Migrate v1 commitments to v2 (Argon2 upgrade)
+  migrate         Migrate commitments (Argon2 upgrade, birth-date binding)
   version         Show version information
   help            Show this help message
 
 Examples:
   identify-cli generate-keys --output ./keys
+  identify-cli generate-keys --output ./keys --raw
+  identify-cli ceremony contribute --dir ./ceremony --name "team-a"
+  identify-cli export-verifier --circuit age --format solidity --output AgeVerifier.sol
   identify-cli verify --proof proof.hex --commitment "123..." --salt "abc..." --challenge 4242
+  identify-cli verify --envelope proof.json --challenge 4242
   identify-cli migrate --secret "password" --salt "abc123..." --old-commitment "123..."
+  identify-cli migrate --secret "password" --salt "abc123..." --birth-date 19900101
   identify-cli version
 
 Run 'identify-cli <command> --help' for more information on a command.`)
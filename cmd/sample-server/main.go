@@ -1,46 +1,67 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log"
 	"math/big"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ghdehrl12345/identify_sdk/v2/age"
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/envelope"
 	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
 )
 
 type policyResponse struct {
 	Config struct {
 		TargetYear      int    `json:"target_year"`
+		TargetDate      int    `json:"target_date"`
+		DynamicDate     bool   `json:"dynamic_date,omitempty"` // target_date is today's date and moves daily
 		LimitAge        int    `json:"limit_age"`
+		AgeMode         string `json:"age_mode"`
 		ArgonMemory     uint32 `json:"argon_memory"`
 		ArgonIterations uint32 `json:"argon_iterations"`
 	} `json:"config"`
 	ParamsVersion string `json:"params_version"`
 	VKID          string `json:"vk_id"`
+	AgeMode       string `json:"age_mode"`
+	Circuit       string `json:"circuit"`
+	Backend       string `json:"backend"`
+	Scheme        int    `json:"scheme"`
+	ProofVersion  string `json:"proof_version"`
 }
 
 type provingKeyResponse struct {
 	KeyType      string `json:"key_type"`
+	Backend      string `json:"backend"`
+	Scheme       int    `json:"scheme,omitempty"`
 	ProvingKey   string `json:"proving_key"` // base64
 	PKID         string `json:"pk_id"`
 	ProofVersion string `json:"proof_version"`
 }
 
 type challengeRequest struct {
-	UserID string `json:"user_id"`
-	Salt   string `json:"salt"`
+	UserID       string `json:"user_id"`
+	Salt         string `json:"salt"`
+	TokenVersion string `json:"token_version,omitempty"` // "ct-v1" for legacy clients
+	SessionKey   string `json:"session_key,omitempty"`   // client-generated Ed25519 session public key (hex)
 }
 
 type challengeResponse struct {
 	ChallengeToken string `json:"challenge_token"`
+	Challenge      string `json:"challenge"` // decimal field element
+	ChannelBinding string `json:"channel_binding,omitempty"`
 	Salt           string `json:"salt"`
 	VKID           string `json:"vk_id"`
 	ParamsVersion  string `json:"params_version"`
@@ -49,20 +70,44 @@ type challengeResponse struct {
 }
 
 type verifyRequest struct {
+	ChallengeToken string          `json:"challenge_token"`
+	Envelope       json.RawMessage `json:"envelope,omitempty"` // replaces proof, commitment, salt, vk_id and params_version
+	Proof          string          `json:"proof"`
+	Commitment     string          `json:"commitment"`
+	Salt           string          `json:"salt"`
+	VKID           string          `json:"vk_id"`
+	ParamsVersion  string          `json:"params_version"`
+	SessionKey     string          `json:"session_key,omitempty"`
+	SessionSig     string          `json:"session_sig,omitempty"` // hex Ed25519 signature over challenge_token by session_key
+}
+
+type changeSecretRequest struct {
 	ChallengeToken string `json:"challenge_token"`
 	Proof          string `json:"proof"`
-	Commitment     string `json:"commitment"`
-	Salt           string `json:"salt"`
-	VKID           string `json:"vk_id"`
-	ParamsVersion  string `json:"params_version"`
+	OldCommitment  string `json:"old_commitment"`
+	OldSalt        string `json:"old_salt"`
+	NewCommitment  string `json:"new_commitment"`
+	NewSalt        string `json:"new_salt"`
+	SessionKey     string `json:"session_key,omitempty"`
+	SessionSig     string `json:"session_sig,omitempty"` // as in verifyRequest
 }
 
 type verifyResponse struct {
-	OK      bool   `json:"ok"`
-	ErrCode string `json:"err_code,omitempty"`
-	ErrMsg  string `json:"err_msg,omitempty"`
+	OK       bool   `json:"ok"`
+	ErrCode  string `json:"err_code,omitempty"`
+	ErrStage string `json:"err_stage,omitempty"`
+	ErrMsg   string `json:"err_msg,omitempty"`
 }
 
+// verifyTimeout bounds how long a handler waits for a proof verification; a
+// request whose client disconnects is abandoned earlier through the request
+// context. Cancellation frees the handler, not the CPU: gnark cannot be
+// interrupted, so an abandoned verification runs to completion in the
+// background and holds one of the backend's work slots until it does (see
+// backend.SetMaxConcurrentWork). Rate limiting, not the timeout, is what
+// protects the server from floods of expensive proofs.
+const verifyTimeout = 5 * time.Second
+
 func main() {
 	cfg := common.DefaultSharedConfig()
 	tokenKey := []byte(os.Getenv("CHALLENGE_TOKEN_KEY"))
@@ -72,9 +117,21 @@ func main() {
 		log.Fatal("CHALLENGE_TOKEN_KEY is required")
 	}
 
+	scheme := 0
+	if v := os.Getenv("AUTH_SCHEME"); v != "" { // "3" for Poseidon2 commitments
+		var err error
+		if scheme, err = strconv.Atoi(v); err != nil {
+			log.Fatalf("invalid AUTH_SCHEME: %v", err)
+		}
+	}
+
 	verifier, err := auth.NewVerifierWithConfig(auth.VerifierConfig{
-		Config:   cfg,
-		TokenKey: tokenKey,
+		Config:     cfg,
+		TokenKey:   tokenKey,
+		Circuit:    os.Getenv("AUTH_CIRCUIT"), // "login" for services without an age requirement
+		Backend:    os.Getenv("AUTH_BACKEND"), // "plonk" to accept PLONK proofs instead of Groth16
+		Scheme:     scheme,
+		TokenStore: auth.NewMemoryTokenStore(), // single-use login and change-secret tokens
 	})
 	if err != nil {
 		log.Fatalf("verifier init failed: %v", err)
@@ -87,12 +144,20 @@ func main() {
 		}
 		bundle := verifier.PolicyBundle()
 		var resp policyResponse
-		resp.Config.TargetYear = bundle.Config.TargetYear
+		resp.Config.TargetDate = bundle.Config.CurrentDate()
+		resp.Config.TargetYear = resp.Config.TargetDate / 10000
+		resp.Config.DynamicDate = bundle.Config.DynamicDate
 		resp.Config.LimitAge = bundle.Config.LimitAge
+		resp.Config.AgeMode = bundle.Config.AgeMode
 		resp.Config.ArgonMemory = bundle.Config.ArgonMemory
 		resp.Config.ArgonIterations = bundle.Config.ArgonIterations
 		resp.ParamsVersion = bundle.ParamsVersion
 		resp.VKID = bundle.VKID
+		resp.AgeMode = bundle.AgeMode
+		resp.Circuit = bundle.Circuit
+		resp.Backend = bundle.Backend
+		resp.Scheme = bundle.Scheme
+		resp.ProofVersion = bundle.ProofVersion
 		writeJSON(w, resp)
 	})
 
@@ -102,20 +167,56 @@ func main() {
 			return
 		}
 		keyType := r.URL.Query().Get("type")
+		keyBackend := r.URL.Query().Get("backend")
+		if keyBackend == "" {
+			keyBackend = verifier.Backend()
+		}
+		if _, err := backend.Normalize(keyBackend); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if keyType == "age" {
+			pkID := age.AgeProvingKeyID()
+			if keyBackend == backend.PLONK {
+				pkID = age.EmbeddedAgePlonkProvingKeyID
+			}
 			writeJSON(w, provingKeyResponse{
 				KeyType:      "age",
-				ProvingKey:   age.ProvingKeyBase64(),
-				PKID:         age.AgeProvingKeyID(),
-				ProofVersion: age.ProofVersion,
+				Backend:      keyBackend,
+				ProvingKey:   age.ProvingKeyBase64For(keyBackend),
+				PKID:         pkID,
+				ProofVersion: backend.ProofVersion(age.ProofVersion, keyBackend),
 			})
 			return
 		}
+		if keyType == "age-range" {
+			// Age range proofs are Groth16-only.
+			writeJSON(w, provingKeyResponse{
+				KeyType:      keyType,
+				Backend:      backend.Groth16,
+				ProvingKey:   age.RangeProvingKeyBase64(),
+				PKID:         age.RangeProvingKeyID(),
+				ProofVersion: age.RangeProofVersion,
+			})
+			return
+		}
+		circuit := auth.CircuitAgeLogin
+		switch keyType {
+		case "login":
+			circuit = auth.CircuitLogin
+		case auth.CircuitChangeSecret:
+			// Change-secret proofs are Groth16-only.
+			circuit, keyBackend = auth.CircuitChangeSecret, backend.Groth16
+		default:
+			keyType = "auth"
+		}
 		writeJSON(w, provingKeyResponse{
-			KeyType:      "auth",
-			ProvingKey:   auth.ProvingKeyBase64(),
-			PKID:         auth.ProvingKeyID(),
-			ProofVersion: auth.ProofVersion,
+			KeyType:      keyType,
+			Backend:      keyBackend,
+			Scheme:       verifier.Scheme(),
+			ProvingKey:   auth.ProvingKeyBase64ForScheme(circuit, keyBackend, verifier.Scheme()),
+			PKID:         auth.ProvingKeyIDForScheme(circuit, keyBackend, verifier.Scheme()),
+			ProofVersion: auth.ProofVersionFor(circuit, keyBackend, verifier.Scheme()),
 		})
 	})
 
@@ -134,19 +235,34 @@ func main() {
 			return
 		}
 
-		challenge, err := cryptoRandInt(1_000_000)
-		if err != nil {
-			http.Error(w, "failed to generate challenge", http.StatusInternalServerError)
-			return
-		}
 		claims := auth.ChallengeTokenClaims{
 			UserID:        req.UserID,
-			Challenge:     challenge,
 			ExpiresAt:     time.Now().Add(2 * time.Minute).Unix(),
-			VKID:          auth.VerifyingKeyID(),
+			VKID:          verifier.VerifyingKeyID(),
 			ParamsVersion: common.ParamsVersion(cfg),
 			KeyID:         kid,
 		}
+		var err error
+		if req.TokenVersion == auth.ChallengeTokenVersionV1 {
+			// Clients built before ct-v2 expect a small numeric challenge.
+			var n int
+			n, err = cryptoRandInt(1_000_000)
+			claims.Challenge = strconv.Itoa(n)
+			claims.Version = auth.ChallengeTokenVersionV1
+		} else {
+			claims.Challenge, err = commitment.NewChallenge()
+		}
+		if err != nil {
+			http.Error(w, "failed to generate challenge", http.StatusInternalServerError)
+			return
+		}
+		if req.SessionKey != "" {
+			if _, err := parseSessionKey(req.SessionKey); err != nil {
+				http.Error(w, "invalid session_key", http.StatusBadRequest)
+				return
+			}
+		}
+		claims.ChannelBinding = channelBinding(req.SessionKey)
 		token, err := auth.IssueChallengeTokenWithKey(tokenKey, kid, claims)
 		if err != nil {
 			http.Error(w, "failed to issue token", http.StatusInternalServerError)
@@ -155,6 +271,8 @@ func main() {
 
 		resp := challengeResponse{
 			ChallengeToken: token,
+			Challenge:      claims.Challenge,
+			ChannelBinding: claims.ChannelBinding,
 			Salt:           req.Salt,
 			VKID:           claims.VKID,
 			ParamsVersion:  claims.ParamsVersion,
@@ -174,9 +292,24 @@ func main() {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
+		if err := verifySessionKey(req.SessionKey, req.SessionSig, req.ChallengeToken); err != nil {
+			writeJSON(w, verifyResponse{OK: false, ErrCode: sdkerrors.ErrChannelMismatch.Code, ErrStage: common.StageToken, ErrMsg: err.Error()})
+			return
+		}
+		if len(req.Envelope) > 0 {
+			env, err := envelope.DecodeJSON(req.Envelope)
+			if err != nil {
+				writeJSON(w, verifyResponse{OK: false, ErrCode: sdkerrors.CodeOf(err), ErrStage: common.StageParse, ErrMsg: err.Error()})
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), verifyTimeout)
+			defer cancel()
+			writeJSON(w, resultResponse(verifier.VerifyEnvelopeWithTokenResult(ctx, env, req.ChallengeToken, channelBinding(req.SessionKey))))
+			return
+		}
 		proofBytes, err := hex.DecodeString(req.Proof)
 		if err != nil {
-			writeJSON(w, verifyResponse{OK: false, ErrCode: "E1001", ErrMsg: "invalid proof format"})
+			writeJSON(w, verifyResponse{OK: false, ErrCode: "E1001", ErrStage: common.StageParse, ErrMsg: "invalid proof format"})
 			return
 		}
 
@@ -186,15 +319,55 @@ func main() {
 			if e, ok := err.(*sdkerrors.Error); ok {
 				code = e.Code
 			}
-			writeJSON(w, verifyResponse{OK: false, ErrCode: code, ErrMsg: err.Error()})
+			writeJSON(w, verifyResponse{OK: false, ErrCode: code, ErrStage: common.StagePolicy, ErrMsg: err.Error()})
 			return
 		}
 
-		ok, err := verifier.VerifyLoginWithToken(proofBytes, req.Commitment, req.Salt, req.ChallengeToken)
+		ctx, cancel := context.WithTimeout(r.Context(), verifyTimeout)
+		defer cancel()
+		writeJSON(w, resultResponse(verifier.VerifyLoginWithTokenResult(ctx, proofBytes, req.Commitment, req.Salt, req.ChallengeToken, channelBinding(req.SessionKey))))
+	})
+
+	http.HandleFunc("/change-secret", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req changeSecretRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if err := verifySessionKey(req.SessionKey, req.SessionSig, req.ChallengeToken); err != nil {
+			writeJSON(w, verifyResponse{OK: false, ErrCode: sdkerrors.ErrChannelMismatch.Code, ErrStage: common.StageToken, ErrMsg: err.Error()})
+			return
+		}
+		proofBytes, err := hex.DecodeString(req.Proof)
 		if err != nil {
-			writeJSON(w, verifyResponse{OK: false, ErrCode: "E1003", ErrMsg: err.Error()})
+			writeJSON(w, verifyResponse{OK: false, ErrCode: "E1001", ErrMsg: "invalid proof format"})
 			return
 		}
+
+		// A real server loads the user's stored commitment and salt here and
+		// rejects the request unless they equal old_commitment / old_salt.
+		ctx, cancel := context.WithTimeout(r.Context(), verifyTimeout)
+		defer cancel()
+		ok, err := verifier.VerifySecretChangeContext(ctx, auth.SecretChange{
+			Proof:         proofBytes,
+			OldCommitment: req.OldCommitment,
+			OldSalt:       req.OldSalt,
+			NewCommitment: req.NewCommitment,
+			NewSalt:       req.NewSalt,
+		}, req.ChallengeToken, channelBinding(req.SessionKey))
+		if err != nil {
+			code := sdkerrors.CodeOf(err)
+			if code == "" {
+				code = "E1003"
+			}
+			writeJSON(w, verifyResponse{OK: false, ErrCode: code, ErrMsg: err.Error()})
+			return
+		}
+		// ...then stores new_commitment / new_salt in place of the old values.
 		writeJSON(w, verifyResponse{OK: ok})
 	})
 
@@ -203,6 +376,53 @@ func main() {
 	log.Fatal(http.ListenAndServe(addr, nil))
 }
 
+// resultResponse reports a verification result with its error code and stage.
+func resultResponse(res common.VerificationResult) verifyResponse {
+	if res.Valid {
+		return verifyResponse{OK: true}
+	}
+	return verifyResponse{OK: false, ErrCode: res.Code, ErrStage: res.Stage, ErrMsg: res.Err.Error()}
+}
+
+// channelBinding derives the login channel binding from the client's session
+// public key. The key alone is public and a relay can forward it, so /verify
+// also requires a signature with it (see verifySessionKey), and the session
+// established afterwards must be tied to the same key: a proxy that does not
+// hold the private key cannot use a relayed login. Deployments terminating
+// TLS themselves can hash a TLS exporter value instead.
+func channelBinding(sessionKey string) string {
+	if sessionKey == "" {
+		return ""
+	}
+	return commitment.ChannelBindingFromBytes([]byte(sessionKey))
+}
+
+// verifySessionKey checks that the client holds the private half of its
+// session key: sessionSig must be its Ed25519 signature over the challenge
+// token. Requests without a session key are not channel-bound.
+func verifySessionKey(sessionKey string, sessionSig string, challengeToken string) error {
+	if sessionKey == "" {
+		return nil
+	}
+	pub, err := parseSessionKey(sessionKey)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(sessionSig)
+	if err != nil || !ed25519.Verify(pub, []byte(challengeToken), sig) {
+		return errors.New("session key signature invalid")
+	}
+	return nil
+}
+
+func parseSessionKey(sessionKey string) (ed25519.PublicKey, error) {
+	raw, err := hex.DecodeString(sessionKey)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, errors.New("session key must be a hex Ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
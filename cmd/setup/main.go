@@ -1,113 +1,255 @@
 package main
 
 import (
-	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ghdehrl12345/identify_sdk/v2/age"
+	"github.com/ghdehrl12345/identify_sdk/v2/attribute"
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+	"github.com/ghdehrl12345/identify_sdk/v2/membership"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
-	"golang.org/x/crypto/blake2b"
 )
 
+// defaultSRSSize covers every PLONK circuit with headroom (2^15 constraints).
+const defaultSRSSize = 1 << 15
+
+type target struct {
+	circuit string
+	backend string
+	newFn   func() frontend.Circuit
+	pk, vk  string
+}
+
+var targets = []target{
+	{auth.CircuitAgeLogin, backend.Groth16, func() frontend.Circuit { return &auth.UserCircuit{} }, "auth/user.pk", "auth/user.vk"},
+	{auth.CircuitLogin, backend.Groth16, func() frontend.Circuit { return &auth.LoginCircuit{} }, "auth/login.pk", "auth/login.vk"},
+	{"age", backend.Groth16, func() frontend.Circuit { return &age.AgeCircuit{} }, "age/age.pk", "age/age.vk"},
+	{"age-range", backend.Groth16, func() frontend.Circuit { return &age.AgeRangeCircuit{} }, "age/age_range.pk", "age/age_range.vk"},
+	{"age-credential", backend.Groth16, func() frontend.Circuit { return &age.CredentialAgeCircuit{} }, "age/age_credential.pk", "age/age_credential.vk"},
+	{attribute.CircuitName, backend.Groth16, func() frontend.Circuit { return &attribute.PredicateCircuit{} }, "attribute/attribute.pk", "attribute/attribute.vk"},
+	{auth.CircuitAgeLogin, backend.PLONK, func() frontend.Circuit { return &auth.UserCircuit{} }, "auth/user_plonk.pk", "auth/user_plonk.vk"},
+	{auth.CircuitLogin, backend.PLONK, func() frontend.Circuit { return &auth.LoginCircuit{} }, "auth/login_plonk.pk", "auth/login_plonk.vk"},
+	{"age", backend.PLONK, func() frontend.Circuit { return &age.AgeCircuit{} }, "age/age_plonk.pk", "age/age_plonk.vk"},
+	{auth.CircuitAgeLogin + "-poseidon2", backend.Groth16, func() frontend.Circuit { return &auth.UserCircuit{Hash: hasher.Poseidon2} }, "auth/user_poseidon2.pk", "auth/user_poseidon2.vk"},
+	{auth.CircuitLogin + "-poseidon2", backend.Groth16, func() frontend.Circuit { return &auth.LoginCircuit{Hash: hasher.Poseidon2} }, "auth/login_poseidon2.pk", "auth/login_poseidon2.vk"},
+	{auth.CircuitAgeLogin + "-poseidon2", backend.PLONK, func() frontend.Circuit { return &auth.UserCircuit{Hash: hasher.Poseidon2} }, "auth/user_poseidon2_plonk.pk", "auth/user_poseidon2_plonk.vk"},
+	{auth.CircuitLogin + "-poseidon2", backend.PLONK, func() frontend.Circuit { return &auth.LoginCircuit{Hash: hasher.Poseidon2} }, "auth/login_poseidon2_plonk.pk", "auth/login_poseidon2_plonk.vk"},
+	{auth.CircuitChangeSecret, backend.Groth16, func() frontend.Circuit { return &auth.ChangeSecretCircuit{} }, "auth/change_secret.pk", "auth/change_secret.vk"},
+	{auth.CircuitChangeSecret + "-poseidon2", backend.Groth16, func() frontend.Circuit { return &auth.ChangeSecretCircuit{Hash: hasher.Poseidon2} }, "auth/change_secret_poseidon2.pk", "auth/change_secret_poseidon2.vk"},
+	{membership.CircuitName, backend.Groth16, func() frontend.Circuit { return &membership.MembershipCircuit{} }, "membership/membership.pk", "membership/membership.vk"},
+	{membership.CircuitName + "-poseidon2", backend.Groth16, func() frontend.Circuit { return &membership.MembershipCircuit{Hash: hasher.Poseidon2} }, "membership/membership_poseidon2.pk", "membership/membership_poseidon2.vk"},
+	{membership.NullifierCircuitName, backend.Groth16, func() frontend.Circuit { return &membership.NullifierCircuit{} }, "membership/nullifier.pk", "membership/nullifier.vk"},
+	{membership.NullifierCircuitName + "-poseidon2", backend.Groth16, func() frontend.Circuit { return &membership.NullifierCircuit{Hash: hasher.Poseidon2} }, "membership/nullifier_poseidon2.pk", "membership/nullifier_poseidon2.vk"},
+}
+
 func main() {
-	fmt.Println("🔨 [Setup] ZKP 회로 컴파일 및 키 생성을 시작합니다...")
+	backendFlag := flag.String("backend", "all", "생성할 백엔드: groth16, plonk, all")
+	circuitsFlag := flag.String("circuits", "", "생성할 회로 (쉼표 구분, 예: age-login,login,age,age-range,age-credential,attribute,age-login-poseidon2,login-poseidon2,change-secret,membership,nullifier; 비우면 전체)")
+	srsPath := flag.String("srs", "keys/kzg_bn254.srs", "PLONK용 KZG SRS 경로 (없으면 생성)")
+	manifestPath := flag.String("manifest", "keys/manifest.json", "키 매니페스트 경로")
+	raw := flag.Bool("raw", false, "키를 비압축(raw) 형식으로 저장 (파일 크기 약 2배, 로딩 속도 향상)")
+	ccsOnly := flag.Bool("ccs-only", false, "키는 그대로 두고 직렬화된 제약 시스템(.ccs)만 다시 생성")
+	production := flag.Bool("production", false, "운영용 PLONK 키 생성: 공개 세레모니 SRS만 허용 (로컬 생성 및 배포된 테스트 SRS 거부)")
+	flag.Parse()
 
-	// 1. 회로 인스턴스 생성
-	var myCircuit auth.UserCircuit
-	var ageCircuit age.AgeCircuit
+	fmt.Println("🔨 [Setup] ZKP 회로 컴파일 및 키 생성을 시작합니다...")
 
-	// 2. 회로 컴파일 (R1CS 제약 시스템으로 변환)
-	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &myCircuit)
-	if err != nil {
-		panic("회로 컴파일 실패: " + err.Error())
+	selected := selectTargets(*backendFlag, *circuitsFlag)
+	if len(selected) == 0 {
+		panic("선택된 회로가 없습니다")
 	}
-	fmt.Printf(">> 회로 컴파일 완료 (제약 조건 수: %d)\n", ccs.GetNbConstraints())
 
-	ageCCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &ageCircuit)
-	if err != nil {
-		panic("Age 회로 컴파일 실패: " + err.Error())
+	var srs *backend.SRS
+	for _, t := range selected {
+		if t.backend == backend.PLONK && !*ccsOnly {
+			srs = loadOrCreateSRS(*srsPath, *production)
+			break
+		}
 	}
-	fmt.Printf(">> Age 회로 컴파일 완료 (제약 조건 수: %d)\n", ageCCS.GetNbConstraints())
 
-	// 3. Setup (증명키 pk, 검증키 vk 생성)
-	pk, vk, err := groth16.Setup(ccs)
-	if err != nil {
-		panic("Setup 실패: " + err.Error())
-	}
-	agePK, ageVK, err := groth16.Setup(ageCCS)
+	manifest, err := backend.LoadManifest(*manifestPath)
 	if err != nil {
-		panic("Age Setup 실패: " + err.Error())
+		manifest = &backend.KeyManifest{}
 	}
-	fmt.Println(">> 암호화 키 생성 완료")
 
-	writeKeyFile := func(path string, writeFn func(io.Writer) (int64, error)) error {
-		dir := filepath.Dir(path)
-		if dir != "." {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return err
+	for _, t := range selected {
+		label := t.circuit + "/" + t.backend
+		ccs, err := backend.Compile(t.backend, t.newFn())
+		if err != nil {
+			panic(fmt.Sprintf("%s 회로 컴파일 실패: %v", label, err))
+		}
+		fmt.Printf(">> %s 회로 컴파일 완료 (제약 조건 수: %d)\n", label, ccs.GetNbConstraints())
+		if err := writeKeyFile(ccsPath(t), ccs.WriteTo); err != nil {
+			panic(fmt.Sprintf("%s 제약 시스템 저장 실패: %v", label, err))
+		}
+
+		if *ccsOnly {
+			entry, ok := manifest.Find(t.circuit, t.backend)
+			if !ok {
+				entry = targetEntry(t)
 			}
+			entry.ConstraintSystem = ccsPath(t)
+			entry.CCSID = fileID(ccsPath(t))
+			manifest.Put(entry)
+			continue
 		}
 
-		f, err := os.Create(path)
+		pk, vk, err := backend.Setup(t.backend, ccs, srs)
 		if err != nil {
-			return err
+			panic(fmt.Sprintf("%s Setup 실패: %v", label, err))
+		}
+		writePK, writeVK := pk.WriteTo, vk.WriteTo
+		if *raw {
+			writePK, writeVK = pk.WriteRawTo, vk.WriteRawTo
+		}
+		if err := writeKeyFile(t.pk, writePK); err != nil {
+			panic(fmt.Sprintf("%s 증명키 저장 실패: %v", label, err))
+		}
+		if err := writeKeyFile(t.vk, writeVK); err != nil {
+			panic(fmt.Sprintf("%s 검증키 저장 실패: %v", label, err))
 		}
-		defer f.Close()
 
-		_, err = writeFn(f)
-		return err
+		entry := targetEntry(t)
+		if t.backend == backend.PLONK {
+			entry.SRSID = srs.ID()
+		}
+		manifest.Put(entry)
+		fmt.Printf("   Proving Key ID: %s\n   Verifying Key ID: %s\n", entry.PKID, entry.VKID)
 	}
+	fmt.Println(">> 암호화 키 생성 완료")
 
-	// User circuit keys - auth 모듈에 저장
-	pkTargets := []string{"auth/user.pk"}
-	for _, path := range pkTargets {
-		if err := writeKeyFile(path, pk.WriteTo); err != nil {
-			panic(fmt.Sprintf("증명키 저장 실패 (%s): %v", path, err))
+	// 이번에 생성하지 않은 기존 키도 매니페스트에 기록
+	for _, t := range targets {
+		if _, ok := manifest.Find(t.circuit, t.backend); ok {
+			continue
+		}
+		if _, err := os.Stat(t.vk); err != nil {
+			continue
 		}
+		manifest.Put(targetEntry(t))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*manifestPath), 0755); err != nil {
+		panic(fmt.Sprintf("매니페스트 경로 생성 실패: %v", err))
 	}
+	if err := manifest.Save(*manifestPath); err != nil {
+		panic(fmt.Sprintf("매니페스트 저장 실패: %v", err))
+	}
+
+	fmt.Printf("✅ [성공] 키 파일과 매니페스트(%s)가 업데이트되었습니다.\n", *manifestPath)
+}
 
-	vkTargets := []string{"auth/user.vk"}
-	for _, path := range vkTargets {
-		if err := writeKeyFile(path, vk.WriteTo); err != nil {
-			panic(fmt.Sprintf("검증키 저장 실패 (%s): %v", path, err))
+// targetEntry returns the manifest entry for the key files of t as written.
+func targetEntry(t target) backend.KeyEntry {
+	entry := backend.KeyEntry{
+		Circuit:      t.circuit,
+		Backend:      t.backend,
+		Curve:        "bn254",
+		ProvingKey:   t.pk,
+		VerifyingKey: t.vk,
+		PKID:         fileID(t.pk),
+		VKID:         fileID(t.vk),
+	}
+	if _, err := os.Stat(ccsPath(t)); err == nil {
+		entry.ConstraintSystem = ccsPath(t)
+		entry.CCSID = fileID(ccsPath(t))
+	}
+	return entry
+}
+
+// ccsPath returns the path of the serialized constraint system shipped next to
+// the proving key of t.
+func ccsPath(t target) string {
+	return strings.TrimSuffix(t.pk, ".pk") + ".ccs"
+}
+
+func selectTargets(backendName string, circuits string) []target {
+	want := map[string]bool{}
+	for _, c := range strings.Split(circuits, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			want[c] = true
+		}
+	}
+	if backendName != "all" {
+		if _, err := backend.Normalize(backendName); err != nil {
+			panic(err.Error())
 		}
 	}
+	var out []target
+	for _, t := range targets {
+		if backendName != "all" && t.backend != backendName {
+			continue
+		}
+		if len(want) > 0 && !want[t.circuit] {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
 
-	// Age circuit keys - age 모듈에 저장
-	agePKTargets := []string{"age/age.pk"}
-	for _, path := range agePKTargets {
-		if err := writeKeyFile(path, agePK.WriteTo); err != nil {
-			panic(fmt.Sprintf("Age 증명키 저장 실패 (%s): %v", path, err))
+// loadOrCreateSRS는 path의 SRS를 읽거나, 없으면 테스트 전용 SRS를 생성해 저장합니다.
+// production이면 테스트 전용 SRS(로컬 생성 또는 backend.TestSRSID)를 거부합니다.
+func loadOrCreateSRS(path string, production bool) *backend.SRS {
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		srs, err := backend.ReadSRS(f)
+		if err != nil {
+			panic(fmt.Sprintf("SRS 로드 실패: %v", err))
+		}
+		if production && srs.TestOnly() {
+			panic(fmt.Sprintf("%s 는 테스트 전용 SRS입니다 (공개 세레모니 SRS를 -srs 로 지정하세요)", path))
 		}
+		fmt.Printf(">> 기존 SRS 사용: %s (%d points)\n", path, srs.Size())
+		return srs
 	}
-	ageVKTargets := []string{"age/age.vk"}
-	for _, path := range ageVKTargets {
-		if err := writeKeyFile(path, ageVK.WriteTo); err != nil {
-			panic(fmt.Sprintf("Age 검증키 저장 실패 (%s): %v", path, err))
+	if production {
+		panic(fmt.Sprintf("SRS 파일 없음: %s (-production 에서는 공개 세레모니 SRS가 필요합니다)", path))
+	}
+
+	fmt.Println(">> 테스트 전용 KZG SRS 생성 중 (로컬 난수, 운영 환경에서는 공개 세레모니 SRS와 -production 사용)...")
+	srs, err := backend.NewSRS(defaultSRSSize)
+	if err != nil {
+		panic(fmt.Sprintf("SRS 생성 실패: %v", err))
+	}
+	if err := writeKeyFile(path, srs.WriteTo); err != nil {
+		panic(fmt.Sprintf("SRS 저장 실패: %v", err))
+	}
+	fmt.Printf(">> SRS 저장 완료: %s (%d points)\n", path, srs.Size())
+	return srs
+}
+
+func writeKeyFile(path string, writeFn func(io.Writer) (int64, error)) error {
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
 		}
 	}
 
-	fmt.Println("✅ [성공] auth/, age/ 경로에 키 파일이 업데이트되었습니다.")
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	// Fingerprints for versioning
-	pkBytes, _ := os.ReadFile("auth/user.pk")
-	vkBytes, _ := os.ReadFile("auth/user.vk")
-	agePkBytes, _ := os.ReadFile("age/age.pk")
-	ageVkBytes, _ := os.ReadFile("age/age.vk")
-	pkID := blake2b.Sum256(pkBytes)
-	vkID := blake2b.Sum256(vkBytes)
-	agePkID := blake2b.Sum256(agePkBytes)
-	ageVkID := blake2b.Sum256(ageVkBytes)
-	fmt.Printf("Proving Key ID: %s\n", hex.EncodeToString(pkID[:]))
-	fmt.Printf("Verifying Key ID: %s\n", hex.EncodeToString(vkID[:]))
-	fmt.Printf("Age Proving Key ID: %s\n", hex.EncodeToString(agePkID[:]))
-	fmt.Printf("Age Verifying Key ID: %s\n", hex.EncodeToString(ageVkID[:]))
+	_, err = writeFn(f)
+	return err
+}
+
+// fileID returns the blake2b-256 fingerprint the SDK reports for a key file.
+func fileID(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("키 파일 읽기 실패 (%s): %v", path, err))
+	}
+	return backend.KeyID(data)
 }
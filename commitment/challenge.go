@@ -0,0 +1,86 @@
+package commitment
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// ParseChallenge parses a challenge given as a decimal string or a 0x-prefixed hex string.
+// The value must be a non-zero BN254 scalar field element.
+func ParseChallenge(challenge string) (*big.Int, error) {
+	var v big.Int
+	var ok bool
+	if rest, found := strings.CutPrefix(strings.ToLower(challenge), "0x"); found {
+		_, ok = v.SetString(rest, 16)
+	} else {
+		_, ok = v.SetString(challenge, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("challenge parse failed: %q", challenge)
+	}
+	if v.Sign() <= 0 || v.Cmp(fr.Modulus()) >= 0 {
+		return nil, fmt.Errorf("challenge out of range: %q", challenge)
+	}
+	return &v, nil
+}
+
+// ParseChannelBinding parses a channel binding given as a decimal or 0x-prefixed
+// hex field element. An empty string means no channel binding and yields 0.
+func ParseChannelBinding(channel string) (*big.Int, error) {
+	if channel == "" {
+		return new(big.Int), nil
+	}
+	v, err := ParseChallenge(channel)
+	if err != nil {
+		return nil, fmt.Errorf("channel binding invalid: %w", err)
+	}
+	return v, nil
+}
+
+// ChannelBindingFromBytes hashes channel material, such as a client session
+// public key or a TLS exporter value, into a channel binding field element.
+func ChannelBindingFromBytes(material []byte) string {
+	h := sha256.Sum256(material)
+	var v big.Int
+	v.SetBytes(h[:])
+	v.Mod(&v, fr.Modulus())
+	if v.Sign() == 0 {
+		v.SetInt64(1)
+	}
+	return v.String()
+}
+
+// NewChallenge returns a uniformly random non-zero field element as a decimal string.
+func NewChallenge() (string, error) {
+	for {
+		v, err := rand.Int(rand.Reader, fr.Modulus())
+		if err != nil {
+			return "", fmt.Errorf("challenge rand failed: %w", err)
+		}
+		if v.Sign() != 0 {
+			return v.String(), nil
+		}
+	}
+}
+
+// DeriveChallenge hashes parts (e.g. a token's JTI and nonce) into the field
+// and returns the result as a decimal string.
+func DeriveChallenge(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	var v big.Int
+	v.SetBytes(h.Sum(nil))
+	v.Mod(&v, fr.Modulus())
+	if v.Sign() == 0 {
+		v.SetInt64(1)
+	}
+	return v.String()
+}
@@ -0,0 +1,45 @@
+package commitment
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// NewHolderSecret returns a random holder secret as a decimal field element.
+// The holder keeps it private; issuers sign the HolderCommitment of it into
+// credentials, so a credential can only be presented by its holder.
+func NewHolderSecret() (string, error) {
+	secret, err := NewChallenge()
+	if err != nil {
+		return "", fmt.Errorf("holder secret rand failed: %w", err)
+	}
+	return secret, nil
+}
+
+// HolderCommitment returns the MiMC hash of a holder secret, the value an
+// issuer binds into a credential. Credential circuits recompute it in-circuit.
+func HolderCommitment(holderSecret string) (string, error) {
+	v, err := parseCommitment(holderSecret)
+	if err != nil {
+		return "", fmt.Errorf("holder secret invalid: %w", err)
+	}
+	var e fr.Element
+	e.SetBigInt(v)
+	return hashElements(SchemeV2, e)
+}
+
+// ParseHolderCommitment parses a decimal holder commitment, which must be a
+// canonical field element.
+func ParseHolderCommitment(holder string) (fr.Element, error) {
+	var e fr.Element
+	v, err := parseCommitment(holder)
+	if err != nil {
+		return e, fmt.Errorf("holder commitment invalid: %w", err)
+	}
+	if v.String() != holder {
+		return e, fmt.Errorf("holder commitment not canonical: %q", holder)
+	}
+	e.SetBigInt(v)
+	return e, nil
+}
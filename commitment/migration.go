@@ -27,11 +27,31 @@ func DefaultMigrationConfig() MigrationConfig {
 	}
 }
 
+// V1Config returns the shared config that recomputes v1 commitments. Policy
+// fields do not affect commitments; only the Argon2 parameters are set apart
+// from the defaults.
+func (c MigrationConfig) V1Config() common.SharedConfig {
+	return argonConfig(c.V1Iterations, c.V1Memory)
+}
+
+// V2Config returns the shared config that creates migrated (v2) commitments.
+func (c MigrationConfig) V2Config() common.SharedConfig {
+	return argonConfig(c.V2Iterations, c.V2Memory)
+}
+
+func argonConfig(iterations uint32, memory uint32) common.SharedConfig {
+	cfg := common.DefaultSharedConfig()
+	cfg.ArgonIterations = iterations
+	cfg.ArgonMemory = memory
+	return cfg
+}
+
 // MigrationResult contains the result of a commitment migration.
 type MigrationResult struct {
 	OldCommitment string // v1 commitment (for reference)
 	NewCommitment string // v2 commitment
 	Salt          string // Salt (unchanged)
+	Record        Record // new commitment with its scheme and Argon2 parameters
 	Success       bool
 	Error         error
 }
@@ -41,13 +61,7 @@ type MigrationResult struct {
 // Returns both old (for verification) and new commitments.
 func MigrateCommitment(secret string, saltHex string, migCfg MigrationConfig) MigrationResult {
 	// Generate old commitment with v1 params (for verification)
-	v1Config := common.SharedConfig{
-		TargetYear:      2025, // doesn't affect commitment
-		LimitAge:        20,
-		ArgonIterations: migCfg.V1Iterations,
-		ArgonMemory:     migCfg.V1Memory,
-	}
-	oldCommitment, _, _, err := ComputeCommitment(secret, saltHex, v1Config)
+	oldCommitment, _, _, err := ComputeLegacyCommitment(secret, saltHex, migCfg.V1Config())
 	if err != nil {
 		return MigrationResult{
 			Success: false,
@@ -56,13 +70,7 @@ func MigrateCommitment(secret string, saltHex string, migCfg MigrationConfig) Mi
 	}
 
 	// Generate new commitment with v2 params
-	v2Config := common.SharedConfig{
-		TargetYear:      2025,
-		LimitAge:        20,
-		ArgonIterations: migCfg.V2Iterations,
-		ArgonMemory:     migCfg.V2Memory,
-	}
-	newCommitment, _, _, err := ComputeCommitment(secret, saltHex, v2Config)
+	record, err := NewRecord(secret, saltHex, NoBirthDate, SchemeV1, migCfg.V2Config())
 	if err != nil {
 		return MigrationResult{
 			OldCommitment: oldCommitment,
@@ -73,8 +81,9 @@ func MigrateCommitment(secret string, saltHex string, migCfg MigrationConfig) Mi
 
 	return MigrationResult{
 		OldCommitment: oldCommitment,
-		NewCommitment: newCommitment,
+		NewCommitment: record.Commitment,
 		Salt:          saltHex,
+		Record:        record,
 		Success:       true,
 	}
 }
@@ -99,6 +108,124 @@ func VerifyAndMigrate(secret string, saltHex string, expectedOldCommitment strin
 	return result
 }
 
+// MigrateToBirthBound converts a SchemeV1 record into a SchemeV2 commitment that binds birthDate.
+// The legacy commitment is recomputed with the v1 Argon2 parameters of migCfg and, when
+// expectedOldCommitment is set, must match it before the new commitment is created with
+// the v2 parameters. The salt is kept.
+func MigrateToBirthBound(secret string, saltHex string, birthDate int, expectedOldCommitment string, migCfg MigrationConfig) MigrationResult {
+	oldCommitment, _, _, err := ComputeLegacyCommitment(secret, saltHex, migCfg.V1Config())
+	if err != nil {
+		return MigrationResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to compute legacy commitment: %w", err),
+		}
+	}
+	if expectedOldCommitment != "" && oldCommitment != expectedOldCommitment {
+		return MigrationResult{
+			OldCommitment: oldCommitment,
+			Success:       false,
+			Error:         fmt.Errorf("old commitment mismatch: secret or salt may be incorrect"),
+		}
+	}
+
+	record, err := NewRecord(secret, saltHex, birthDate, SchemeV2, migCfg.V2Config())
+	if err != nil {
+		return MigrationResult{
+			OldCommitment: oldCommitment,
+			Success:       false,
+			Error:         fmt.Errorf("failed to compute birth-bound commitment: %w", err),
+		}
+	}
+
+	return MigrationResult{
+		OldCommitment: oldCommitment,
+		NewCommitment: record.Commitment,
+		Salt:          saltHex,
+		Record:        record,
+		Success:       true,
+	}
+}
+
+// MigrateToPoseidon2 converts a SchemeV2 (MiMC) commitment into its SchemeV3 (Poseidon2)
+// counterpart. The MiMC commitment is recomputed and, when expectedOldCommitment is set,
+// must match it first. Salt and Argon2 parameters are kept.
+func MigrateToPoseidon2(secret string, saltHex string, birthDate int, expectedOldCommitment string, cfg common.SharedConfig) MigrationResult {
+	oldCommitment, _, _, err := ComputeCommitmentWithScheme(secret, saltHex, birthDate, SchemeV2, cfg)
+	if err != nil {
+		return MigrationResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to compute mimc commitment: %w", err),
+		}
+	}
+	if expectedOldCommitment != "" && oldCommitment != expectedOldCommitment {
+		return MigrationResult{
+			OldCommitment: oldCommitment,
+			Success:       false,
+			Error:         fmt.Errorf("old commitment mismatch: secret or salt may be incorrect"),
+		}
+	}
+
+	record, err := NewRecord(secret, saltHex, birthDate, SchemeV3, cfg)
+	if err != nil {
+		return MigrationResult{
+			OldCommitment: oldCommitment,
+			Success:       false,
+			Error:         fmt.Errorf("failed to compute poseidon2 commitment: %w", err),
+		}
+	}
+
+	return MigrationResult{
+		OldCommitment: oldCommitment,
+		NewCommitment: record.Commitment,
+		Salt:          saltHex,
+		Record:        record,
+		Success:       true,
+	}
+}
+
+// MigrateRecord verifies secret (and birthDate) against a stored record using the
+// record's own scheme and Argon2 parameters, then re-creates it for scheme with
+// cfg's parameters. The salt is kept. SchemeV1 records need a birthDate to move
+// to a birth-bound scheme.
+func MigrateRecord(secret string, birthDate int, old Record, scheme int, cfg common.SharedConfig) MigrationResult {
+	oldBirthDate := birthDate
+	if old.Scheme == SchemeV1 {
+		oldBirthDate = NoBirthDate
+	}
+	ok, err := old.Verify(secret, oldBirthDate)
+	if err != nil {
+		return MigrationResult{
+			OldCommitment: old.Commitment,
+			Success:       false,
+			Error:         fmt.Errorf("failed to recompute stored record: %w", err),
+		}
+	}
+	if !ok {
+		return MigrationResult{
+			OldCommitment: old.Commitment,
+			Success:       false,
+			Error:         fmt.Errorf("old commitment mismatch: secret or birth date may be incorrect"),
+		}
+	}
+
+	record, err := NewRecord(secret, old.Salt, birthDate, scheme, cfg)
+	if err != nil {
+		return MigrationResult{
+			OldCommitment: old.Commitment,
+			Success:       false,
+			Error:         fmt.Errorf("failed to compute new record: %w", err),
+		}
+	}
+
+	return MigrationResult{
+		OldCommitment: old.Commitment,
+		NewCommitment: record.Commitment,
+		Salt:          record.Salt,
+		Record:        record,
+		Success:       true,
+	}
+}
+
 // BatchMigration migrates multiple commitments at once.
 type BatchMigration struct {
 	config MigrationConfig
@@ -115,6 +242,8 @@ type MigrationEntry struct {
 	Secret        string
 	Salt          string
 	OldCommitment string // For verification
+	BirthDate     int    // YYYYMMDD, used by MigrateBirthBound and MigrateRecords
+	Record        string // stored record, used by MigrateRecords
 }
 
 // BatchMigrationResult contains results for all entries.
@@ -129,6 +258,7 @@ type BatchMigrationSuccess struct {
 	OldCommitment string
 	NewCommitment string
 	Salt          string
+	Record        string // new record; empty if the migration produced none
 }
 
 // BatchMigrationFailure represents a failed migration.
@@ -145,29 +275,77 @@ func (b *BatchMigration) Migrate(entries []MigrationEntry) BatchMigrationResult
 	}
 
 	for _, entry := range entries {
-		var migResult MigrationResult
-		if entry.OldCommitment != "" {
-			// Verify and migrate
-			migResult = VerifyAndMigrate(entry.Secret, entry.Salt, entry.OldCommitment, b.config)
-		} else {
-			// Just migrate without verification
-			migResult = MigrateCommitment(entry.Secret, entry.Salt, b.config)
-		}
+		result.add(entry, b.migrateEntry(entry))
+	}
+
+	return result
+}
+
+// MigrateBirthBound converts SchemeV1 records to SchemeV2, verifying each with the
+// v1 Argon2 parameters and re-creating it with the target (v2) parameters.
+func (b *BatchMigration) MigrateBirthBound(entries []MigrationEntry) BatchMigrationResult {
+	result := BatchMigrationResult{
+		Successful: make([]BatchMigrationSuccess, 0),
+		Failed:     make([]BatchMigrationFailure, 0),
+	}
+
+	for _, entry := range entries {
+		result.add(entry, MigrateToBirthBound(entry.Secret, entry.Salt, entry.BirthDate, entry.OldCommitment, b.config))
+	}
+
+	return result
+}
+
+// MigrateRecords re-creates stored records for CurrentScheme with the target (v2)
+// Argon2 parameters, verifying each against the record's own parameters.
+func (b *BatchMigration) MigrateRecords(entries []MigrationEntry) BatchMigrationResult {
+	result := BatchMigrationResult{
+		Successful: make([]BatchMigrationSuccess, 0),
+		Failed:     make([]BatchMigrationFailure, 0),
+	}
 
-		if migResult.Success {
-			result.Successful = append(result.Successful, BatchMigrationSuccess{
-				UserID:        entry.UserID,
-				OldCommitment: migResult.OldCommitment,
-				NewCommitment: migResult.NewCommitment,
-				Salt:          migResult.Salt,
-			})
-		} else {
-			result.Failed = append(result.Failed, BatchMigrationFailure{
-				UserID: entry.UserID,
-				Error:  migResult.Error.Error(),
-			})
+	cfg := b.config.V2Config()
+	for _, entry := range entries {
+		old, err := ParseRecord(entry.Record)
+		if err != nil {
+			result.add(entry, MigrationResult{Error: err})
+			continue
 		}
+		result.add(entry, MigrateRecord(entry.Secret, entry.BirthDate, old, CurrentScheme, cfg))
 	}
 
 	return result
 }
+
+func (b *BatchMigration) migrateEntry(entry MigrationEntry) MigrationResult {
+	if entry.OldCommitment != "" {
+		// Verify and migrate
+		return VerifyAndMigrate(entry.Secret, entry.Salt, entry.OldCommitment, b.config)
+	}
+	// Just migrate without verification
+	return MigrateCommitment(entry.Secret, entry.Salt, b.config)
+}
+
+func (r *BatchMigrationResult) add(entry MigrationEntry, migResult MigrationResult) {
+	if migResult.Success {
+		r.Successful = append(r.Successful, BatchMigrationSuccess{
+			UserID:        entry.UserID,
+			OldCommitment: migResult.OldCommitment,
+			NewCommitment: migResult.NewCommitment,
+			Salt:          migResult.Salt,
+			Record:        recordString(migResult.Record),
+		})
+		return
+	}
+	r.Failed = append(r.Failed, BatchMigrationFailure{
+		UserID: entry.UserID,
+		Error:  migResult.Error.Error(),
+	})
+}
+
+func recordString(r Record) string {
+	if r.Commitment == "" {
+		return ""
+	}
+	return r.String()
+}
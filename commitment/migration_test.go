@@ -2,6 +2,8 @@ package commitment
 
 import (
 	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
 )
 
 func TestMigrateCommitment(t *testing.T) {
@@ -105,3 +107,74 @@ func TestBatchMigration(t *testing.T) {
 		t.Logf("User %s: old=%s... new=%s...", s.UserID, s.OldCommitment[:10], s.NewCommitment[:10])
 	}
 }
+
+func TestMigrateToBirthBound(t *testing.T) {
+	secret := "test-secret-123"
+	salt := "0123456789abcdef0123456789abcdef"
+	migCfg := DefaultMigrationConfig()
+
+	// The legacy commitment was created with the v1 Argon2 parameters.
+	legacy, _, _, err := ComputeLegacyCommitment(secret, salt, migCfg.V1Config())
+	if err != nil {
+		t.Fatalf("legacy commitment failed: %v", err)
+	}
+
+	result := MigrateToBirthBound(secret, salt, 19900315, legacy, migCfg)
+	if !result.Success {
+		t.Fatalf("migration failed: %v", result.Error)
+	}
+	expected, _, _, err := ComputeCommitment(secret, salt, 19900315, migCfg.V2Config())
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+	if result.NewCommitment != expected {
+		t.Error("new commitment should be the scheme v2 commitment")
+	}
+
+	if r := MigrateToBirthBound("wrong-secret", salt, 19900315, legacy, migCfg); r.Success {
+		t.Error("should fail with wrong secret")
+	}
+	if r := MigrateToBirthBound(secret, salt, 19900230, legacy, migCfg); r.Success {
+		t.Error("should fail with invalid birth date")
+	}
+
+	batch := NewBatchMigration(migCfg).MigrateBirthBound([]MigrationEntry{
+		{UserID: "user1", Secret: secret, Salt: salt, OldCommitment: legacy, BirthDate: 19900315},
+	})
+	if len(batch.Successful) != 1 || batch.Successful[0].NewCommitment != expected {
+		t.Fatalf("batch migration of a v1 commitment failed: %+v", batch)
+	}
+}
+
+func TestMigrateToPoseidon2(t *testing.T) {
+	secret := "test-secret-123"
+	salt := "0123456789abcdef0123456789abcdef"
+	cfg := common.DefaultSharedConfig()
+
+	mimcCommit, _, _, err := ComputeCommitment(secret, salt, 19900315, cfg)
+	if err != nil {
+		t.Fatalf("commitment failed: %v", err)
+	}
+
+	result := MigrateToPoseidon2(secret, salt, 19900315, mimcCommit, cfg)
+	if !result.Success {
+		t.Fatalf("migration failed: %v", result.Error)
+	}
+	expected, _, _, err := ComputeCommitmentWithScheme(secret, salt, 19900315, SchemeV3, cfg)
+	if err != nil {
+		t.Fatalf("poseidon2 commitment failed: %v", err)
+	}
+	if result.NewCommitment != expected {
+		t.Error("new commitment should be the scheme v3 commitment")
+	}
+	if result.NewCommitment == mimcCommit {
+		t.Error("poseidon2 and mimc commitments should differ")
+	}
+
+	if r := MigrateToPoseidon2("wrong-secret", salt, 19900315, mimcCommit, cfg); r.Success {
+		t.Error("should fail with wrong secret")
+	}
+	if _, _, _, err := ComputeCommitmentWithScheme(secret, salt, 19900315, SchemeV1, cfg); err == nil {
+		t.Error("scheme v1 has no birth-bound commitment")
+	}
+}
@@ -2,74 +2,194 @@ package commitment
 
 import (
 	"encoding/hex"
+	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
 	"golang.org/x/crypto/argon2"
 )
 
-// ComputeCommitment derives a MiMC commitment from secret and salt.
-func ComputeCommitment(secret string, saltHex string, cfg common.SharedConfig) (commitment string, saltInt big.Int, derived fr.Element, err error) {
+// Commitment scheme versions.
+const (
+	// SchemeV1 commits to H(derived, salt) and carries no birth date.
+	SchemeV1 = 1
+	// SchemeV2 commits to H(derived, salt, birthDate) so age claims are fixed at registration.
+	SchemeV2 = 2
+	// SchemeV3 is SchemeV2 with Poseidon2 in place of MiMC for the commitment and binding.
+	SchemeV3 = 3
+	// CurrentScheme is the scheme produced by ComputeCommitment.
+	CurrentScheme = SchemeV2
+)
+
+// NormalizeScheme validates a birth-date-bound scheme (SchemeV2 or SchemeV3); 0 selects CurrentScheme.
+func NormalizeScheme(scheme int) (int, error) {
+	switch scheme {
+	case 0:
+		return CurrentScheme, nil
+	case SchemeV2, SchemeV3:
+		return scheme, nil
+	default:
+		return 0, fmt.Errorf("unsupported commitment scheme v%d", scheme)
+	}
+}
+
+// HashForScheme returns the hasher a scheme commits and binds with.
+func HashForScheme(scheme int) (string, error) {
+	switch scheme {
+	case SchemeV1, SchemeV2:
+		return hasher.MiMC, nil
+	case SchemeV3:
+		return hasher.Poseidon2, nil
+	default:
+		return "", fmt.Errorf("unknown commitment scheme v%d", scheme)
+	}
+}
+
+// NoBirthDate registers a SchemeV2 commitment without a birth date.
+// Such commitments can only prove the login-only circuit.
+const NoBirthDate = 0
+
+// ComputeCommitment derives a MiMC commitment from secret, salt and birth date (YYYYMMDD or NoBirthDate).
+func ComputeCommitment(secret string, saltHex string, birthDate int, cfg common.SharedConfig) (commitment string, saltInt big.Int, derived fr.Element, err error) {
+	return ComputeCommitmentWithScheme(secret, saltHex, birthDate, SchemeV2, cfg)
+}
+
+// ComputeCommitmentWithScheme is ComputeCommitment for SchemeV2 (MiMC) or SchemeV3 (Poseidon2).
+func ComputeCommitmentWithScheme(secret string, saltHex string, birthDate int, scheme int, cfg common.SharedConfig) (commitment string, saltInt big.Int, derived fr.Element, err error) {
+	if scheme, err = NormalizeScheme(scheme); err != nil {
+		return "", saltInt, derived, err
+	}
+	return ComputeRecordCommitment(secret, birthDate, RecordFor(scheme, saltHex, cfg))
+}
+
+// ComputeLegacyCommitment derives a SchemeV1 commitment H(derived, salt).
+// It is only needed to verify records created before birth-date binding.
+func ComputeLegacyCommitment(secret string, saltHex string, cfg common.SharedConfig) (commitment string, saltInt big.Int, derived fr.Element, err error) {
+	return ComputeRecordCommitment(secret, NoBirthDate, RecordFor(SchemeV1, saltHex, cfg))
+}
+
+// RecordFor returns the record (without commitment) for cfg's Argon2 parameters.
+func RecordFor(scheme int, saltHex string, cfg common.SharedConfig) Record {
+	return Record{
+		Scheme:     scheme,
+		Memory:     cfg.ArgonMemory,
+		Iterations: cfg.ArgonIterations,
+		Threads:    common.ArgonThreads,
+		Salt:       strings.ToLower(saltHex),
+	}
+}
+
+func deriveSecret(secret string, saltHex string, iterations uint32, memory uint32, threads uint8) (saltInt big.Int, derived fr.Element, err error) {
 	saltBytes, err := hex.DecodeString(saltHex)
 	if err != nil {
-		return "", saltInt, derived, err
+		return saltInt, derived, err
 	}
 	saltInt.SetBytes(saltBytes)
 
-	derivedBytes := argon2.IDKey([]byte(secret), saltBytes, cfg.ArgonIterations, cfg.ArgonMemory, common.ArgonThreads, common.ArgonKeyLen)
+	derivedBytes := argon2.IDKey([]byte(secret), saltBytes, iterations, memory, threads, common.ArgonKeyLen)
 
 	var derivedInt big.Int
 	derivedInt.SetBytes(derivedBytes)
 	derived.SetBigInt(&derivedInt)
+	return saltInt, derived, nil
+}
 
-	var saltElem, derivedElem fr.Element
-	saltElem.SetBigInt(&saltInt)
-	derivedElem.SetBigInt(&derivedInt)
+func saltElement(saltInt *big.Int) fr.Element {
+	var e fr.Element
+	e.SetBigInt(saltInt)
+	return e
+}
 
-	commitHasher := mimc.NewMiMC()
-	deBytes := derivedElem.Bytes()
-	sBytes := saltElem.Bytes()
-	commitHasher.Write(deBytes[:])
-	commitHasher.Write(sBytes[:])
-	commitBytes := commitHasher.Sum(nil)
+func hashElements(scheme int, elems ...fr.Element) (string, error) {
+	name, err := HashForScheme(scheme)
+	if err != nil {
+		return "", err
+	}
+	digest, err := hasher.Sum(name, elems...)
+	if err != nil {
+		return "", err
+	}
+	var out big.Int
+	return digest.BigInt(&out).String(), nil
+}
 
-	var commitInt big.Int
-	commitInt.SetBytes(commitBytes)
-	commitment = commitInt.String()
+// ComputeBinding creates a challenge-bound hash from commitment and challenge
+// without a channel binding.
+// challenge is a decimal or 0x-prefixed hex field element (see ParseChallenge);
+// small integer challenges from ct-v1 tokens are valid field elements too.
+func ComputeBinding(commitment string, challenge string) (string, error) {
+	return ComputeChannelBinding(commitment, challenge, "")
+}
 
-	return commitment, saltInt, derived, nil
+// ComputeChannelBinding creates H(commitment, challenge, channel), tying a proof to
+// the channel that requested it. An empty channel means no channel binding (0).
+func ComputeChannelBinding(commitment string, challenge string, channel string) (string, error) {
+	return ComputeChannelBindingWithScheme(commitment, challenge, channel, SchemeV2)
 }
 
-// ComputeBinding creates a challenge-bound hash from commitment and challenge.
-func ComputeBinding(commitment string, challenge int) (string, error) {
-	var commitInt big.Int
-	if _, ok := commitInt.SetString(commitment, 10); !ok {
-		return "", nil
+// ComputeChannelBindingWithScheme is ComputeChannelBinding with the hash of the
+// commitment's scheme.
+func ComputeChannelBindingWithScheme(commitment string, challenge string, channel string, scheme int) (string, error) {
+	commitInt, err := parseCommitment(commitment)
+	if err != nil {
+		return "", err
 	}
-	chInt := big.NewInt(int64(challenge))
+	chInt, err := ParseChallenge(challenge)
+	if err != nil {
+		return "", err
+	}
+	cbInt, err := ParseChannelBinding(channel)
+	if err != nil {
+		return "", err
+	}
+
+	var elems [3]fr.Element
+	elems[0].SetBigInt(commitInt)
+	elems[1].SetBigInt(chInt)
+	elems[2].SetBigInt(cbInt)
+	return hashElements(scheme, elems[:]...)
+}
 
-	bindHasher := mimc.NewMiMC()
-	bindHasher.Write(intBytes(&commitInt))
-	bindHasher.Write(intBytes(chInt))
-	bindBytes := bindHasher.Sum(nil)
+// ComputeChangeBinding creates H(oldCommitment, challenge, newCommitment), the
+// binding of a change-secret proof that rotates oldCommitment to newCommitment.
+func ComputeChangeBinding(oldCommitment string, challenge string, newCommitment string, scheme int) (string, error) {
+	oldInt, err := parseCommitment(oldCommitment)
+	if err != nil {
+		return "", err
+	}
+	newInt, err := parseCommitment(newCommitment)
+	if err != nil {
+		return "", err
+	}
+	chInt, err := ParseChallenge(challenge)
+	if err != nil {
+		return "", err
+	}
 
-	var bindInt big.Int
-	bindInt.SetBytes(bindBytes)
-	return bindInt.String(), nil
+	var elems [3]fr.Element
+	elems[0].SetBigInt(oldInt)
+	elems[1].SetBigInt(chInt)
+	elems[2].SetBigInt(newInt)
+	return hashElements(scheme, elems[:]...)
 }
 
-func intBytes(v *big.Int) []byte {
-	if v.Sign() == 0 {
-		return []byte{0}
+func parseCommitment(commitment string) (*big.Int, error) {
+	commitInt, ok := new(big.Int).SetString(commitment, 10)
+	if !ok {
+		return nil, fmt.Errorf("commitment parse failed: %s", commitment)
+	}
+	if commitInt.Sign() < 0 || commitInt.Cmp(fr.Modulus()) >= 0 {
+		return nil, fmt.Errorf("commitment out of range: %s", commitment)
 	}
-	return v.Bytes()
+	return commitInt, nil
 }
 
 // ComputeCommitmentAndBinding returns both commitment and binding in one call.
-func ComputeCommitmentAndBinding(secret string, saltHex string, challenge int, cfg common.SharedConfig) (commitment string, binding string, derived fr.Element, saltInt big.Int, err error) {
-	commitment, saltInt, derived, err = ComputeCommitment(secret, saltHex, cfg)
+func ComputeCommitmentAndBinding(secret string, saltHex string, birthDate int, challenge string, cfg common.SharedConfig) (commitment string, binding string, derived fr.Element, saltInt big.Int, err error) {
+	commitment, saltInt, derived, err = ComputeCommitment(secret, saltHex, birthDate, cfg)
 	if err != nil {
 		return "", "", derived, saltInt, err
 	}
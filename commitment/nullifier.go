@@ -0,0 +1,58 @@
+package commitment
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
+
+// scopeDomain separates scope elements from challenges derived from the same strings.
+const scopeDomain = "idz-scope"
+
+// ScopeElement maps a scope name (e.g. "poll:2026-10") into the field and
+// returns it as a decimal string.
+func ScopeElement(scope string) string {
+	return DeriveChallenge(scopeDomain, scope)
+}
+
+// ComputeNullifier derives the nullifier H(derived, scope) of the secret behind
+// a commitment made with saltHex, using the hash of scheme. It is stable for a
+// user and scope and reveals nothing about the commitment.
+func ComputeNullifier(secret string, saltHex string, scope string, scheme int, cfg common.SharedConfig) (string, error) {
+	scheme, err := NormalizeScheme(scheme)
+	if err != nil {
+		return "", err
+	}
+	r := RecordFor(scheme, saltHex, cfg)
+	_, derived, err := deriveSecret(secret, r.Salt, r.Iterations, r.Memory, r.Threads)
+	if err != nil {
+		return "", err
+	}
+	return NullifierFromDerived(derived, scope, scheme)
+}
+
+// ParseNullifier parses a decimal nullifier, which must be a canonical field element.
+func ParseNullifier(nullifier string) (*big.Int, error) {
+	v, err := parseCommitment(nullifier)
+	if err != nil {
+		return nil, fmt.Errorf("nullifier invalid: %w", err)
+	}
+	// Nullifier stores compare strings, so "0"+n or "+"+n must not pass as n.
+	if v.String() != nullifier {
+		return nil, fmt.Errorf("nullifier not canonical: %q", nullifier)
+	}
+	return v, nil
+}
+
+// NullifierFromDerived is ComputeNullifier for an already derived secret.
+func NullifierFromDerived(derived fr.Element, scope string, scheme int) (string, error) {
+	scopeInt, err := parseCommitment(ScopeElement(scope))
+	if err != nil {
+		return "", err
+	}
+	var scopeElem fr.Element
+	scopeElem.SetBigInt(scopeInt)
+	return hashElements(scheme, derived, scopeElem)
+}
@@ -0,0 +1,198 @@
+package commitment
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
+
+// recordPrefix prefixes the hash name in the record identifier ("idz-mimc").
+const recordPrefix = "idz-"
+
+// Record is a stored commitment together with everything needed to recompute
+// it: scheme, Argon2 parameters and salt. Its string form follows the PHC
+// format, with a hex salt and a decimal commitment:
+//
+//	$idz-mimc$v=2$m=65536,t=3,p=4$<salt>$<commitment>
+type Record struct {
+	Scheme     int    // SchemeV1, SchemeV2 or SchemeV3
+	Memory     uint32 // Argon2 memory in KiB
+	Iterations uint32 // Argon2 iterations
+	Threads    uint8  // Argon2 parallelism
+	Salt       string // hex
+	Commitment string // decimal field element
+}
+
+// NewRecord computes a commitment for scheme with cfg's Argon2 parameters and
+// returns it as a Record. SchemeV1 records take NoBirthDate.
+func NewRecord(secret string, saltHex string, birthDate int, scheme int, cfg common.SharedConfig) (Record, error) {
+	if scheme == 0 {
+		scheme = CurrentScheme
+	}
+	r := RecordFor(scheme, saltHex, cfg)
+	commit, _, _, err := ComputeRecordCommitment(secret, birthDate, r)
+	if err != nil {
+		return Record{}, err
+	}
+	r.Commitment = commit
+	return r, nil
+}
+
+// ParseRecord parses the string form of a Record. The hash name must match the
+// scheme version and the commitment must be a canonical field element.
+func ParseRecord(s string) (Record, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return Record{}, fmt.Errorf("record format invalid: expected $id$v=$params$salt$commitment")
+	}
+	var r Record
+
+	version, ok := strings.CutPrefix(parts[2], "v=")
+	if !ok {
+		return Record{}, fmt.Errorf("record version missing")
+	}
+	scheme, err := strconv.Atoi(version)
+	if err != nil {
+		return Record{}, fmt.Errorf("record version invalid: %q", version)
+	}
+	hashName, err := HashForScheme(scheme)
+	if err != nil {
+		return Record{}, err
+	}
+	if parts[1] != recordPrefix+hashName {
+		return Record{}, fmt.Errorf("record id %q does not match scheme v%d", parts[1], scheme)
+	}
+	r.Scheme = scheme
+
+	params := strings.Split(parts[3], ",")
+	if len(params) != 3 {
+		return Record{}, fmt.Errorf("record params invalid: %q", parts[3])
+	}
+	for i, key := range []string{"m", "t", "p"} {
+		value, ok := strings.CutPrefix(params[i], key+"=")
+		if !ok {
+			return Record{}, fmt.Errorf("record param %s missing", key)
+		}
+		bits := 32
+		if key == "p" {
+			bits = 8
+		}
+		n, err := strconv.ParseUint(value, 10, bits)
+		if err != nil || n == 0 {
+			return Record{}, fmt.Errorf("record param %s invalid: %q", key, value)
+		}
+		switch key {
+		case "m":
+			r.Memory = uint32(n)
+		case "t":
+			r.Iterations = uint32(n)
+		case "p":
+			r.Threads = uint8(n)
+		}
+	}
+
+	if _, err := hex.DecodeString(parts[4]); err != nil || parts[4] == "" || parts[4] != strings.ToLower(parts[4]) {
+		return Record{}, fmt.Errorf("record salt must be lowercase hex")
+	}
+	r.Salt = parts[4]
+
+	var commit big.Int
+	if _, ok := commit.SetString(parts[5], 10); !ok || commit.String() != parts[5] || commit.Sign() < 0 || commit.Cmp(fr.Modulus()) >= 0 {
+		return Record{}, fmt.Errorf("record commitment invalid: %q", parts[5])
+	}
+	r.Commitment = parts[5]
+	return r, nil
+}
+
+// String encodes the record in its PHC-style form.
+func (r Record) String() string {
+	hashName, err := HashForScheme(r.Scheme)
+	if err != nil {
+		hashName = "unknown"
+	}
+	return fmt.Sprintf("$%s%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		recordPrefix, hashName, r.Scheme, r.Memory, r.Iterations, r.Threads, r.Salt, r.Commitment)
+}
+
+// MarshalText implements encoding.TextMarshaler so records store as strings.
+func (r Record) MarshalText() ([]byte, error) {
+	if _, err := HashForScheme(r.Scheme); err != nil {
+		return nil, err
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler via ParseRecord.
+func (r *Record) UnmarshalText(text []byte) error {
+	parsed, err := ParseRecord(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Config returns base with the record's Argon2 parameters, for provers that
+// take a common.SharedConfig. Threads are not part of SharedConfig; use
+// ComputeRecordCommitment when they differ from common.ArgonThreads.
+func (r Record) Config(base common.SharedConfig) common.SharedConfig {
+	base.ArgonMemory = r.Memory
+	base.ArgonIterations = r.Iterations
+	return base
+}
+
+// Matches reports whether the record's Argon2 parameters equal cfg's.
+// Records that do not match should be re-created (see MigrateRecord).
+func (r Record) Matches(cfg common.SharedConfig) bool {
+	return r.Memory == cfg.ArgonMemory && r.Iterations == cfg.ArgonIterations && r.Threads == common.ArgonThreads
+}
+
+// Verify recomputes the commitment from secret and birthDate with the record's
+// parameters and compares it in constant time.
+func (r Record) Verify(secret string, birthDate int) (bool, error) {
+	commit, _, _, err := ComputeRecordCommitment(secret, birthDate, r)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(commit), []byte(r.Commitment)) == 1, nil
+}
+
+// ComputeRecordCommitment derives the commitment for secret and birthDate using
+// the scheme, Argon2 parameters (including threads) and salt of r. r.Commitment
+// is ignored.
+func ComputeRecordCommitment(secret string, birthDate int, r Record) (commitment string, saltInt big.Int, derived fr.Element, err error) {
+	if r.Iterations == 0 || r.Threads == 0 {
+		return "", saltInt, derived, fmt.Errorf("record argon2 params invalid: t=%d,p=%d", r.Iterations, r.Threads)
+	}
+	if r.Scheme != SchemeV1 {
+		if _, err := HashForScheme(r.Scheme); err != nil {
+			return "", saltInt, derived, err
+		}
+		if birthDate != NoBirthDate {
+			if err := common.ValidateDate(birthDate); err != nil {
+				return "", saltInt, derived, err
+			}
+		}
+	} else if birthDate != NoBirthDate {
+		return "", saltInt, derived, fmt.Errorf("scheme v1 records carry no birth date")
+	}
+	saltInt, derived, err = deriveSecret(secret, r.Salt, r.Iterations, r.Memory, r.Threads)
+	if err != nil {
+		return "", saltInt, derived, err
+	}
+
+	if r.Scheme == SchemeV1 {
+		commitment, err = hashElements(SchemeV1, derived, saltElement(&saltInt))
+		return commitment, saltInt, derived, err
+	}
+	var birthElem fr.Element
+	birthElem.SetUint64(uint64(birthDate))
+	commitment, err = hashElements(r.Scheme, derived, saltElement(&saltInt), birthElem)
+	return commitment, saltInt, derived, err
+}
@@ -0,0 +1,129 @@
+package commitment
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	salt := "0123456789abcdef0123456789abcdef"
+
+	for _, scheme := range []int{SchemeV2, SchemeV3} {
+		r, err := NewRecord("test-secret", salt, 19900315, scheme, cfg)
+		if err != nil {
+			t.Fatalf("v%d: record failed: %v", scheme, err)
+		}
+		want, _, _, err := ComputeCommitmentWithScheme("test-secret", salt, 19900315, scheme, cfg)
+		if err != nil || r.Commitment != want {
+			t.Fatalf("v%d: record commitment differs from ComputeCommitmentWithScheme: %v", scheme, err)
+		}
+
+		s := r.String()
+		hash, _ := HashForScheme(scheme)
+		if prefix := "$idz-" + hash + "$v="; !strings.HasPrefix(s, prefix) || !strings.Contains(s, "$m=65536,t=3,p=4$"+salt+"$") {
+			t.Fatalf("v%d: unexpected encoding %s", scheme, s)
+		}
+		parsed, err := ParseRecord(s)
+		if err != nil || parsed != r {
+			t.Fatalf("v%d: parse round trip failed: %+v %v", scheme, parsed, err)
+		}
+		if ok, err := parsed.Verify("test-secret", 19900315); err != nil || !ok {
+			t.Fatalf("v%d: verify failed: %v", scheme, err)
+		}
+		if ok, _ := parsed.Verify("wrong-secret", 19900315); ok {
+			t.Fatalf("v%d: wrong secret verified", scheme)
+		}
+	}
+}
+
+func TestRecordCarriesArgonParams(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	cfg.ArgonIterations = 1
+	salt := "0123456789abcdef0123456789abcdef"
+
+	r, err := NewRecord("test-secret", salt, 19900315, SchemeV2, cfg)
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	parsed, err := ParseRecord(r.String())
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if parsed.Iterations != 1 || parsed.Matches(common.DefaultSharedConfig()) {
+		t.Fatalf("record should carry t=1: %+v", parsed)
+	}
+	// The record verifies with its own parameters, whatever the current defaults are.
+	if ok, err := parsed.Verify("test-secret", 19900315); err != nil || !ok {
+		t.Fatalf("verify failed: %v", err)
+	}
+
+	var stored struct {
+		Record Record `json:"record"`
+	}
+	stored.Record = r
+	data, err := json.Marshal(stored)
+	if err != nil || !strings.Contains(string(data), `"$idz-mimc$v=2$m=65536,t=1,p=4$`) {
+		t.Fatalf("json encoding failed: %s %v", data, err)
+	}
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Record != r {
+		t.Fatalf("json decoding failed: %v", err)
+	}
+}
+
+func TestParseRecordRejects(t *testing.T) {
+	commit := "123456789"
+	for _, s := range []string{
+		"",
+		commit,
+		"$idz-mimc$v=2$m=65536,t=3,p=4$abcd",
+		"$idz-poseidon2$v=2$m=65536,t=3,p=4$abcd$" + commit,             // hash does not match version
+		"$idz-mimc$v=4$m=65536,t=3,p=4$abcd$" + commit,                  // unknown version
+		"$idz-mimc$v=2$t=3,m=65536,p=4$abcd$" + commit,                  // params out of order
+		"$idz-mimc$v=2$m=65536,t=0,p=4$abcd$" + commit,                  // zero iterations
+		"$idz-mimc$v=2$m=65536,t=3,p=256$abcd$" + commit,                // threads overflow
+		"$idz-mimc$v=2$m=65536,t=3,p=4$ABCD$" + commit,                  // uppercase salt
+		"$idz-mimc$v=2$m=65536,t=3,p=4$xyz$" + commit,                   // non-hex salt
+		"$idz-mimc$v=2$m=65536,t=3,p=4$abcd$0123",                       // non-canonical commitment
+		"$idz-mimc$v=2$m=65536,t=3,p=4$abcd$-1",                         // negative commitment
+		"$idz-mimc$v=2$m=65536,t=3,p=4$abcd$" + strings.Repeat("9", 80), // out of field
+	} {
+		if _, err := ParseRecord(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestMigrateRecord(t *testing.T) {
+	salt := "0123456789abcdef0123456789abcdef"
+	v1Config := common.DefaultSharedConfig()
+	v1Config.ArgonIterations = 1
+	cfg := common.DefaultSharedConfig()
+
+	old, err := NewRecord("test-secret", salt, NoBirthDate, SchemeV1, v1Config)
+	if err != nil {
+		t.Fatalf("v1 record failed: %v", err)
+	}
+	result := MigrateRecord("test-secret", 19900315, old, CurrentScheme, cfg)
+	if !result.Success {
+		t.Fatalf("migration failed: %v", result.Error)
+	}
+	want, err := NewRecord("test-secret", salt, 19900315, SchemeV2, cfg)
+	if err != nil || result.Record != want || result.NewCommitment != want.Commitment {
+		t.Fatalf("unexpected migrated record %s: %v", result.Record, err)
+	}
+	if r := MigrateRecord("wrong-secret", 19900315, old, CurrentScheme, cfg); r.Success {
+		t.Error("should fail with wrong secret")
+	}
+
+	batch := NewBatchMigration(DefaultMigrationConfig()).MigrateRecords([]MigrationEntry{
+		{UserID: "a", Secret: "test-secret", BirthDate: 19900315, Record: old.String()},
+		{UserID: "b", Secret: "test-secret", Record: "not-a-record"},
+	})
+	if len(batch.Successful) != 1 || batch.Successful[0].Record != want.String() || len(batch.Failed) != 1 {
+		t.Fatalf("unexpected batch result: %+v", batch)
+	}
+}
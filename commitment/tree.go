@@ -0,0 +1,296 @@
+package commitment
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// TreeDepth is the depth of the membership tree the embedded membership keys
+// are generated for (2^20 leaves).
+const TreeDepth = 20
+
+// DefaultRootHistory is the number of recent roots a Tree accepts, so proofs
+// made against a root stay valid while a few more leaves are appended, updated
+// or removed.
+const DefaultRootHistory = 30
+
+// Tree is a Merkle tree of registered commitments. Nodes are H(left, right)
+// with the hash of the tree's commitment scheme and empty leaves are 0.
+// Commitments are appended; Update replaces one and Remove revokes one by
+// zeroing its leaf. Every change pushes a new root, and proofs against older
+// roots keep verifying until they fall out of the root history, so a removed
+// member is locked out only after that many further changes. It is safe for
+// concurrent use.
+type Tree struct {
+	mu      sync.RWMutex
+	scheme  int
+	hash    string
+	depth   int
+	history int
+	zeros   []fr.Element   // zeros[l] is the root of an empty subtree of height l
+	levels  [][]fr.Element // levels[0] are the leaves
+	roots   []string       // recent roots, oldest first
+}
+
+// MerklePath is the authentication path of a leaf: the sibling at each level,
+// leaf level first, and the leaf index whose bits pick the side (bit l set
+// means the node at level l is a right child).
+type MerklePath struct {
+	Index    uint64   `json:"index"`
+	Siblings []string `json:"siblings"` // decimal field elements
+}
+
+// NewTree creates an empty tree. depth 0 selects TreeDepth, scheme 0 selects
+// CurrentScheme and history 0 selects DefaultRootHistory.
+func NewTree(depth int, scheme int, history int) (*Tree, error) {
+	if depth == 0 {
+		depth = TreeDepth
+	}
+	if depth < 1 || depth > 32 {
+		return nil, fmt.Errorf("tree depth %d out of range", depth)
+	}
+	scheme, err := NormalizeScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := HashForScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	if history == 0 {
+		history = DefaultRootHistory
+	}
+	if history < 1 {
+		return nil, fmt.Errorf("tree root history %d out of range", history)
+	}
+
+	t := &Tree{
+		scheme:  scheme,
+		hash:    hash,
+		depth:   depth,
+		history: history,
+		zeros:   make([]fr.Element, depth+1),
+		levels:  make([][]fr.Element, depth+1),
+	}
+	for l := 1; l <= depth; l++ {
+		if t.zeros[l], err = hasher.Sum(hash, t.zeros[l-1], t.zeros[l-1]); err != nil {
+			return nil, err
+		}
+	}
+	t.roots = []string{elementString(t.zeros[depth])}
+	return t, nil
+}
+
+// Append adds a commitment as the next leaf and returns its index.
+func (t *Tree) Append(commitment string) (uint64, error) {
+	leafInt, err := parseCommitment(commitment)
+	if err != nil {
+		return 0, err
+	}
+	var node fr.Element
+	node.SetBigInt(leafInt)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	index := uint64(len(t.levels[0]))
+	if index >= uint64(1)<<t.depth {
+		return 0, fmt.Errorf("tree is full (%d leaves)", index)
+	}
+	if err := t.setLeaf(index, node); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// Update replaces the commitment at index, e.g. after a secret change, and
+// pushes the new root. The old commitment stays provable against the roots
+// still in the history.
+func (t *Tree) Update(index uint64, commitment string) error {
+	leafInt, err := parseCommitment(commitment)
+	if err != nil {
+		return err
+	}
+	var node fr.Element
+	node.SetBigInt(leafInt)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if index >= uint64(len(t.levels[0])) {
+		return fmt.Errorf("leaf index %d out of range", index)
+	}
+	return t.setLeaf(index, node)
+}
+
+// Remove revokes the commitment at index by zeroing its leaf and pushes the
+// new root. The index is not reused; Leaves reports it as "0".
+func (t *Tree) Remove(index uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if index >= uint64(len(t.levels[0])) {
+		return fmt.Errorf("leaf index %d out of range", index)
+	}
+	return t.setLeaf(index, t.zeros[0])
+}
+
+// setLeaf stores node at index (at most one past the last leaf), rehashes its
+// path and records the new root. The caller holds t.mu.
+func (t *Tree) setLeaf(index uint64, node fr.Element) error {
+	var err error
+	pos := index
+	for l := 0; l < t.depth; l++ {
+		level := t.levels[l]
+		if pos == uint64(len(level)) {
+			level = append(level, node)
+		} else {
+			level[pos] = node
+		}
+		t.levels[l] = level
+
+		left, right := node, t.zeros[l]
+		if pos%2 == 1 {
+			left, right = level[pos-1], node
+		} else if pos+1 < uint64(len(level)) {
+			right = level[pos+1]
+		}
+		if node, err = hasher.Sum(t.hash, left, right); err != nil {
+			return err
+		}
+		pos /= 2
+	}
+	if len(t.levels[t.depth]) == 0 {
+		t.levels[t.depth] = []fr.Element{node}
+	} else {
+		t.levels[t.depth][0] = node
+	}
+
+	t.roots = append(t.roots, elementString(node))
+	if len(t.roots) > t.history {
+		t.roots = slices.Delete(t.roots, 0, len(t.roots)-t.history)
+	}
+	return nil
+}
+
+// Len returns the number of leaves.
+func (t *Tree) Len() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return uint64(len(t.levels[0]))
+}
+
+// Depth returns the tree depth.
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Scheme returns the commitment scheme whose hash builds the tree.
+func (t *Tree) Scheme() int {
+	return t.scheme
+}
+
+// Root returns the current root as a decimal field element.
+func (t *Tree) Root() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.roots[len(t.roots)-1]
+}
+
+// Roots returns the accepted root history, oldest first.
+func (t *Tree) Roots() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Clone(t.roots)
+}
+
+// KnownRoot reports whether root is in the root history.
+func (t *Tree) KnownRoot(root string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Contains(t.roots, root)
+}
+
+// Leaves returns the appended commitments in index order, for persisting the tree.
+func (t *Tree) Leaves() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]string, len(t.levels[0]))
+	for i := range t.levels[0] {
+		out[i] = elementString(t.levels[0][i])
+	}
+	return out
+}
+
+// Path returns the authentication path of the leaf at index against the current root.
+func (t *Tree) Path(index uint64) (MerklePath, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if index >= uint64(len(t.levels[0])) {
+		return MerklePath{}, fmt.Errorf("leaf index %d out of range", index)
+	}
+	path := MerklePath{Index: index, Siblings: make([]string, t.depth)}
+	pos := index
+	for l := 0; l < t.depth; l++ {
+		sibling := t.zeros[l]
+		if s := pos ^ 1; s < uint64(len(t.levels[l])) {
+			sibling = t.levels[l][s]
+		}
+		path.Siblings[l] = elementString(sibling)
+		pos /= 2
+	}
+	return path, nil
+}
+
+// ComputeRoot hashes leaf up the path with the hash of scheme and returns the root.
+func (p MerklePath) ComputeRoot(leaf string, scheme int) (string, error) {
+	hash, err := HashForScheme(scheme)
+	if err != nil {
+		return "", err
+	}
+	if len(p.Siblings) < 64 && p.Index>>len(p.Siblings) != 0 {
+		return "", fmt.Errorf("leaf index %d out of range for depth %d", p.Index, len(p.Siblings))
+	}
+	leafInt, err := parseCommitment(leaf)
+	if err != nil {
+		return "", err
+	}
+	var node fr.Element
+	node.SetBigInt(leafInt)
+	for l, s := range p.Siblings {
+		siblingInt, err := parseCommitment(s)
+		if err != nil {
+			return "", fmt.Errorf("path sibling %d: %w", l, err)
+		}
+		var sibling fr.Element
+		sibling.SetBigInt(siblingInt)
+		left, right := node, sibling
+		if p.Index>>l&1 == 1 {
+			left, right = sibling, node
+		}
+		if node, err = hasher.Sum(hash, left, right); err != nil {
+			return "", err
+		}
+	}
+	return elementString(node), nil
+}
+
+// ParseRoot parses a decimal tree root, which must be a canonical field element.
+func ParseRoot(root string) (*big.Int, error) {
+	v, err := parseCommitment(root)
+	if err != nil {
+		return nil, fmt.Errorf("root invalid: %w", err)
+	}
+	// Accepted roots are compared as strings, so "0"+r must not pass as r.
+	if v.String() != root {
+		return nil, fmt.Errorf("root not canonical: %q", root)
+	}
+	return v, nil
+}
+
+func elementString(e fr.Element) string {
+	var out big.Int
+	return e.BigInt(&out).String()
+}
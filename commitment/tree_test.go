@@ -0,0 +1,143 @@
+package commitment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTreePaths(t *testing.T) {
+	for _, scheme := range []int{SchemeV2, SchemeV3} {
+		tree, err := NewTree(4, scheme, 3)
+		if err != nil {
+			t.Fatalf("v%d: tree failed: %v", scheme, err)
+		}
+		empty := tree.Root()
+
+		var leaves []string
+		for i := 0; i < 5; i++ {
+			leaf := fmt.Sprint(1000 + i)
+			index, err := tree.Append(leaf)
+			if err != nil || index != uint64(i) {
+				t.Fatalf("v%d: append %d failed: %d %v", scheme, i, index, err)
+			}
+			leaves = append(leaves, leaf)
+		}
+		root := tree.Root()
+		for i, leaf := range leaves {
+			path, err := tree.Path(uint64(i))
+			if err != nil {
+				t.Fatalf("v%d: path %d failed: %v", scheme, i, err)
+			}
+			if got, err := path.ComputeRoot(leaf, scheme); err != nil || got != root {
+				t.Fatalf("v%d: path %d does not lead to the root: %v", scheme, i, err)
+			}
+			if got, _ := path.ComputeRoot("999", scheme); got == root {
+				t.Fatalf("v%d: path %d accepted a foreign leaf", scheme, i)
+			}
+		}
+
+		// Rebuilding from the leaves gives the same root.
+		rebuilt, _ := NewTree(4, scheme, 3)
+		for _, leaf := range tree.Leaves() {
+			if _, err := rebuilt.Append(leaf); err != nil {
+				t.Fatalf("v%d: rebuild failed: %v", scheme, err)
+			}
+		}
+		if rebuilt.Root() != root {
+			t.Fatalf("v%d: rebuilt root differs", scheme)
+		}
+
+		// Only the last three roots are accepted.
+		if roots := tree.Roots(); len(roots) != 3 || roots[2] != root {
+			t.Fatalf("v%d: unexpected root history %v", scheme, roots)
+		}
+		if !tree.KnownRoot(root) || tree.KnownRoot(empty) {
+			t.Fatalf("v%d: root history not enforced", scheme)
+		}
+	}
+}
+
+func TestTreeUpdateRemove(t *testing.T) {
+	for _, scheme := range []int{SchemeV2, SchemeV3} {
+		tree, _ := NewTree(4, scheme, 2)
+		for i := 0; i < 4; i++ {
+			tree.Append(fmt.Sprint(1000 + i))
+		}
+		before := tree.Root()
+
+		if err := tree.Remove(1); err != nil {
+			t.Fatalf("v%d: remove failed: %v", scheme, err)
+		}
+		if err := tree.Update(2, "2002"); err != nil {
+			t.Fatalf("v%d: update failed: %v", scheme, err)
+		}
+		root := tree.Root()
+		if tree.Len() != 4 || tree.Leaves()[1] != "0" {
+			t.Fatalf("v%d: unexpected leaves %v", scheme, tree.Leaves())
+		}
+
+		// The removed and replaced commitments no longer lead to the root;
+		// the others and the new commitment do.
+		for i, leaf := range map[uint64]string{0: "1000", 2: "2002", 3: "1003"} {
+			path, _ := tree.Path(i)
+			if got, _ := path.ComputeRoot(leaf, scheme); got != root {
+				t.Fatalf("v%d: path %d does not lead to the root", scheme, i)
+			}
+		}
+		for i, leaf := range map[uint64]string{1: "1001", 2: "1002"} {
+			path, _ := tree.Path(i)
+			if got, _ := path.ComputeRoot(leaf, scheme); got == root {
+				t.Fatalf("v%d: stale leaf %d still leads to the root", scheme, i)
+			}
+		}
+
+		// Rebuilding from the leaves gives the same root, and the root from
+		// before the removal has left the history.
+		rebuilt, _ := NewTree(4, scheme, 2)
+		for _, leaf := range tree.Leaves() {
+			rebuilt.Append(leaf)
+		}
+		if rebuilt.Root() != root {
+			t.Fatalf("v%d: rebuilt root differs", scheme)
+		}
+		if tree.KnownRoot(before) {
+			t.Fatalf("v%d: root before the removal still accepted", scheme)
+		}
+		if err := tree.Remove(4); err == nil {
+			t.Fatalf("v%d: expected out of range removal to fail", scheme)
+		}
+	}
+}
+
+func TestTreeRejects(t *testing.T) {
+	tree, err := NewTree(2, SchemeV2, 0)
+	if err != nil {
+		t.Fatalf("tree failed: %v", err)
+	}
+	if _, err := tree.Append("not-a-number"); err == nil {
+		t.Fatal("expected invalid leaf error")
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := tree.Append(fmt.Sprint(i + 1)); err != nil {
+			t.Fatalf("append %d failed: %v", i, err)
+		}
+	}
+	if _, err := tree.Append("5"); err == nil {
+		t.Fatal("expected full tree error")
+	}
+	if _, err := tree.Path(4); err == nil {
+		t.Fatal("expected index out of range error")
+	}
+	if _, err := NewTree(0, SchemeV1, 0); err == nil {
+		t.Fatal("expected scheme v1 to be rejected")
+	}
+	root := tree.Root()
+	if _, err := ParseRoot(root); err != nil {
+		t.Fatalf("parse root failed: %v", err)
+	}
+	for _, alias := range []string{"0" + root, "+" + root} {
+		if _, err := ParseRoot(alias); err == nil {
+			t.Fatalf("expected non-canonical root %q to be rejected", alias)
+		}
+	}
+}
@@ -5,16 +5,38 @@ import "time"
 // SharedConfig contains policy/KDF parameters that must match between client and server.
 type SharedConfig struct {
 	TargetYear      int
+	TargetDate      int // YYYYMMDD; 0 means the last day of TargetYear
 	LimitAge        int
+	AgeMode         string // AgeModeInternational (default) or AgeModeKoreanYear
 	ArgonMemory     uint32
 	ArgonIterations uint32
+	// DynamicDate makes the policy date the current UTC date at each proof and
+	// verification; TargetYear and TargetDate are then ignored.
+	DynamicDate bool
 }
 
-// DefaultSharedConfig returns a SharedConfig with dynamic current year.
+// CurrentDate returns the policy date (YYYYMMDD) used as the age reference:
+// today for DynamicDate configs, otherwise TargetDate. Configs without
+// TargetDate fall back to December 31 of TargetYear, which matches the
+// year-subtraction semantics of earlier releases.
+func (c SharedConfig) CurrentDate() int {
+	if c.DynamicDate {
+		return DateOf(time.Now().UTC())
+	}
+	if c.TargetDate != 0 {
+		return c.TargetDate
+	}
+	return c.TargetYear*10000 + 1231
+}
+
+// DefaultSharedConfig returns a SharedConfig with dynamic current date.
 func DefaultSharedConfig() SharedConfig {
+	now := time.Now().UTC()
 	return SharedConfig{
-		TargetYear:      time.Now().Year(),
+		TargetYear:      now.Year(),
+		DynamicDate:     true,
 		LimitAge:        20,
+		AgeMode:         AgeModeInternational,
 		ArgonMemory:     ArgonMemory,
 		ArgonIterations: ArgonIterations,
 	}
@@ -30,12 +52,27 @@ func DefaultSharedConfigWithYear(year int) SharedConfig {
 	}
 }
 
+// DefaultSharedConfigWithDate returns a SharedConfig with explicit date (YYYYMMDD) (for testing).
+func DefaultSharedConfigWithDate(date int) SharedConfig {
+	return SharedConfig{
+		TargetYear:      date / 10000,
+		TargetDate:      date,
+		LimitAge:        20,
+		AgeMode:         AgeModeInternational,
+		ArgonMemory:     ArgonMemory,
+		ArgonIterations: ArgonIterations,
+	}
+}
+
 // DefaultSharedConfigWithEnv returns a SharedConfig with environment-specific Argon2 parameters.
 func DefaultSharedConfigWithEnv(env string) SharedConfig {
 	argonCfg := GetArgonConfig(env)
+	now := time.Now().UTC()
 	return SharedConfig{
-		TargetYear:      time.Now().Year(),
+		TargetYear:      now.Year(),
+		DynamicDate:     true,
 		LimitAge:        20,
+		AgeMode:         AgeModeInternational,
 		ArgonMemory:     argonCfg.Memory,
 		ArgonIterations: argonCfg.Iterations,
 	}
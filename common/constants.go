@@ -6,7 +6,9 @@ const (
 	ArgonMemory     uint32 = 64 * 1024 // KiB
 	ArgonThreads    uint8  = 4
 	ArgonKeyLen     uint32 = 32
-	MimcSeed               = "identify-sdk-mimc-seed"
+	// Deprecated: MiMC uses gnark-crypto's fixed BN254 round constants; the hash
+	// is selected by the commitment scheme (see the hasher package).
+	MimcSeed = "identify-sdk-mimc-seed"
 )
 
 // Environment constants
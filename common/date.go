@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// Age counting modes.
+const (
+	// AgeModeInternational counts full years (만 나이): the age increases on each birthday.
+	// A person born on February 29 turns a year older on March 1 in common years.
+	AgeModeInternational = "international"
+	// AgeModeKoreanYear counts year-age (연 나이): current year minus birth year.
+	AgeModeKoreanYear = "korean-year"
+)
+
+// AgeModeCode maps an age mode to the circuit's public Mode input.
+// An empty mode selects AgeModeInternational.
+func AgeModeCode(mode string) (int, error) {
+	switch mode {
+	case "", AgeModeInternational:
+		return 0, nil
+	case AgeModeKoreanYear:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unknown age mode %q", mode)
+	}
+}
+
+// ValidateDate checks that a YYYYMMDD integer names a real calendar day.
+func ValidateDate(date int) error {
+	year, month, day := date/10000, (date/100)%100, date%100
+	if year < 1 || year > 9999 || month < 1 || month > 12 || day < 1 {
+		return fmt.Errorf("invalid date %d: expected YYYYMMDD", date)
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return fmt.Errorf("invalid date %d: no such calendar day", date)
+	}
+	return nil
+}
+
+// SplitDate splits a YYYYMMDD integer into its year and MMDD parts.
+func SplitDate(date int) (year int, monthDay int) {
+	return date / 10000, date % 10000
+}
+
+// DateOf returns t's calendar date as YYYYMMDD.
+func DateOf(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+// AgeAt returns the age on current for someone born on birth (both YYYYMMDD),
+// mirroring the in-circuit computation.
+func AgeAt(birth int, current int, mode string) int {
+	by, bmd := SplitDate(birth)
+	cy, cmd := SplitDate(current)
+	age := cy - by
+	if mode != AgeModeKoreanYear && cmd < bmd {
+		age--
+	}
+	return age
+}
@@ -8,8 +8,25 @@ import (
 )
 
 // ParamsVersion returns a stable fingerprint for shared policy/KDF parameters.
+// DynamicDate configs leave the date out, so their fingerprint does not change
+// as the policy date moves. An empty AgeMode counts as AgeModeInternational,
+// so a config and its normalized form share one fingerprint.
 func ParamsVersion(cfg SharedConfig) string {
+	mode := cfg.AgeMode
+	if mode == "" {
+		mode = AgeModeInternational
+	}
+	if cfg.DynamicDate {
+		payload := fmt.Sprintf("today-%d-%d-%d-%s", cfg.LimitAge, cfg.ArgonMemory, cfg.ArgonIterations, mode)
+		sum := blake2b.Sum256([]byte(payload))
+		return hex.EncodeToString(sum[:])
+	}
 	payload := fmt.Sprintf("%d-%d-%d-%d", cfg.TargetYear, cfg.LimitAge, cfg.ArgonMemory, cfg.ArgonIterations)
+	// Date and mode are appended only when they differ from a year-only
+	// international config, so those keep their original fingerprint.
+	if cfg.TargetDate != 0 || mode != AgeModeInternational {
+		payload += fmt.Sprintf("-%d-%s", cfg.TargetDate, mode)
+	}
 	sum := blake2b.Sum256([]byte(payload))
 	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"time"
+
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// Verification stages reported by VerificationResult.Stage.
+const (
+	StageParse   = "parse"   // proof, envelope or public input malformed
+	StagePolicy  = "policy"  // key, params version, issuer, root or scope not accepted
+	StageToken   = "token"   // challenge token invalid, expired or bound to another channel
+	StageReplay  = "replay"  // token JTI or nullifier already used
+	StagePairing = "pairing" // the proof itself did not verify (or verification was canceled)
+)
+
+// Claim names used in VerificationResult.Claims. Each verifier sets the ones
+// that apply to its proofs.
+const (
+	ClaimUserID        = "user_id"
+	ClaimJTI           = "jti"
+	ClaimExpiresAt     = "exp" // unix seconds
+	ClaimVKID          = "vk_id"
+	ClaimParamsVersion = "params_version"
+	ClaimProofVersion  = "proof_version"
+	ClaimIssuerKey     = "issuer_key"
+	ClaimRoot          = "root"
+	ClaimScope         = "scope"
+	ClaimNullifier     = "nullifier"
+)
+
+// VerificationResult is the outcome of a ...Result verify call. A failed
+// verification carries the stable error code of Err and the stage that
+// rejected the proof; a successful one carries the claims the verifier checked.
+type VerificationResult struct {
+	Valid      bool              `json:"valid"`
+	Code       string            `json:"code,omitempty"`  // error code (e.g. E1003) when Valid is false
+	Stage      string            `json:"stage,omitempty"` // Stage* when Valid is false
+	Claims     map[string]string `json:"claims,omitempty"`
+	VerifiedAt time.Time         `json:"verified_at"`
+	Duration   time.Duration     `json:"duration_ns"`
+	Err        error             `json:"-"`
+}
+
+// NewVerificationResult builds the result of a verification that started at
+// start. A nil err yields a valid result with claims; otherwise claims are
+// dropped and errors without a code are reported as E1003.
+func NewVerificationResult(start time.Time, claims map[string]string, err error) VerificationResult {
+	now := time.Now()
+	res := VerificationResult{
+		Valid:      err == nil,
+		VerifiedAt: now.UTC(),
+		Duration:   now.Sub(start),
+		Err:        err,
+	}
+	if err != nil {
+		res.Code = sdkerrors.CodeOf(err)
+		if res.Code == "" {
+			res.Code = sdkerrors.ErrVerificationFail.Code
+		}
+		res.Stage = StageOf(res.Code)
+		return res
+	}
+	res.Claims = claims
+	return res
+}
+
+// StageOf returns the verification stage an error code is reported at.
+// Codes outside the parse, token, replay and pairing groups (key, issuer,
+// root and configuration errors) are policy failures.
+func StageOf(code string) string {
+	switch code {
+	case "E1001", "E1002", "E1005", "E1006", "E1007", "E1010", "E1014":
+		return StageParse
+	case "E1011", "E1012", "E1016", "E4004":
+		return StageToken
+	case "E1013", "E1018":
+		return StageReplay
+	case "E1003", "E1019":
+		return StagePairing
+	}
+	return StagePolicy
+}
@@ -0,0 +1,309 @@
+// Package envelope defines the canonical wire format for proofs: a versioned
+// envelope carrying the proof bytes, named public inputs and the key and policy
+// metadata a verifier checks before verifying. Envelopes have a stable JSON
+// encoding and a compact, deterministic CBOR encoding; decoding is strict.
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// Version is the envelope format version.
+const Version = "idz-envelope-v1"
+
+// Proof encodings for the JSON form. The CBOR form carries raw bytes and keeps
+// the encoding only so a round trip preserves it.
+const (
+	EncodingHex    = "hex"    // lowercase hex
+	EncodingBase64 = "base64" // standard, padded base64
+)
+
+// Public input names used by the SDK's proofs.
+const (
+	InputCommitment = "commitment" // decimal field element
+	InputSalt       = "salt"       // hex
+	InputIssuerKey  = "issuer_key" // hex credential issuer key
+)
+
+// Limits applied when decoding untrusted envelopes.
+const (
+	MaxProofSize    = 64 << 10
+	MaxPublicInputs = 16
+)
+
+var (
+	keyIDPattern     = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	inputNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	cborEncMode, _   = cbor.CoreDetEncOptions().EncMode()
+	cborDecMode, _   = cbor.DecOptions{
+		DupMapKey:         cbor.DupMapKeyEnforcedAPF,
+		IndefLength:       cbor.IndefLengthForbidden,
+		TagsMd:            cbor.TagsForbidden,
+		ExtraReturnErrors: cbor.ExtraDecErrorUnknownField,
+	}.DecMode()
+)
+
+// Envelope is a proof with its public inputs and metadata. Public inputs are
+// the values a verifier needs besides its own policy (e.g. commitment and salt
+// for login proofs); inputs implied by the policy or a challenge token are not
+// included.
+type Envelope struct {
+	Version       string
+	ProofVersion  string
+	VKID          string
+	ParamsVersion string
+	Encoding      string // EncodingHex or EncodingBase64; empty means hex
+	Proof         []byte
+	PublicInputs  map[string]string
+}
+
+type jsonEnvelope struct {
+	Version       string            `json:"version"`
+	ProofVersion  string            `json:"proof_version"`
+	VKID          string            `json:"vk_id"`
+	ParamsVersion string            `json:"params_version"`
+	Encoding      string            `json:"encoding"`
+	Proof         string            `json:"proof"`
+	PublicInputs  map[string]string `json:"public_inputs,omitempty"`
+}
+
+type cborEnvelope struct {
+	Version       string            `cbor:"1,keyasint"`
+	ProofVersion  string            `cbor:"2,keyasint"`
+	VKID          string            `cbor:"3,keyasint"`
+	ParamsVersion string            `cbor:"4,keyasint"`
+	Encoding      string            `cbor:"5,keyasint"`
+	Proof         []byte            `cbor:"6,keyasint"`
+	PublicInputs  map[string]string `cbor:"7,keyasint,omitempty"`
+}
+
+// New returns a hex-encoded envelope of the current version.
+func New(proof []byte, proofVersion string, vkID string, paramsVersion string, inputs map[string]string) Envelope {
+	return Envelope{
+		Version:       Version,
+		ProofVersion:  proofVersion,
+		VKID:          vkID,
+		ParamsVersion: paramsVersion,
+		Encoding:      EncodingHex,
+		Proof:         proof,
+		PublicInputs:  inputs,
+	}
+}
+
+// Validate checks the version, metadata, proof and public input names.
+// Decode, DecodeJSON and DecodeCBOR call it; envelopes built in code should
+// be validated before they are sent.
+func (e Envelope) Validate() error {
+	switch {
+	case e.Version != Version:
+		return formatError("version unsupported", fmt.Errorf("%q", e.Version))
+	case e.encoding() != EncodingHex && e.encoding() != EncodingBase64:
+		return formatError("proof encoding unsupported", fmt.Errorf("%q", e.Encoding))
+	case e.ProofVersion == "":
+		return formatError("proof_version missing", nil)
+	case !keyIDPattern.MatchString(e.VKID):
+		return formatError("vk_id must be 64 lowercase hex characters", nil)
+	case e.ParamsVersion == "":
+		return formatError("params_version missing", nil)
+	case len(e.Proof) == 0:
+		return formatError("proof missing", nil)
+	case len(e.Proof) > MaxProofSize:
+		return formatError("proof too large", fmt.Errorf("%d bytes", len(e.Proof)))
+	case len(e.PublicInputs) > MaxPublicInputs:
+		return formatError("too many public inputs", fmt.Errorf("%d", len(e.PublicInputs)))
+	}
+	for name, value := range e.PublicInputs {
+		if !inputNamePattern.MatchString(name) {
+			return formatError("public input name invalid", fmt.Errorf("%q", name))
+		}
+		if value == "" {
+			return formatError("public input empty", fmt.Errorf("%q", name))
+		}
+	}
+	return nil
+}
+
+// Input returns the named public input, or an E1001 error if it is missing.
+func (e Envelope) Input(name string) (string, error) {
+	v, ok := e.PublicInputs[name]
+	if !ok {
+		return "", formatError("public input missing", fmt.Errorf("%q", name))
+	}
+	return v, nil
+}
+
+// ExpectInputs checks that the public inputs are exactly names.
+func (e Envelope) ExpectInputs(names ...string) error {
+	for _, name := range names {
+		if _, err := e.Input(name); err != nil {
+			return err
+		}
+	}
+	for name := range e.PublicInputs {
+		if !slices.Contains(names, name) {
+			return formatError("public input unexpected", fmt.Errorf("%q", name))
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the envelope with the proof in its declared encoding.
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	w := jsonEnvelope{
+		Version:       e.Version,
+		ProofVersion:  e.ProofVersion,
+		VKID:          e.VKID,
+		ParamsVersion: e.ParamsVersion,
+		Encoding:      e.encoding(),
+		PublicInputs:  e.PublicInputs,
+	}
+	switch w.Encoding {
+	case EncodingHex:
+		w.Proof = hex.EncodeToString(e.Proof)
+	case EncodingBase64:
+		w.Proof = base64.StdEncoding.EncodeToString(e.Proof)
+	default:
+		return nil, formatError("proof encoding unsupported", fmt.Errorf("%q", e.Encoding))
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes strictly: unknown fields, non-canonical proof
+// encodings and envelopes that fail Validate are rejected.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var w jsonEnvelope
+	if err := dec.Decode(&w); err != nil {
+		return formatError("json decode failed", err)
+	}
+	if dec.More() {
+		return formatError("json decode failed", fmt.Errorf("trailing data"))
+	}
+	var proof []byte
+	var err error
+	switch w.Encoding {
+	case EncodingHex:
+		proof, err = hex.DecodeString(w.Proof)
+		if err == nil && hex.EncodeToString(proof) != w.Proof {
+			err = fmt.Errorf("proof hex must be lowercase")
+		}
+	case EncodingBase64:
+		proof, err = base64.StdEncoding.Strict().DecodeString(w.Proof)
+	default:
+		err = fmt.Errorf("proof encoding unsupported: %q", w.Encoding)
+	}
+	if err != nil {
+		return formatError("proof decode failed", err)
+	}
+	out := Envelope{
+		Version:       w.Version,
+		ProofVersion:  w.ProofVersion,
+		VKID:          w.VKID,
+		ParamsVersion: w.ParamsVersion,
+		Encoding:      w.Encoding,
+		Proof:         proof,
+		PublicInputs:  w.PublicInputs,
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	*e = out
+	return nil
+}
+
+// MarshalCBOR encodes the envelope as a CBOR map with integer keys using the
+// core deterministic encoding, so equal envelopes encode to equal bytes.
+func (e Envelope) MarshalCBOR() ([]byte, error) {
+	return cborEncMode.Marshal(cborEnvelope{
+		Version:       e.Version,
+		ProofVersion:  e.ProofVersion,
+		VKID:          e.VKID,
+		ParamsVersion: e.ParamsVersion,
+		Encoding:      e.encoding(),
+		Proof:         e.Proof,
+		PublicInputs:  e.PublicInputs,
+	})
+}
+
+// UnmarshalCBOR decodes strictly: unknown or duplicate keys, tags, indefinite
+// lengths, non-deterministic encodings and envelopes that fail Validate are
+// rejected.
+func (e *Envelope) UnmarshalCBOR(data []byte) error {
+	var w cborEnvelope
+	if err := cborDecMode.Unmarshal(data, &w); err != nil {
+		return formatError("cbor decode failed", err)
+	}
+	out := Envelope{
+		Version:       w.Version,
+		ProofVersion:  w.ProofVersion,
+		VKID:          w.VKID,
+		ParamsVersion: w.ParamsVersion,
+		Encoding:      w.Encoding,
+		Proof:         w.Proof,
+		PublicInputs:  w.PublicInputs,
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	canonical, err := out.MarshalCBOR()
+	if err != nil {
+		return formatError("cbor encode failed", err)
+	}
+	if !bytes.Equal(canonical, data) {
+		return formatError("cbor encoding not deterministic", nil)
+	}
+	*e = out
+	return nil
+}
+
+// DecodeJSON decodes and validates a JSON envelope.
+func DecodeJSON(data []byte) (Envelope, error) {
+	var e Envelope
+	if err := e.UnmarshalJSON(data); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+// DecodeCBOR decodes and validates a CBOR envelope.
+func DecodeCBOR(data []byte) (Envelope, error) {
+	var e Envelope
+	if err := e.UnmarshalCBOR(data); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+// Decode decodes a JSON or CBOR envelope, telling them apart by the first
+// byte: JSON envelopes are objects ('{') and CBOR envelopes are maps.
+func Decode(data []byte) (Envelope, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return DecodeJSON(data)
+	}
+	return DecodeCBOR(data)
+}
+
+func (e Envelope) encoding() string {
+	if e.Encoding == "" {
+		return EncodingHex
+	}
+	return e.Encoding
+}
+
+func formatError(msg string, cause error) error {
+	if cause == nil {
+		return sdkerrors.New(sdkerrors.ErrProofFormat.Code, "envelope "+msg)
+	}
+	return sdkerrors.Wrap(sdkerrors.ErrProofFormat.Code, "envelope "+msg, cause)
+}
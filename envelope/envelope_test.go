@@ -0,0 +1,103 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func testEnvelope() Envelope {
+	return New([]byte{0xde, 0xad, 0xbe, 0xef}, "auth-proof-v4", strings.Repeat("ab", 32), "params-1", map[string]string{
+		InputCommitment: "12345",
+		InputSalt:       "deadbeef",
+	})
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	for _, encoding := range []string{EncodingHex, EncodingBase64} {
+		env := testEnvelope()
+		env.Encoding = encoding
+
+		data, err := json.Marshal(env)
+		if err != nil {
+			t.Fatalf("%s: json marshal failed: %v", encoding, err)
+		}
+		again, err := json.Marshal(env)
+		if err != nil || !bytes.Equal(data, again) {
+			t.Fatalf("%s: json encoding not stable", encoding)
+		}
+		got, err := Decode(data)
+		if err != nil {
+			t.Fatalf("%s: json decode failed: %v", encoding, err)
+		}
+		if !bytes.Equal(got.Proof, env.Proof) || got.Encoding != encoding || got.PublicInputs[InputSalt] != "deadbeef" {
+			t.Fatalf("%s: json round trip mismatch: %+v", encoding, got)
+		}
+
+		cbor, err := env.MarshalCBOR()
+		if err != nil {
+			t.Fatalf("%s: cbor marshal failed: %v", encoding, err)
+		}
+		if len(cbor) >= len(data) {
+			t.Fatalf("%s: cbor (%d bytes) not smaller than json (%d bytes)", encoding, len(cbor), len(data))
+		}
+		got, err = Decode(cbor)
+		if err != nil {
+			t.Fatalf("%s: cbor decode failed: %v", encoding, err)
+		}
+		if !bytes.Equal(got.Proof, env.Proof) || got.Encoding != encoding || got.VKID != env.VKID {
+			t.Fatalf("%s: cbor round trip mismatch: %+v", encoding, got)
+		}
+	}
+}
+
+func TestEnvelopeStrictDecode(t *testing.T) {
+	data, err := json.Marshal(testEnvelope())
+	if err != nil {
+		t.Fatalf("json marshal failed: %v", err)
+	}
+	valid := string(data)
+
+	cases := map[string]string{
+		"unknown field":   strings.Replace(valid, `"version"`, `"extra":1,"version"`, 1),
+		"version":         strings.Replace(valid, Version, "idz-envelope-v0", 1),
+		"uppercase hex":   strings.Replace(valid, "deadbeef", "DEADBEEF", 1),
+		"encoding":        strings.Replace(valid, `"encoding":"hex"`, `"encoding":"raw"`, 1),
+		"vk_id":           strings.Replace(valid, strings.Repeat("ab", 32), "vk-abc", 1),
+		"trailing data":   valid + "{}",
+		"input name":      strings.Replace(valid, `"salt"`, `"Salt"`, 1),
+		"missing version": strings.Replace(valid, `"version":"`+Version+`",`, "", 1),
+	}
+	for name, input := range cases {
+		if _, err := DecodeJSON([]byte(input)); sdkerrors.CodeOf(err) != sdkerrors.ErrProofFormat.Code {
+			t.Errorf("%s: expected E1001, got %v", name, err)
+		}
+	}
+
+	cbor, err := testEnvelope().MarshalCBOR()
+	if err != nil {
+		t.Fatalf("cbor marshal failed: %v", err)
+	}
+	if _, err := DecodeCBOR(append(cbor, 0x00)); err == nil {
+		t.Fatalf("expected trailing cbor data to be rejected")
+	}
+	if _, err := DecodeCBOR(cbor[:len(cbor)-1]); err == nil {
+		t.Fatalf("expected truncated cbor to be rejected")
+	}
+}
+
+func TestEnvelopeInputs(t *testing.T) {
+	env := testEnvelope()
+	if err := env.ExpectInputs(InputCommitment, InputSalt); err != nil {
+		t.Fatalf("expected inputs to match: %v", err)
+	}
+	if err := env.ExpectInputs(InputCommitment); err == nil {
+		t.Fatalf("expected unexpected input to be rejected")
+	}
+	if err := env.ExpectInputs(InputCommitment, InputSalt, InputIssuerKey); err == nil {
+		t.Fatalf("expected missing input to be rejected")
+	}
+}
@@ -7,6 +7,8 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 )
 
@@ -39,6 +41,29 @@ func Wrap(code, message string, cause error) *Error {
 	return &Error{Code: code, Message: message, Cause: cause}
 }
 
+// CodeOf returns the code of the first coded error in err's chain, or "" if there is none.
+// Besides *Error it recognizes errors exposing an ErrorCode method.
+func CodeOf(err error) string {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e.Code
+	}
+	var coded interface{ ErrorCode() string }
+	if stderrors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return ""
+}
+
+// FromContext returns an E1019 error wrapping ctx.Err() once ctx is canceled
+// or its deadline has passed, and nil before.
+func FromContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return Wrap(ErrCanceled.Code, "operation canceled", err)
+	}
+	return nil
+}
+
 // Authentication errors (E1xxx)
 var (
 	ErrProofFormat      = New("E1001", "invalid proof format")
@@ -53,6 +78,14 @@ var (
 	ErrMissingArguments = New("E1010", "missing required arguments")
 	ErrChallengeExpired = New("E1011", "challenge expired")
 	ErrChallengeInvalid = New("E1012", "challenge token invalid")
+	// E1013 is reserved for auth.ErrJTIAlreadyUsed.
+	ErrCredentialInvalid = New("E1014", "credential invalid")
+	ErrIssuerUntrusted   = New("E1015", "credential issuer not trusted")
+	ErrChannelMismatch   = New("E1016", "channel binding mismatch")
+	ErrRootUnknown       = New("E1017", "merkle root not accepted")
+	ErrNullifierUsed     = New("E1018", "nullifier already used in scope")
+	ErrCanceled          = New("E1019", "operation canceled or deadline exceeded")
+	ErrRateLimited       = New("E1020", "too many login attempts")
 )
 
 // Key/Setup errors (E2xxx)
@@ -63,6 +96,7 @@ var (
 	ErrKeyMismatch = New("E2004", "key fingerprint mismatch")
 	ErrSetupFailed = New("E2005", "setup failed")
 	ErrKeyRotation = New("E2006", "key rotation required")
+	ErrCeremony    = New("E2007", "setup ceremony transcript invalid")
 )
 
 // Cryptography errors (E3xxx)
@@ -1,6 +1,11 @@
 package errors
 
-import "testing"
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
 
 func TestErrorFormat(t *testing.T) {
 	err := ErrProofFormat
@@ -35,6 +40,8 @@ func TestErrorCodes(t *testing.T) {
 		{ErrEncryptionFailed, "E3001"},
 		{ErrConfigNotFound, "E4001"},
 		{ErrKeyRotation, "E2006"},
+		{ErrCanceled, "E1019"},
+		{ErrRateLimited, "E1020"},
 	}
 
 	for _, tt := range tests {
@@ -43,3 +50,37 @@ func TestErrorCodes(t *testing.T) {
 		}
 	}
 }
+
+type stageError struct{}
+
+func (stageError) Error() string     { return "verify: bad proof" }
+func (stageError) ErrorCode() string { return "E1003" }
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{Wrap("E1002", "commitment", stageError{}), "E1002"},
+		{fmt.Errorf("proof verification failed: %w", stageError{}), "E1003"},
+		{fmt.Errorf("plain"), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.code {
+			t.Errorf("CodeOf(%v) = %q, want %q", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := FromContext(ctx); err != nil {
+		t.Fatalf("live context: %v", err)
+	}
+	cancel()
+	err := FromContext(ctx)
+	if CodeOf(err) != "E1019" || !stderrors.Is(err, context.Canceled) {
+		t.Fatalf("expected E1019 wrapping context.Canceled, got %v", err)
+	}
+}
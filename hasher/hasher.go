@@ -0,0 +1,82 @@
+// Package hasher provides the field hashes behind commitments and challenge
+// bindings, with matching native and in-circuit implementations. MiMC is the
+// original hash; Poseidon2 (Merkle-Damgård over the width-2 permutation with
+// gnark-crypto's default BN254 parameters) is cheaper in-circuit and better studied.
+package hasher
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	nativeposeidon2 "github.com/consensys/gnark-crypto/ecc/bn254/fr/poseidon2"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	stdmimc "github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+)
+
+// Hash names.
+const (
+	MiMC      = "mimc"
+	Poseidon2 = "poseidon2"
+)
+
+// Normalize validates a hash name; empty selects MiMC.
+func Normalize(name string) (string, error) {
+	switch name {
+	case "", MiMC:
+		return MiMC, nil
+	case Poseidon2:
+		return Poseidon2, nil
+	default:
+		return "", fmt.Errorf("unknown hash %q", name)
+	}
+}
+
+// Sum hashes field elements natively, matching what New computes in-circuit.
+func Sum(name string, elems ...fr.Element) (fr.Element, error) {
+	name, err := Normalize(name)
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var h interface {
+		Write([]byte) (int, error)
+		Sum([]byte) []byte
+	}
+	if name == Poseidon2 {
+		h = nativeposeidon2.NewMerkleDamgardHasher()
+	} else {
+		h = mimc.NewMiMC()
+	}
+	for _, e := range elems {
+		b := e.Bytes()
+		if _, err := h.Write(b[:]); err != nil {
+			return fr.Element{}, err
+		}
+	}
+	var out fr.Element
+	out.SetBytes(h.Sum(nil))
+	return out, nil
+}
+
+// New returns the in-circuit hasher for name.
+func New(api frontend.API, name string) (hash.FieldHasher, error) {
+	name, err := Normalize(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == Poseidon2 {
+		params := nativeposeidon2.GetDefaultParameters()
+		perm, err := poseidon2.NewPoseidon2FromParameters(api, params.Width, params.NbFullRounds, params.NbPartialRounds)
+		if err != nil {
+			return nil, err
+		}
+		return hash.NewMerkleDamgardHasher(api, perm, 0), nil
+	}
+	h, err := stdmimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
@@ -0,0 +1,64 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type sumCircuit struct {
+	Hash   string `gnark:"-"`
+	In     [3]frontend.Variable
+	Digest frontend.Variable `gnark:",public"`
+}
+
+func (c *sumCircuit) Define(api frontend.API) error {
+	h, err := New(api, c.Hash)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In[:]...)
+	api.AssertIsEqual(h.Sum(), c.Digest)
+	return nil
+}
+
+func TestNativeMatchesCircuit(t *testing.T) {
+	var in [3]fr.Element
+	in[0].SetUint64(42)
+	in[1].SetRandom()
+	in[2].SetUint64(20000101)
+
+	digests := map[string]fr.Element{}
+	for _, name := range []string{MiMC, Poseidon2} {
+		digest, err := Sum(name, in[:]...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		digests[name] = digest
+
+		assignment := &sumCircuit{Hash: name, Digest: digest}
+		for i := range in {
+			assignment.In[i] = in[i]
+		}
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sumCircuit{Hash: name})
+		if err != nil {
+			t.Fatalf("%s compile: %v", name, err)
+		}
+		witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		if err != nil {
+			t.Fatalf("%s witness: %v", name, err)
+		}
+		if err := ccs.IsSolved(witness); err != nil {
+			t.Fatalf("%s: circuit digest differs from native: %v", name, err)
+		}
+	}
+	if digests[MiMC] == digests[Poseidon2] {
+		t.Fatalf("hashes should differ")
+	}
+	if _, err := Sum("sha1"); err == nil {
+		t.Fatalf("expected unknown hash error")
+	}
+}
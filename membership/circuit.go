@@ -0,0 +1,66 @@
+// Package membership proves that a commitment is a leaf of a commitment.Tree of
+// registered commitments without revealing which one, for anonymous logins.
+package membership
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// MembershipCircuit proves knowledge of the secret behind some leaf of the tree
+// with the public Root. The commitment, salt and leaf index stay private.
+type MembershipCircuit struct {
+	// Public inputs
+	Root      frontend.Variable `gnark:",public"`
+	Challenge frontend.Variable `gnark:",public"`
+
+	// Private inputs
+	SecretKey frontend.Variable
+	Salt      frontend.Variable
+	BirthDate frontend.Variable
+	Index     frontend.Variable
+	Path      [commitment.TreeDepth]frontend.Variable
+
+	// Hash selects the commitment and tree hash at compile time (hasher.MiMC when empty).
+	Hash string `gnark:"-"`
+}
+
+// Define implements the gnark circuit definition.
+func (circuit *MembershipCircuit) Define(api frontend.API) error {
+	if err := assertMember(api, circuit.Hash, circuit.SecretKey, circuit.Salt, circuit.BirthDate, circuit.Index, circuit.Path, circuit.Root); err != nil {
+		return err
+	}
+
+	// Challenge is non-zero (see commitment.ParseChallenge); the constraint also
+	// keeps it bound to the proof, as Groth16 ignores unconstrained public inputs.
+	api.AssertIsDifferent(circuit.Challenge, 0)
+	return nil
+}
+
+// assertMember constrains H(secret, salt, birthDate) to be the leaf at index
+// under root.
+func assertMember(api frontend.API, hash string, secret, salt, birthDate, index frontend.Variable, path [commitment.TreeDepth]frontend.Variable, root frontend.Variable) error {
+	// Leaf: H(secret, salt, birthDate), the registered commitment
+	leafHash, err := hasher.New(api, hash)
+	if err != nil {
+		return err
+	}
+	leafHash.Write(secret, salt, birthDate)
+	node := leafHash.Sum()
+
+	// Path: bit l of index set means the node is the right child at level l
+	bits := api.ToBinary(index, len(path))
+	for l, sibling := range path {
+		left := api.Select(bits[l], sibling, node)
+		right := api.Select(bits[l], node, sibling)
+		nodeHash, err := hasher.New(api, hash)
+		if err != nil {
+			return err
+		}
+		nodeHash.Write(left, right)
+		node = nodeHash.Sum()
+	}
+	api.AssertIsEqual(node, root)
+	return nil
+}
@@ -0,0 +1,199 @@
+package membership
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// NullifierCircuitName names the nullifier circuit in the key manifest.
+const NullifierCircuitName = "nullifier"
+
+// NullifierProofVersion is the semantic version for nullifier proofs ("-poseidon2" suffixed for SchemeV3).
+const NullifierProofVersion = "nullifier-proof-v1"
+
+//go:embed nullifier.pk
+var nullifierProvingKeyData []byte
+
+//go:embed nullifier_poseidon2.pk
+var nullifierPoseidon2ProvingKeyData []byte
+
+//go:embed nullifier.vk
+var nullifierVerifyingKeyData []byte
+
+//go:embed nullifier_poseidon2.vk
+var nullifierPoseidon2VerifyingKeyData []byte
+
+//go:embed nullifier.ccs
+var nullifierCCSData []byte
+
+//go:embed nullifier_poseidon2.ccs
+var nullifierPoseidon2CCSData []byte
+
+// NullifierProof is a membership proof with the scope it acts in and the
+// member's nullifier for that scope.
+type NullifierProof struct {
+	Proof     []byte
+	Scope     string // application scope name, e.g. "poll:2026-10"
+	Nullifier string // decimal field element, see commitment.ComputeNullifier
+}
+
+// GenerateNullifierProof is GenerateMembershipProof that also outputs the
+// member's nullifier for scope. To bind the action itself (a vote, a coupon
+// id), derive the challenge from it, e.g. commitment.DeriveChallenge(jti, vote).
+// The circuit is compiled on first use.
+func (p *Prover) GenerateNullifierProof(secret string, birthDate int, saltHex string, path commitment.MerklePath, root string, scope string, challenge string) (NullifierProof, error) {
+	if len(path.Siblings) != commitment.TreeDepth {
+		return NullifierProof{}, fmt.Errorf("membership path has %d levels, expected %d", len(path.Siblings), commitment.TreeDepth)
+	}
+	leaf, saltInt, derived, err := commitment.ComputeCommitmentWithScheme(secret, saltHex, birthDate, p.scheme, p.config)
+	if err != nil {
+		return NullifierProof{}, err
+	}
+	computed, err := path.ComputeRoot(leaf, p.scheme)
+	if err != nil {
+		return NullifierProof{}, err
+	}
+	if computed != root {
+		return NullifierProof{}, fmt.Errorf("commitment is not a leaf under root %s", root)
+	}
+	rootInt, err := commitment.ParseRoot(root)
+	if err != nil {
+		return NullifierProof{}, err
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return NullifierProof{}, err
+	}
+	nullifier, err := commitment.NullifierFromDerived(derived, scope, p.scheme)
+	if err != nil {
+		return NullifierProof{}, err
+	}
+	if err := p.loadNullifierCircuit(); err != nil {
+		return NullifierProof{}, err
+	}
+
+	var derivedInt big.Int
+	derived.BigInt(&derivedInt)
+	proof, err := backend.Prove(p.nullCCS, p.nullPK, &NullifierCircuit{
+		Root:      rootInt,
+		Scope:     commitment.ScopeElement(scope),
+		Nullifier: nullifier,
+		Challenge: challengeInt,
+		SecretKey: derivedInt,
+		Salt:      saltInt,
+		BirthDate: birthDate,
+		Index:     path.Index,
+		Path:      pathAssignment(path),
+	})
+	if err != nil {
+		return NullifierProof{}, fmt.Errorf("nullifier %w", err)
+	}
+	return NullifierProof{Proof: proof, Scope: scope, Nullifier: nullifier}, nil
+}
+
+func (p *Prover) loadNullifierCircuit() error {
+	p.nullOnce.Do(func() {
+		pkData, ccsData := nullifierProvingKeyData, nullifierCCSData
+		if p.scheme == commitment.SchemeV3 {
+			pkData, ccsData = nullifierPoseidon2ProvingKeyData, nullifierPoseidon2CCSData
+		}
+		ccs, err := backend.LoadConstraintSystem(backend.Groth16, ccsData, newNullifierCircuit(p.scheme))
+		if err != nil {
+			p.nullErr = fmt.Errorf("nullifier circuit load failed: %w", err)
+			return
+		}
+		pk, err := backend.LoadProvingKey(backend.Groth16, pkData)
+		if err != nil {
+			p.nullErr = fmt.Errorf("nullifier proving key parse failed: %w", err)
+			return
+		}
+		p.nullCCS = ccs
+		p.nullPK = pk
+	})
+	return p.nullErr
+}
+
+// VerifyNullifier verifies a nullifier proof for scope, the scope the server
+// expects (e.g. the current poll), against root and challenge like
+// VerifyMembership. A proof made under any other scope fails with E4002, since
+// it would carry a fresh nullifier. When VerifierConfig.Nullifiers is set, the
+// nullifier is then recorded for its scope and a second proof with the same
+// nullifier fails with E1018; otherwise the caller must record it.
+func (v *Verifier) VerifyNullifier(np NullifierProof, scope string, root string, challenge string) (bool, error) {
+	return v.verifyNullifier(context.Background(), np, scope, root, challenge)
+}
+
+func (v *Verifier) verifyNullifier(ctx context.Context, np NullifierProof, scope string, root string, challenge string) (bool, error) {
+	rootInt, challengeInt, err := v.parsePublic(root, challenge)
+	if err != nil {
+		return false, err
+	}
+	if scope == "" {
+		return false, sdkerrors.New(sdkerrors.ErrMissingArguments.Code, "nullifier scope missing")
+	}
+	if np.Scope != scope {
+		return false, sdkerrors.New(sdkerrors.ErrPolicyMismatch.Code, "nullifier scope mismatch")
+	}
+	nullifierInt, err := commitment.ParseNullifier(np.Nullifier)
+	if err != nil {
+		return false, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "nullifier parse failed", err)
+	}
+	if v.nullifiers != nil && v.nullifiers.Exists(np.Scope, np.Nullifier) {
+		return false, ErrNullifierUsed
+	}
+	if err := v.loadNullifierKey(); err != nil {
+		return false, err
+	}
+
+	assignment := &NullifierCircuit{
+		Root:      rootInt,
+		Scope:     commitment.ScopeElement(np.Scope),
+		Nullifier: nullifierInt,
+		Challenge: challengeInt,
+	}
+	if err := backend.VerifyContext(ctx, v.nullVK, np.Proof, assignment); err != nil {
+		return false, backend.VerifyError(err, "membership")
+	}
+	if v.nullifiers != nil {
+		if err := v.nullifiers.Store(np.Scope, np.Nullifier); err != nil {
+			return false, sdkerrors.Wrap(ErrNullifierUsed.Code, "nullifier already used", err)
+		}
+	}
+	return true, nil
+}
+
+func (v *Verifier) loadNullifierKey() error {
+	v.nullOnce.Do(func() {
+		vkData := nullifierVerifyingKeyData
+		if v.scheme == commitment.SchemeV3 {
+			vkData = nullifierPoseidon2VerifyingKeyData
+		}
+		vk, err := backend.LoadVerifyingKey(backend.Groth16, vkData)
+		if err != nil {
+			v.nullErr = sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "nullifier verifying key parse failed", err)
+			return
+		}
+		v.nullVK = vk
+	})
+	return v.nullErr
+}
+
+// NullifierProofVersionFor returns NullifierProofVersion, suffixed "-poseidon2" for SchemeV3.
+func NullifierProofVersionFor(scheme int) string {
+	if scheme == commitment.SchemeV3 {
+		return NullifierProofVersion + "-" + hasher.Poseidon2
+	}
+	return NullifierProofVersion
+}
+
+func newNullifierCircuit(scheme int) *NullifierCircuit {
+	hash, _ := commitment.HashForScheme(scheme)
+	return &NullifierCircuit{Hash: hash}
+}
@@ -0,0 +1,48 @@
+package membership
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// NullifierCircuit is MembershipCircuit with a public nullifier H(secret, scope).
+// The nullifier is the same for every proof a member makes in a scope and
+// unlinkable across scopes, so a verifier can refuse a second action per scope
+// without learning who acted.
+type NullifierCircuit struct {
+	// Public inputs
+	Root      frontend.Variable `gnark:",public"`
+	Scope     frontend.Variable `gnark:",public"`
+	Nullifier frontend.Variable `gnark:",public"`
+	Challenge frontend.Variable `gnark:",public"`
+
+	// Private inputs
+	SecretKey frontend.Variable
+	Salt      frontend.Variable
+	BirthDate frontend.Variable
+	Index     frontend.Variable
+	Path      [commitment.TreeDepth]frontend.Variable
+
+	// Hash selects the commitment, tree and nullifier hash at compile time (hasher.MiMC when empty).
+	Hash string `gnark:"-"`
+}
+
+// Define implements the gnark circuit definition.
+func (circuit *NullifierCircuit) Define(api frontend.API) error {
+	if err := assertMember(api, circuit.Hash, circuit.SecretKey, circuit.Salt, circuit.BirthDate, circuit.Index, circuit.Path, circuit.Root); err != nil {
+		return err
+	}
+
+	// Nullifier: H(secret, scope)
+	nullHash, err := hasher.New(api, circuit.Hash)
+	if err != nil {
+		return err
+	}
+	nullHash.Write(circuit.SecretKey, circuit.Scope)
+	api.AssertIsEqual(nullHash.Sum(), circuit.Nullifier)
+
+	// See MembershipCircuit: keeps Challenge bound to the proof.
+	api.AssertIsDifferent(circuit.Challenge, 0)
+	return nil
+}
@@ -0,0 +1,129 @@
+package membership
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+// NullifierStore records the nullifiers used per scope so each member acts at
+// most once in a scope. Unlike token JTIs, nullifiers do not expire; a scope
+// ends when the application stops accepting proofs for it.
+type NullifierStore interface {
+	// Store records nullifier for scope. Returns ErrNullifierUsed if it was already recorded.
+	Store(scope string, nullifier string) error
+	// Exists checks if nullifier has been used in scope.
+	Exists(scope string, nullifier string) bool
+}
+
+// ErrNullifierUsed is returned when a nullifier has already been used in its scope.
+var ErrNullifierUsed = sdkerrors.ErrNullifierUsed
+
+type nullifierKey struct {
+	Scope     string `json:"scope"`
+	Nullifier string `json:"nullifier"`
+}
+
+// MemoryNullifierStore is an in-memory implementation of NullifierStore.
+type MemoryNullifierStore struct {
+	used map[nullifierKey]struct{}
+	mu   sync.RWMutex
+}
+
+// NewMemoryNullifierStore creates a new in-memory nullifier store.
+func NewMemoryNullifierStore() *MemoryNullifierStore {
+	return &MemoryNullifierStore{used: make(map[nullifierKey]struct{})}
+}
+
+// Store records a nullifier. Returns error if already used in scope.
+func (m *MemoryNullifierStore) Store(scope string, nullifier string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := nullifierKey{scope, nullifier}
+	if _, exists := m.used[key]; exists {
+		return ErrNullifierUsed
+	}
+	m.used[key] = struct{}{}
+	return nil
+}
+
+// Exists checks if a nullifier has been used in scope.
+func (m *MemoryNullifierStore) Exists(scope string, nullifier string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.used[nullifierKey{scope, nullifier}]
+	return exists
+}
+
+// FileNullifierStore is a NullifierStore persisted to an append-only file with
+// one JSON object per line, so used nullifiers survive restarts. Every Store
+// is synced before it returns.
+type FileNullifierStore struct {
+	mem  *MemoryNullifierStore
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileNullifierStore opens (or creates) the store at path and loads the
+// nullifiers recorded so far.
+func NewFileNullifierStore(path string) (*FileNullifierStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("nullifier store open failed: %w", err)
+	}
+	mem := NewMemoryNullifierStore()
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var key nullifierKey
+		if err := json.Unmarshal(scanner.Bytes(), &key); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("nullifier store line %d invalid: %w", line, err)
+		}
+		mem.used[key] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("nullifier store read failed: %w", err)
+	}
+	return &FileNullifierStore{mem: mem, file: file}, nil
+}
+
+// Store records a nullifier and appends it to the file. Returns error if
+// already used in scope.
+func (f *FileNullifierStore) Store(scope string, nullifier string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.mem.Exists(scope, nullifier) {
+		return ErrNullifierUsed
+	}
+	line, err := json.Marshal(nullifierKey{scope, nullifier})
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("nullifier store write failed: %w", err)
+	}
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("nullifier store sync failed: %w", err)
+	}
+	return f.mem.Store(scope, nullifier)
+}
+
+// Exists checks if a nullifier has been used in scope.
+func (f *FileNullifierStore) Exists(scope string, nullifier string) bool {
+	return f.mem.Exists(scope, nullifier)
+}
+
+// Close closes the underlying file.
+func (f *FileNullifierStore) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,120 @@
+package membership
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestNullifierProof(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	salt := "0123456789abcdef0123456789abcdef"
+
+	for _, scheme := range []int{commitment.SchemeV2, commitment.SchemeV3} {
+		tree, _ := commitment.NewTree(0, scheme, 0)
+		leaf, _, _, err := commitment.ComputeCommitmentWithScheme("member-secret", salt, commitment.NoBirthDate, scheme, cfg)
+		if err != nil {
+			t.Fatalf("v%d: commitment failed: %v", scheme, err)
+		}
+		tree.Append("4242")
+		index, _ := tree.Append(leaf)
+		path, _ := tree.Path(index)
+		root := tree.Root()
+
+		prover, err := NewProver(cfg, scheme)
+		if err != nil {
+			t.Fatalf("v%d: prover init failed: %v", scheme, err)
+		}
+		verifier, err := NewVerifier(VerifierConfig{Scheme: scheme, Roots: tree, Nullifiers: NewMemoryNullifierStore()})
+		if err != nil {
+			t.Fatalf("v%d: verifier init failed: %v", scheme, err)
+		}
+
+		np, err := prover.GenerateNullifierProof("member-secret", commitment.NoBirthDate, salt, path, root, "poll:1", "777")
+		if err != nil {
+			t.Fatalf("v%d: proof generation failed: %v", scheme, err)
+		}
+		if want, _ := commitment.ComputeNullifier("member-secret", salt, "poll:1", scheme, cfg); np.Nullifier != want {
+			t.Fatalf("v%d: nullifier differs from ComputeNullifier", scheme)
+		}
+
+		forged := np
+		forged.Scope = "poll:2"
+		if _, err := verifier.VerifyNullifier(forged, "poll:2", root, "777"); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+			t.Fatalf("v%d: expected E1003 for a relabeled scope, got %v", scheme, err)
+		}
+		// A proof made under a scope the server did not ask for is refused.
+		if _, err := verifier.VerifyNullifier(np, "poll:1x", root, "777"); sdkerrors.CodeOf(err) != sdkerrors.ErrPolicyMismatch.Code {
+			t.Fatalf("v%d: expected E4002 for an unexpected scope, got %v", scheme, err)
+		}
+		if ok, err := verifier.VerifyNullifier(np, "poll:1", root, "777"); err != nil || !ok {
+			t.Fatalf("v%d: verification failed: %v", scheme, err)
+		}
+
+		// A second action in the scope is rejected, even with a fresh proof.
+		again, err := prover.GenerateNullifierProof("member-secret", commitment.NoBirthDate, salt, path, root, "poll:1", "778")
+		if err != nil {
+			t.Fatalf("v%d: proof generation failed: %v", scheme, err)
+		}
+		if again.Nullifier != np.Nullifier {
+			t.Fatalf("v%d: nullifier not stable within a scope", scheme)
+		}
+		if res := verifier.VerifyNullifierResult(context.Background(), again, "poll:1", root, "778"); res.Code != sdkerrors.ErrNullifierUsed.Code || res.Stage != common.StageReplay {
+			t.Fatalf("v%d: expected E1018 at the replay stage on reuse, got %+v", scheme, res)
+		}
+		// Padded spellings of a spent nullifier are not fresh nullifiers.
+		for _, padded := range []string{"0" + again.Nullifier, "+" + again.Nullifier} {
+			replay := again
+			replay.Nullifier = padded
+			if _, err := verifier.VerifyNullifier(replay, "poll:1", root, "778"); sdkerrors.CodeOf(err) != sdkerrors.ErrCommitmentParse.Code {
+				t.Fatalf("v%d: expected E1002 for nullifier %q, got %v", scheme, padded, err)
+			}
+		}
+
+		// Another scope yields an unrelated nullifier and is accepted.
+		other, err := prover.GenerateNullifierProof("member-secret", commitment.NoBirthDate, salt, path, root, "poll:2", "779")
+		if err != nil {
+			t.Fatalf("v%d: proof generation failed: %v", scheme, err)
+		}
+		if other.Nullifier == np.Nullifier {
+			t.Fatalf("v%d: nullifier linkable across scopes", scheme)
+		}
+		if ok, err := verifier.VerifyNullifier(other, "poll:2", root, "779"); err != nil || !ok {
+			t.Fatalf("v%d: verification in another scope failed: %v", scheme, err)
+		}
+	}
+}
+
+func TestFileNullifierStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nullifiers.jsonl")
+	store, err := NewFileNullifierStore(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if err := store.Store("poll:1", "123"); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if err := store.Store("poll:1", "123"); err != ErrNullifierUsed {
+		t.Fatalf("expected ErrNullifierUsed, got %v", err)
+	}
+	if err := store.Store("poll:2", "123"); err != nil {
+		t.Fatalf("store in another scope failed: %v", err)
+	}
+	store.Close()
+
+	reopened, err := NewFileNullifierStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if !reopened.Exists("poll:1", "123") || !reopened.Exists("poll:2", "123") || reopened.Exists("poll:3", "123") {
+		t.Fatal("nullifiers not persisted per scope")
+	}
+	if err := reopened.Store("poll:1", "123"); err != ErrNullifierUsed {
+		t.Fatalf("expected ErrNullifierUsed after reopen, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package membership
+
+import (
+	_ "embed"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	"github.com/ghdehrl12345/identify_sdk/v2/hasher"
+)
+
+// CircuitName names the membership circuit in the key manifest.
+const CircuitName = "membership"
+
+// ProofVersion is the semantic version for membership proofs ("-poseidon2" suffixed for SchemeV3).
+const ProofVersion = "membership-proof-v1"
+
+//go:embed membership.pk
+var membershipProvingKeyData []byte
+
+//go:embed membership_poseidon2.pk
+var membershipPoseidon2ProvingKeyData []byte
+
+//go:embed membership.ccs
+var membershipCCSData []byte
+
+//go:embed membership_poseidon2.ccs
+var membershipPoseidon2CCSData []byte
+
+// EmbeddedProvingKeyID is the blake2b-256 fingerprint of the embedded MiMC membership proving key.
+var EmbeddedProvingKeyID = backend.KeyID(membershipProvingKeyData)
+
+// EmbeddedPoseidon2ProvingKeyID is the blake2b-256 fingerprint of the embedded Poseidon2 membership proving key.
+var EmbeddedPoseidon2ProvingKeyID = backend.KeyID(membershipPoseidon2ProvingKeyData)
+
+// Prover generates membership proofs. Membership proofs use Groth16.
+type Prover struct {
+	provingKey *backend.ProvingKey
+	ccs        constraint.ConstraintSystem
+	config     common.SharedConfig
+	scheme     int
+
+	nullOnce sync.Once
+	nullPK   *backend.ProvingKey
+	nullCCS  constraint.ConstraintSystem
+	nullErr  error
+}
+
+// NewProver creates a membership prover for trees of scheme (0 selects
+// commitment.CurrentScheme) with cfg's Argon2 parameters.
+func NewProver(cfg common.SharedConfig, scheme int) (*Prover, error) {
+	scheme, err := commitment.NormalizeScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	pkData, ccsData := membershipProvingKeyData, membershipCCSData
+	if scheme == commitment.SchemeV3 {
+		pkData, ccsData = membershipPoseidon2ProvingKeyData, membershipPoseidon2CCSData
+	}
+	ccs, err := backend.LoadConstraintSystem(backend.Groth16, ccsData, newCircuit(scheme))
+	if err != nil {
+		return nil, fmt.Errorf("membership circuit load failed: %w", err)
+	}
+	if len(pkData) == 0 {
+		return nil, fmt.Errorf("embedded membership proving key is empty (run setup)")
+	}
+	pk, err := backend.LoadProvingKey(backend.Groth16, pkData)
+	if err != nil {
+		return nil, fmt.Errorf("membership proving key parse failed: %w", err)
+	}
+	return &Prover{
+		provingKey: pk,
+		ccs:        ccs,
+		config:     cfg,
+		scheme:     scheme,
+	}, nil
+}
+
+// GenerateMembershipProof proves that the commitment of secret, saltHex and
+// birthDate (commitment.NoBirthDate if none) is the leaf at path under root,
+// for a challenge issued by the verifier. The path must come from a tree of
+// depth commitment.TreeDepth built with the prover's scheme.
+func (p *Prover) GenerateMembershipProof(secret string, birthDate int, saltHex string, path commitment.MerklePath, root string, challenge string) ([]byte, error) {
+	if len(path.Siblings) != commitment.TreeDepth {
+		return nil, fmt.Errorf("membership path has %d levels, expected %d", len(path.Siblings), commitment.TreeDepth)
+	}
+	leaf, saltInt, derived, err := commitment.ComputeCommitmentWithScheme(secret, saltHex, birthDate, p.scheme, p.config)
+	if err != nil {
+		return nil, err
+	}
+	computed, err := path.ComputeRoot(leaf, p.scheme)
+	if err != nil {
+		return nil, err
+	}
+	if computed != root {
+		return nil, fmt.Errorf("commitment is not a leaf under root %s", root)
+	}
+	rootInt, err := commitment.ParseRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	challengeInt, err := commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	var derivedInt big.Int
+	derived.BigInt(&derivedInt)
+	assignment := &MembershipCircuit{
+		Root:      rootInt,
+		Challenge: challengeInt,
+		SecretKey: derivedInt,
+		Salt:      saltInt,
+		BirthDate: birthDate,
+		Index:     path.Index,
+		Path:      pathAssignment(path),
+	}
+
+	proof, err := backend.Prove(p.ccs, p.provingKey, assignment)
+	if err != nil {
+		return nil, fmt.Errorf("membership %w", err)
+	}
+	return proof, nil
+}
+
+// Scheme returns the commitment scheme of the trees this prover proves against.
+func (p *Prover) Scheme() int {
+	return p.scheme
+}
+
+// ProvingKeyID returns the fingerprint of the membership proving key for this prover's scheme.
+func (p *Prover) ProvingKeyID() string {
+	if p.scheme == commitment.SchemeV3 {
+		return EmbeddedPoseidon2ProvingKeyID
+	}
+	return EmbeddedProvingKeyID
+}
+
+// ProofVersionFor returns ProofVersion, suffixed "-poseidon2" for SchemeV3.
+func ProofVersionFor(scheme int) string {
+	if scheme == commitment.SchemeV3 {
+		return ProofVersion + "-" + hasher.Poseidon2
+	}
+	return ProofVersion
+}
+
+func pathAssignment(path commitment.MerklePath) [commitment.TreeDepth]frontend.Variable {
+	var out [commitment.TreeDepth]frontend.Variable
+	for l, s := range path.Siblings {
+		out[l] = s
+	}
+	return out
+}
+
+func newCircuit(scheme int) *MembershipCircuit {
+	hash, _ := commitment.HashForScheme(scheme)
+	return &MembershipCircuit{Hash: hash}
+}
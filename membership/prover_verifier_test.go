@@ -0,0 +1,106 @@
+package membership
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+func TestMembershipProof(t *testing.T) {
+	cfg := common.DefaultSharedConfig()
+	salt := "0123456789abcdef0123456789abcdef"
+
+	for _, scheme := range []int{commitment.SchemeV2, commitment.SchemeV3} {
+		tree, err := commitment.NewTree(0, scheme, 0)
+		if err != nil {
+			t.Fatalf("v%d: tree failed: %v", scheme, err)
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := tree.Append(fmt.Sprint(7000 + i)); err != nil {
+				t.Fatalf("v%d: append failed: %v", scheme, err)
+			}
+		}
+		leaf, _, _, err := commitment.ComputeCommitmentWithScheme("member-secret", salt, 19900101, scheme, cfg)
+		if err != nil {
+			t.Fatalf("v%d: commitment failed: %v", scheme, err)
+		}
+		index, err := tree.Append(leaf)
+		if err != nil {
+			t.Fatalf("v%d: append failed: %v", scheme, err)
+		}
+		path, err := tree.Path(index)
+		if err != nil {
+			t.Fatalf("v%d: path failed: %v", scheme, err)
+		}
+		root := tree.Root()
+
+		prover, err := NewProver(cfg, scheme)
+		if err != nil {
+			t.Fatalf("v%d: prover init failed: %v", scheme, err)
+		}
+		verifier, err := NewVerifier(VerifierConfig{Scheme: scheme, Roots: tree})
+		if err != nil {
+			t.Fatalf("v%d: verifier init failed: %v", scheme, err)
+		}
+
+		if _, err := prover.GenerateMembershipProof("wrong-secret", 19900101, salt, path, root, "12345"); err == nil {
+			t.Fatalf("v%d: expected non-member error", scheme)
+		}
+		proof, err := prover.GenerateMembershipProof("member-secret", 19900101, salt, path, root, "12345")
+		if err != nil {
+			t.Fatalf("v%d: proof generation failed: %v", scheme, err)
+		}
+		if ok, err := verifier.VerifyMembership(proof, root, "12345"); err != nil || !ok {
+			t.Fatalf("v%d: verification failed: %v", scheme, err)
+		}
+		if _, err := verifier.VerifyMembership(proof, root, "12346"); sdkerrors.CodeOf(err) != sdkerrors.ErrVerificationFail.Code {
+			t.Fatalf("v%d: expected E1003 for another challenge, got %v", scheme, err)
+		}
+
+		// The proof stays valid while its root is in the history, then is rejected.
+		for i := 0; i < commitment.DefaultRootHistory; i++ {
+			if _, err := tree.Append(fmt.Sprint(8000 + i)); err != nil {
+				t.Fatalf("v%d: append failed: %v", scheme, err)
+			}
+			if i == 0 {
+				if ok, err := verifier.VerifyMembership(proof, root, "12345"); err != nil || !ok {
+					t.Fatalf("v%d: recent root rejected: %v", scheme, err)
+				}
+			}
+		}
+		if _, err := verifier.VerifyMembership(proof, root, "12345"); sdkerrors.CodeOf(err) != sdkerrors.ErrRootUnknown.Code {
+			t.Fatalf("v%d: expected E1017 for an expired root, got %v", scheme, err)
+		}
+	}
+}
+
+// TestEmbeddedConstraintSystems fails when a circuit changed without
+// regenerating the shipped constraint systems (go run ./cmd/setup -ccs-only).
+func TestEmbeddedConstraintSystems(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		circuit frontend.Circuit
+		data    []byte
+	}{
+		{"membership", newCircuit(commitment.SchemeV2), membershipCCSData},
+		{"membership_poseidon2", newCircuit(commitment.SchemeV3), membershipPoseidon2CCSData},
+		{"nullifier", newNullifierCircuit(commitment.SchemeV2), nullifierCCSData},
+		{"nullifier_poseidon2", newNullifierCircuit(commitment.SchemeV3), nullifierPoseidon2CCSData},
+	} {
+		ccs, err := backend.Compile(backend.Groth16, c.circuit)
+		if err != nil {
+			t.Fatalf("%s compile: %v", c.name, err)
+		}
+		var buf bytes.Buffer
+		ccs.WriteTo(&buf)
+		if !bytes.Equal(buf.Bytes(), c.data) {
+			t.Fatalf("%s.ccs is stale", c.name)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package membership
+
+import (
+	"context"
+	"time"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/common"
+)
+
+// VerifyMembershipResult is VerifyMembership with a context, reporting a
+// common.VerificationResult: the error code and stage of a rejected proof, or
+// the vk_id and root for a valid one.
+func (v *Verifier) VerifyMembershipResult(ctx context.Context, proofBytes []byte, root string, challenge string) common.VerificationResult {
+	start := time.Now()
+	_, err := v.verifyMembership(ctx, proofBytes, root, challenge)
+	return common.NewVerificationResult(start, map[string]string{
+		common.ClaimVKID: v.VerifyingKeyID(),
+		common.ClaimRoot: root,
+	}, err)
+}
+
+// VerifyNullifierResult is VerifyNullifier with a context, reporting a
+// common.VerificationResult. A valid result carries the root, scope and
+// nullifier; a reused nullifier is reported at the replay stage.
+func (v *Verifier) VerifyNullifierResult(ctx context.Context, np NullifierProof, scope string, root string, challenge string) common.VerificationResult {
+	start := time.Now()
+	_, err := v.verifyNullifier(ctx, np, scope, root, challenge)
+	return common.NewVerificationResult(start, map[string]string{
+		common.ClaimRoot:      root,
+		common.ClaimScope:     np.Scope,
+		common.ClaimNullifier: np.Nullifier,
+	}, err)
+}
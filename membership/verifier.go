@@ -0,0 +1,129 @@
+package membership
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ghdehrl12345/identify_sdk/v2/backend"
+	"github.com/ghdehrl12345/identify_sdk/v2/commitment"
+	sdkerrors "github.com/ghdehrl12345/identify_sdk/v2/errors"
+)
+
+//go:embed membership.vk
+var membershipVerifyingKeyData []byte
+
+//go:embed membership_poseidon2.vk
+var membershipPoseidon2VerifyingKeyData []byte
+
+// EmbeddedVerifyingKeyID is the blake2b-256 fingerprint of the embedded MiMC membership verifying key.
+var EmbeddedVerifyingKeyID = backend.KeyID(membershipVerifyingKeyData)
+
+// EmbeddedPoseidon2VerifyingKeyID is the blake2b-256 fingerprint of the embedded Poseidon2 membership verifying key.
+var EmbeddedPoseidon2VerifyingKeyID = backend.KeyID(membershipPoseidon2VerifyingKeyData)
+
+// RootSet reports whether a tree root is accepted. *commitment.Tree implements
+// it with its root history.
+type RootSet interface {
+	KnownRoot(root string) bool
+}
+
+// VerifierConfig holds configuration for the membership verifier.
+type VerifierConfig struct {
+	Scheme     int            // optional: commitment scheme of the tree (0 selects commitment.CurrentScheme)
+	Roots      RootSet        // optional: accepted roots; proofs against other roots fail with E1017
+	Nullifiers NullifierStore // optional: records nullifiers; reuse in a scope fails with E1018
+	ExpectedVK string         // optional: expected verifying key fingerprint
+}
+
+// Verifier verifies membership proofs.
+type Verifier struct {
+	verifyingKey *backend.VerifyingKey
+	scheme       int
+	roots        RootSet
+	nullifiers   NullifierStore
+
+	nullOnce sync.Once
+	nullVK   *backend.VerifyingKey
+	nullErr  error
+}
+
+// NewVerifier creates a membership verifier.
+func NewVerifier(cfg VerifierConfig) (*Verifier, error) {
+	scheme, err := commitment.NormalizeScheme(cfg.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	vkData, vkID := membershipVerifyingKeyData, EmbeddedVerifyingKeyID
+	if scheme == commitment.SchemeV3 {
+		vkData, vkID = membershipPoseidon2VerifyingKeyData, EmbeddedPoseidon2VerifyingKeyID
+	}
+	if len(vkData) == 0 {
+		return nil, sdkerrors.ErrKeyNotFound
+	}
+	vk, err := backend.LoadVerifyingKey(backend.Groth16, vkData)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyParse.Code, "membership verifying key parse failed", err)
+	}
+	if cfg.ExpectedVK != "" && cfg.ExpectedVK != vkID {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrKeyMismatch.Code, "membership verifying key fingerprint mismatch", fmt.Errorf("expected %s got %s", cfg.ExpectedVK, vkID))
+	}
+	return &Verifier{
+		verifyingKey: vk,
+		scheme:       scheme,
+		roots:        cfg.Roots,
+		nullifiers:   cfg.Nullifiers,
+	}, nil
+}
+
+// VerifyMembership verifies that proof was made by the owner of some leaf of
+// the tree with root, for challenge. The verifier learns nothing about which
+// leaf. Without VerifierConfig.Roots the caller must check that root is a
+// current root of its tree.
+func (v *Verifier) VerifyMembership(proofBytes []byte, root string, challenge string) (bool, error) {
+	return v.verifyMembership(context.Background(), proofBytes, root, challenge)
+}
+
+func (v *Verifier) verifyMembership(ctx context.Context, proofBytes []byte, root string, challenge string) (bool, error) {
+	rootInt, challengeInt, err := v.parsePublic(root, challenge)
+	if err != nil {
+		return false, err
+	}
+
+	assignment := &MembershipCircuit{Root: rootInt, Challenge: challengeInt}
+	if err := backend.VerifyContext(ctx, v.verifyingKey, proofBytes, assignment); err != nil {
+		return false, backend.VerifyError(err, "membership")
+	}
+	return true, nil
+}
+
+// parsePublic parses and checks the root and challenge shared by membership and nullifier proofs.
+func (v *Verifier) parsePublic(root string, challenge string) (rootInt *big.Int, challengeInt *big.Int, err error) {
+	rootInt, err = commitment.ParseRoot(root)
+	if err != nil {
+		return nil, nil, sdkerrors.Wrap(sdkerrors.ErrCommitmentParse.Code, "root parse failed", err)
+	}
+	if v.roots != nil && !v.roots.KnownRoot(root) {
+		return nil, nil, sdkerrors.ErrRootUnknown
+	}
+	challengeInt, err = commitment.ParseChallenge(challenge)
+	if err != nil {
+		return nil, nil, sdkerrors.Wrap(sdkerrors.ErrChallengeInvalid.Code, "challenge invalid", err)
+	}
+	return rootInt, challengeInt, nil
+}
+
+// Scheme returns the commitment scheme of the tree this verifier accepts.
+func (v *Verifier) Scheme() int {
+	return v.scheme
+}
+
+// VerifyingKeyID returns the fingerprint of the membership verifying key for this verifier's scheme.
+func (v *Verifier) VerifyingKeyID() string {
+	if v.scheme == commitment.SchemeV3 {
+		return EmbeddedPoseidon2VerifyingKeyID
+	}
+	return EmbeddedVerifyingKeyID
+}
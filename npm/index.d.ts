@@ -3,9 +3,23 @@
  */
 export interface Config {
     targetYear?: number;
+    /** Policy date as YYYYMMDD; defaults to Dec 31 of targetYear. */
+    targetDate?: number;
+    /** Use today's date as the policy date (server bundles with dynamic_date); the default when no year or date is set. */
+    dynamicDate?: boolean;
     limitAge?: number;
+    /** "international" (full age, default) or "korean-year" (current year - birth year). */
+    ageMode?: "international" | "korean-year";
     argonMemory?: number;
     argonIterations?: number;
+    /** Auth circuit from the server policy bundle; "login" skips the age predicate. Read at init. */
+    circuit?: "age-login" | "login";
+    /** Proving backend from the server policy bundle; must match the proving key. Read at init. */
+    backend?: "groth16" | "plonk";
+    /** Commitment scheme from the server policy bundle: 2 (MiMC, default) or 3 (Poseidon2). Read at init. */
+    scheme?: 2 | 3;
+    /** vk_id of the verifying key matching a custom proving key, sent in proof envelopes. Read at init. */
+    vkId?: string;
 }
 
 /**
@@ -28,8 +42,15 @@ export interface ProofResult {
     binding: string;
     salt: string;
     pkId?: string;
+    circuit?: "age-login" | "login";
+    backend?: "groth16" | "plonk";
+    scheme?: 2 | 3;
     policyYear?: number;
+    policyDate?: number;
     limitAge?: number;
+    ageMode?: string;
+    /** Canonical proof envelope (idz-envelope-v1) as a JSON string; send it as-is to the verifier. */
+    envelope?: string;
 }
 
 /**
@@ -49,17 +70,19 @@ export declare class IdentifyClient {
     /**
      * Generate a ZKP authentication proof.
      * @param secret - User's secret (password)
-     * @param birthYear - User's birth year
+     * @param birthDate - Birth date committed at registration (YYYYMMDD)
      * @param config - Configuration { targetYear, limitAge }
-     * @param challenge - Server-issued challenge
+     * @param challenge - Server-issued challenge (decimal or 0x-hex string; numbers from ct-v1 tokens)
      * @param saltHex - Salt in hex format
+     * @param channelBinding - Optional channel binding from the server (decimal field element)
      */
     generateProof(
         secret: string,
-        birthYear: number,
+        birthDate: number,
         config: Config,
-        challenge: number,
-        saltHex: string
+        challenge: string | number,
+        saltHex: string,
+        channelBinding?: string
     ): ProofResult;
 
     /**
@@ -36,15 +36,23 @@ function sanitizeError(message, genericMessage = "An error occurred") {
  * @param {Object} opts - Options
  * @param {string} [opts.wasmPath] - Path to identify.wasm
  * @param {Uint8Array|Buffer} [opts.wasmBytes] - In-memory wasm bytes
- * @param {string} [opts.provingKeyPath] - Path to user.pk
+ * @param {string} [opts.provingKeyPath] - Path to user.pk (login.pk when config.circuit is "login", *_poseidon2*.pk when config.scheme is 3, *_plonk.pk when config.backend is "plonk")
  * @param {Uint8Array|Buffer} [opts.provingKeyBytes] - In-memory proving key
- * @param {Object} [opts.config] - Configuration { targetYear, limitAge, argonMemory, argonIterations }
+ * @param {Object} [opts.config] - Configuration { targetYear, limitAge, argonMemory, argonIterations, circuit, backend, scheme }
  * @returns {Promise<IdentifyClient>}
  */
 async function init(opts = {}) {
   const distDir = path.join(__dirname, "dist");
   const wasmFile = opts.wasmPath || path.join(distDir, "identify.wasm");
-  const pkFile = opts.provingKeyPath || path.join(distDir, "user.pk");
+  const circuit = (opts.config && opts.config.circuit) || "age-login";
+  const backend = (opts.config && opts.config.backend) || "groth16";
+  const scheme = (opts.config && opts.config.scheme) || 2;
+  const pkName =
+    (circuit === "login" ? "login" : "user") +
+    (scheme === 3 ? "_poseidon2" : "") +
+    (backend === "plonk" ? "_plonk" : "") +
+    ".pk";
+  const pkFile = opts.provingKeyPath || path.join(distDir, pkName);
 
   const wasmBinary = opts.wasmBytes || (await fs.promises.readFile(wasmFile));
   const pkBytes = opts.provingKeyBytes || (await fs.promises.readFile(pkFile));
@@ -83,20 +91,22 @@ class IdentifyClient {
   /**
    * Generate a ZKP authentication proof.
    * @param {string} secret - User's secret (password)
-   * @param {number} birthYear - User's birth year
+   * @param {number} birthDate - Birth date committed at registration (YYYYMMDD)
    * @param {Object} config - { targetYear, limitAge }
-   * @param {number} challenge - Server-issued challenge
+   * @param {string|number} challenge - Server-issued challenge (decimal or 0x-hex string; numbers from ct-v1 tokens)
    * @param {string} saltHex - Salt in hex format
+   * @param {string} [channelBinding] - Channel binding from the server (decimal field element)
    * @returns {{ proof: string, hash: string, binding: string, salt: string }}
    */
-  generateProof(secret, birthYear, config, challenge, saltHex) {
+  generateProof(secret, birthDate, config, challenge, saltHex, channelBinding) {
     const cfg = config || {};
     const res = global.GenerateIdentifyProof(
       secret,
-      birthYear,
+      birthDate,
       cfg,
-      challenge || 0,
-      saltHex || ""
+      challenge,
+      saltHex || "",
+      channelBinding || ""
     );
     if (typeof res === "string" && res.startsWith("Error")) {
       throw new Error(sanitizeError(res, "Proof generation failed"));
@@ -107,8 +117,14 @@ class IdentifyClient {
       binding: res.binding,
       salt: res.salt,
       pkId: res.pkId,
+      circuit: res.circuit,
+      backend: res.backend,
+      scheme: res.scheme,
       policyYear: res.policyYear || cfg.targetYear,
+      policyDate: res.policyDate || cfg.targetDate,
       limitAge: res.limitAge || cfg.limitAge,
+      ageMode: res.ageMode || cfg.ageMode,
+      envelope: res.envelope,
     };
   }
 
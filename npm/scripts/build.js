@@ -41,6 +41,32 @@ if (fs.existsSync(pkSrc)) {
     console.warn("⚠️  user.pk not found, skipping...");
 }
 
+// Copy login-only proving key
+const loginPkSrc = path.join(rootDir, "auth", "login.pk");
+const loginPkDst = path.join(distDir, "login.pk");
+if (fs.existsSync(loginPkSrc)) {
+    fs.copyFileSync(loginPkSrc, loginPkDst);
+} else {
+    console.warn("⚠️  login.pk not found, skipping...");
+}
+
+// Copy PLONK and Poseidon2 proving keys
+for (const name of [
+    "user_plonk.pk",
+    "login_plonk.pk",
+    "user_poseidon2.pk",
+    "login_poseidon2.pk",
+    "user_poseidon2_plonk.pk",
+    "login_poseidon2_plonk.pk",
+]) {
+    const src = path.join(rootDir, "auth", name);
+    if (fs.existsSync(src)) {
+        fs.copyFileSync(src, path.join(distDir, name));
+    } else {
+        console.warn(`⚠️  ${name} not found, skipping...`);
+    }
+}
+
 // Copy age proving key
 const agePkSrc = path.join(rootDir, "age", "age.pk");
 const agePkDst = path.join(distDir, "age.pk");
@@ -54,4 +80,11 @@ console.log("\n✅ Build complete!");
 console.log("   dist/identify.wasm");
 console.log("   dist/wasm_exec.js");
 console.log("   dist/user.pk");
+console.log("   dist/login.pk");
+console.log("   dist/user_plonk.pk");
+console.log("   dist/login_plonk.pk");
+console.log("   dist/user_poseidon2.pk");
+console.log("   dist/login_poseidon2.pk");
+console.log("   dist/user_poseidon2_plonk.pk");
+console.log("   dist/login_poseidon2_plonk.pk");
 console.log("   dist/age.pk");
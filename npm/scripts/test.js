@@ -13,9 +13,9 @@ async function test() {
         // Generate proof
         const result = client.generateProof(
             "testpassword",  // secret
-            2000,            // birth year
+            20000101,        // birth date (YYYYMMDD)
             { targetYear: 2025, limitAge: 20 },
-            12345,           // challenge
+            "0x1f2e3d4c5b6a79880102030405060708", // challenge (ct-v2 field element)
             salt
         );
 
@@ -4,7 +4,9 @@ package main
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"syscall/js"
 
 	"github.com/ghdehrl12345/identify_sdk/v2/auth"
@@ -26,12 +28,25 @@ func InitProver(this js.Value, p []js.Value) interface{} {
 	js.CopyBytesToGo(pkBytes, pkJS)
 
 	cfg := common.DefaultSharedConfig()
+	policy := auth.DefaultPolicy()
 	if len(p) >= 2 && p[1].Type() == js.TypeObject {
 		cfg = parseSharedConfig(p[1], cfg)
+		if v := p[1].Get("circuit"); v.Type() == js.TypeString {
+			policy.Circuit = v.String()
+		}
+		if v := p[1].Get("backend"); v.Type() == js.TypeString {
+			policy.Backend = v.String()
+		}
+		if v := p[1].Get("scheme"); v.Type() == js.TypeNumber {
+			policy.Scheme = v.Int()
+		}
+		if v := p[1].Get("vkId"); v.Type() == js.TypeString {
+			policy.VKID = v.String()
+		}
 	}
 
 	var err error
-	prover, err = auth.NewUserProverFromPKWithPolicy(pkBytes, auth.DefaultPolicy(), cfg)
+	prover, err = auth.NewUserProverFromPKWithPolicy(pkBytes, policy, cfg)
 
 	if err != nil {
 		fmt.Println("❌ [WASM] Engine initialization failed:", err)
@@ -49,30 +64,52 @@ func GenerateProofWrapper(this js.Value, p []js.Value) interface{} {
 	}
 
 	if len(p) < 5 {
-		return "Error: expected args (secret, birthYear, config, challenge, saltHex)"
+		return "Error: expected args (secret, birthDate, config, challenge, saltHex[, channelBinding])"
 	}
 
 	secret := p[0].String()
-	birth := p[1].Int()
+	birthDate := p[1].Int()
 	cfg := parseSharedConfig(p[2], common.DefaultSharedConfig())
-	challenge := p[3].Int()
+	// Challenges are decimal/hex strings (ct-v2); numbers are accepted from ct-v1 clients.
+	challenge := p[3].String()
+	if p[3].Type() == js.TypeNumber {
+		challenge = strconv.Itoa(p[3].Int())
+	}
 	saltHex := p[4].String()
+	channel := ""
+	if len(p) >= 6 && p[5].Type() == js.TypeString {
+		channel = p[5].String()
+	}
 
-	proofBytes, pubHash, binding, err := prover.GenerateProof(secret, birth, cfg.TargetYear, cfg.LimitAge, challenge, saltHex)
+	proofBytes, pubHash, binding, err := prover.GenerateProofWithChannel(secret, birthDate, cfg.CurrentDate(), cfg.LimitAge, challenge, channel, saltHex)
 	if err != nil {
 		return "Error: " + err.Error()
 	}
 
 	proofHex := hex.EncodeToString(proofBytes)
+	env := prover.Envelope(proofBytes, pubHash, saltHex, cfg)
+	if err := env.Validate(); err != nil {
+		return "Error: " + err.Error()
+	}
+	envJSON, err := json.Marshal(env)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
 
 	result := map[string]interface{}{
 		"proof":      proofHex,
 		"hash":       pubHash,
 		"binding":    binding,
 		"salt":       saltHex,
-		"pkId":       auth.ProvingKeyID(),
-		"policyYear": cfg.TargetYear,
+		"pkId":       prover.ProvingKeyID(),
+		"circuit":    prover.Circuit(),
+		"backend":    prover.Backend(),
+		"scheme":     prover.Scheme(),
+		"policyYear": cfg.CurrentDate() / 10000,
+		"policyDate": cfg.CurrentDate(),
 		"limitAge":   cfg.LimitAge,
+		"ageMode":    cfg.AgeMode,
+		"envelope":   string(envJSON),
 	}
 	return js.ValueOf(result)
 }
@@ -86,6 +123,20 @@ func parseSharedConfig(jsVal js.Value, base common.SharedConfig) common.SharedCo
 	} else if v := jsVal.Get("currentYear"); v.Type() == js.TypeNumber {
 		base.TargetYear = v.Int()
 	}
+	if v := jsVal.Get("targetDate"); v.Type() == js.TypeNumber {
+		base.TargetDate = v.Int()
+		base.TargetYear = base.TargetDate / 10000
+	}
+	// An explicit policy date pins the config unless the server marks its date as daily.
+	if jsVal.Get("targetYear").Type() == js.TypeNumber || jsVal.Get("currentYear").Type() == js.TypeNumber || jsVal.Get("targetDate").Type() == js.TypeNumber {
+		base.DynamicDate = false
+	}
+	if v := jsVal.Get("dynamicDate"); v.Type() == js.TypeBoolean {
+		base.DynamicDate = v.Bool()
+	}
+	if v := jsVal.Get("ageMode"); v.Type() == js.TypeString {
+		base.AgeMode = v.String()
+	}
 	if v := jsVal.Get("limitAge"); v.Type() == js.TypeNumber {
 		base.LimitAge = v.Int()
 	}